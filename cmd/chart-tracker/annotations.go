@@ -0,0 +1,187 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// changesAnnotation represents the annotation used to list the changes
+	// introduced in the chart version. Each change can be a description or an
+	// object with its kind and description.
+	changesAnnotation = "artifacthub.io/changes"
+
+	// containsSecurityUpdatesAnnotation represents the annotation used to
+	// indicate that the chart version contains security updates.
+	containsSecurityUpdatesAnnotation = "artifacthub.io/containsSecurityUpdates"
+
+	// displayNameAnnotation represents the annotation used to provide a
+	// human friendly name for the chart.
+	displayNameAnnotation = "artifacthub.io/displayName"
+
+	// imagesAnnotation represents the annotation used to list the container
+	// images used by the chart.
+	imagesAnnotation = "artifacthub.io/images"
+
+	// licenseAnnotation represents the annotation used to provide the SPDX
+	// identifier of the chart license.
+	licenseAnnotation = "artifacthub.io/license"
+
+	// linksAnnotation represents the annotation used to provide some links
+	// related to the chart.
+	linksAnnotation = "artifacthub.io/links"
+
+	// prereleaseAnnotation represents the annotation used to indicate that
+	// the chart version is a pre-release.
+	prereleaseAnnotation = "artifacthub.io/prerelease"
+)
+
+// annotatedImage represents an entry of the images annotation.
+type annotatedImage struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+// enrichPackageFromAnnotations updates the package provided using the
+// artifacthub.io annotations available in the chart metadata. Annotations
+// that are not valid are ignored, and an error describing them is returned
+// once all the valid ones have been applied.
+func enrichPackageFromAnnotations(p *hub.Package, annotations map[string]string) error {
+	var errs []string
+	invalid := func(annotation string, err error) {
+		errs = append(errs, fmt.Sprintf("%s: %s", annotation, err))
+	}
+
+	// Changes
+	if v, ok := annotations[changesAnnotation]; ok {
+		var changes []interface{}
+		if err := yaml.Unmarshal([]byte(v), &changes); err != nil {
+			invalid(changesAnnotation, err)
+		} else {
+			for _, entry := range changes {
+				change, err := parseAnnotatedChange(entry)
+				if err != nil {
+					invalid(changesAnnotation, err)
+					continue
+				}
+				if change != nil {
+					p.Changes = append(p.Changes, change)
+				}
+			}
+		}
+	}
+
+	// Contains security updates
+	if v, ok := annotations[containsSecurityUpdatesAnnotation]; ok {
+		containsSecurityUpdates, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			invalid(containsSecurityUpdatesAnnotation, errors.New("invalid boolean value"))
+		} else {
+			p.ContainsSecurityUpdates = containsSecurityUpdates
+		}
+	}
+
+	// Display name
+	if v := strings.TrimSpace(annotations[displayNameAnnotation]); v != "" {
+		p.DisplayName = v
+	}
+
+	// Images
+	if v, ok := annotations[imagesAnnotation]; ok {
+		var images []*annotatedImage
+		if err := yaml.Unmarshal([]byte(v), &images); err != nil {
+			invalid(imagesAnnotation, err)
+		} else {
+			imagesSet := make(map[string]struct{})
+			for _, image := range p.Images {
+				imagesSet[image] = struct{}{}
+			}
+			for _, entry := range images {
+				image, err := pkg.NormalizeImageReference(entry.Image, true)
+				if err != nil {
+					invalid(imagesAnnotation, fmt.Errorf("invalid image %s", entry.Image))
+					continue
+				}
+				imagesSet[image] = struct{}{}
+			}
+			p.Images = make([]string, 0, len(imagesSet))
+			for image := range imagesSet {
+				p.Images = append(p.Images, image)
+			}
+			sort.Strings(p.Images)
+		}
+	}
+
+	// License
+	if v := strings.TrimSpace(annotations[licenseAnnotation]); v != "" {
+		p.License = v
+	}
+
+	// Links
+	if v, ok := annotations[linksAnnotation]; ok {
+		var links []*hub.Link
+		if err := yaml.Unmarshal([]byte(v), &links); err != nil {
+			invalid(linksAnnotation, err)
+		} else {
+			for _, link := range links {
+				if link.URL == "" {
+					invalid(linksAnnotation, errors.New("link url not provided"))
+					continue
+				}
+				p.Links = append(p.Links, link)
+			}
+		}
+	}
+
+	// Prerelease
+	if v, ok := annotations[prereleaseAnnotation]; ok {
+		prerelease, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			invalid(prereleaseAnnotation, errors.New("invalid boolean value"))
+		} else {
+			p.Prerelease = prerelease
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid annotations: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// parseAnnotatedChange parses an entry of the changes annotation, which can be
+// a description or an object with the kind and description of the change.
+// When the kind is not provided, it's inferred from the description.
+func parseAnnotatedChange(entry interface{}) (*hub.Change, error) {
+	var kind, description string
+	switch e := entry.(type) {
+	case string:
+		description = e
+	case map[string]interface{}:
+		kind, _ = e["kind"].(string)
+		description, _ = e["description"].(string)
+	default:
+		return nil, errors.New("invalid change")
+	}
+	description = strings.TrimSpace(description)
+	if description == "" {
+		if kind != "" {
+			return nil, errors.New("change description not provided")
+		}
+		return nil, nil
+	}
+	change := &hub.Change{Description: description}
+	if kind != "" {
+		change.Kind = normalizeChangeKind(kind)
+	} else {
+		change.Kind = inferChangeKind(description)
+	}
+	return change, nil
+}
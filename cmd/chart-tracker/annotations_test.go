@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnrichPackageFromAnnotations(t *testing.T) {
+	t.Run("valid annotations are applied", func(t *testing.T) {
+		p := &hub.Package{
+			Name:   "pkg1",
+			Images: []string{"docker.io/library/nginx:1.17"},
+		}
+		err := enrichPackageFromAnnotations(p, map[string]string{
+			changesAnnotation:                 "- Added feature\n- kind: security\n  description: Bump base image\n",
+			containsSecurityUpdatesAnnotation: "true",
+			displayNameAnnotation:             "Package 1",
+			imagesAnnotation:                  "- name: etcd\n  image: quay.io/coreos/etcd:v3.4.7\n- name: nginx\n  image: nginx:1.17\n",
+			licenseAnnotation:                 "Apache-2.0",
+			linksAnnotation:                   "- name: source\n  url: https://github.com/org1/pkg1\n",
+			prereleaseAnnotation:              "false",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []*hub.Change{
+			{Kind: hub.Added, Description: "Added feature"},
+			{Kind: hub.Security, Description: "Bump base image"},
+		}, p.Changes)
+		assert.True(t, p.ContainsSecurityUpdates)
+		assert.Equal(t, "Package 1", p.DisplayName)
+		assert.Equal(t, []string{"docker.io/library/nginx:1.17", "quay.io/coreos/etcd:v3.4.7"}, p.Images)
+		assert.Equal(t, "Apache-2.0", p.License)
+		assert.Equal(t, []*hub.Link{{Name: "source", URL: "https://github.com/org1/pkg1"}}, p.Links)
+		assert.False(t, p.Prerelease)
+	})
+
+	t.Run("invalid annotations are ignored and reported", func(t *testing.T) {
+		p := &hub.Package{Name: "pkg1"}
+		err := enrichPackageFromAnnotations(p, map[string]string{
+			changesAnnotation:    "invalid: [",
+			licenseAnnotation:    "MIT",
+			linksAnnotation:      "- name: source\n",
+			prereleaseAnnotation: "maybe",
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), changesAnnotation)
+		assert.Contains(t, err.Error(), "link url not provided")
+		assert.Contains(t, err.Error(), prereleaseAnnotation)
+		assert.Nil(t, p.Changes)
+		assert.Nil(t, p.Links)
+		assert.False(t, p.Prerelease)
+		assert.Equal(t, "MIT", p.License)
+	})
+
+	t.Run("no annotations", func(t *testing.T) {
+		p := &hub.Package{Name: "pkg1"}
+		assert.NoError(t, enrichPackageFromAnnotations(p, nil))
+		assert.Equal(t, &hub.Package{Name: "pkg1"}, p)
+	})
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"container/list"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// defaultCacheMaxSize represents the maximum size of the disk cache, in
+	// bytes, used when none is configured.
+	defaultCacheMaxSize = 1 << 30
+
+	// cacheTmpPrefix represents the prefix of the temporary files used while
+	// writing cache entries.
+	cacheTmpPrefix = ".tmp-"
+)
+
+// cacheKeyRE is a regexp used to validate the keys of the cache entries, as
+// they are used as file names.
+var cacheKeyRE = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// Cache defines the methods a Cache implementation must provide.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	GetFresh(key string, maxAge time.Duration) ([]byte, bool)
+	Set(key string, data []byte)
+}
+
+// cacheEntry represents an entry stored in the disk cache.
+type cacheEntry struct {
+	key     string
+	size    int64
+	created time.Time
+}
+
+// DiskCache is a Cache implementation that stores each entry in its own file
+// in the directory provided. The total size of the entries is limited, and
+// the least recently used ones are evicted when it's exceeded. Entries stored
+// in previous runs are loaded when the cache is created. The files modification
+// time records when each entry was stored, and it's used to rebuild the usage
+// order approximately.
+type DiskCache struct {
+	dir     string
+	maxSize int64
+
+	mu      sync.Mutex
+	size    int64
+	ll      *list.List               // Front: most recently used
+	entries map[string]*list.Element // K: entry key
+}
+
+// NewDiskCache creates a new DiskCache instance.
+func NewDiskCache(dir string, maxSize int64) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	c := &DiskCache{
+		dir:     dir,
+		maxSize: maxSize,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+	}
+
+	// Load entries available from previous runs
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().After(files[j].ModTime())
+	})
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), cacheTmpPrefix) {
+			_ = os.Remove(filepath.Join(dir, f.Name()))
+			continue
+		}
+		if f.IsDir() || !cacheKeyRE.MatchString(f.Name()) {
+			continue
+		}
+		e := &cacheEntry{key: f.Name(), size: f.Size(), created: f.ModTime()}
+		c.entries[e.key] = c.ll.PushBack(e)
+		c.size += e.size
+	}
+	c.removeFiles(c.evict())
+	return c, nil
+}
+
+// Get returns the data of the entry with the key provided, if available.
+func (c *DiskCache) Get(key string) ([]byte, bool) {
+	return c.GetFresh(key, 0)
+}
+
+// GetFresh returns the data of the entry with the key provided, if available
+// and stored within the maximum age given. Expired entries are removed. A zero
+// maximum age means entries never expire. The lock is only held while the
+// entries index is updated, so the entry is read without holding it.
+func (c *DiskCache) GetFresh(key string, maxAge time.Duration) ([]byte, bool) {
+	if !cacheKeyRE.MatchString(key) {
+		return nil, false
+	}
+	c.mu.Lock()
+	elem, ok := c.entries[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, false
+	}
+	if maxAge > 0 && time.Since(elem.Value.(*cacheEntry).created) > maxAge {
+		c.remove(elem)
+		c.mu.Unlock()
+		c.removeFiles([]string{key})
+		return nil, false
+	}
+	c.ll.MoveToFront(elem)
+	c.mu.Unlock()
+
+	data, err := ioutil.ReadFile(c.path(key))
+	if err != nil {
+		// The entry file may have been removed in the meantime, so the entry
+		// is only dropped if it hasn't been replaced
+		c.mu.Lock()
+		if c.entries[key] == elem {
+			c.remove(elem)
+		}
+		c.mu.Unlock()
+		return nil, false
+	}
+	return data, true
+}
+
+// Set stores the data provided in an entry with the given key, evicting the
+// least recently used entries if needed. Data larger than the maximum size of
+// the cache is not stored. The entry is written to a temporary file that is
+// renamed once complete, so that readers never see a partially written entry,
+// and the lock is only held while the entries index is updated.
+func (c *DiskCache) Set(key string, data []byte) {
+	size := int64(len(data))
+	if !cacheKeyRE.MatchString(key) || size > c.maxSize {
+		return
+	}
+
+	// Write entry file
+	tmp, err := ioutil.TempFile(c.dir, cacheTmpPrefix)
+	if err != nil {
+		log.Warn().Err(err).Str("key", key).Msg("error creating cache entry")
+		return
+	}
+	_, err = tmp.Write(data)
+	if errClose := tmp.Close(); err == nil {
+		err = errClose
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		log.Warn().Err(err).Str("key", key).Msg("error writing cache entry")
+		return
+	}
+
+	// Update entries index, removing the files of the entries evicted
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.entries[key] = c.ll.PushFront(&cacheEntry{key: key, size: size, created: time.Now()})
+	c.size += size
+	evicted := c.evict()
+	c.mu.Unlock()
+	c.removeFiles(evicted)
+}
+
+// evict removes from the index the least recently used entries until the
+// total size of the cache does not exceed the maximum configured, returning
+// the keys of the entries evicted so that their files can be removed once the
+// lock is released. The lock must be held.
+func (c *DiskCache) evict() []string {
+	var evicted []string
+	for c.size > c.maxSize {
+		elem := c.ll.Back()
+		if elem == nil {
+			break
+		}
+		c.remove(elem)
+		evicted = append(evicted, elem.Value.(*cacheEntry).key)
+	}
+	return evicted
+}
+
+// remove removes the entry in the element provided from the index. Its file
+// is not deleted. The lock must be held.
+func (c *DiskCache) remove(elem *list.Element) {
+	e := elem.Value.(*cacheEntry)
+	c.ll.Remove(elem)
+	delete(c.entries, e.key)
+	c.size -= e.size
+}
+
+// removeFiles deletes the files of the entries with the keys provided. It must
+// be called without holding the lock.
+func (c *DiskCache) removeFiles(keys []string) {
+	for _, key := range keys {
+		_ = os.Remove(c.path(key))
+	}
+}
+
+// path returns the path of the file used to store the entry with the key
+// provided.
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, key)
+}
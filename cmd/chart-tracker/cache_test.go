@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskCache(t *testing.T) {
+	setup := func(t *testing.T) (string, func()) {
+		dir, err := ioutil.TempDir("", "chart-tracker-cache")
+		require.NoError(t, err)
+		return dir, func() { os.RemoveAll(dir) }
+	}
+
+	t.Run("entry stored and retrieved", func(t *testing.T) {
+		dir, cleanup := setup(t)
+		defer cleanup()
+		c, err := NewDiskCache(dir, 10)
+		require.NoError(t, err)
+
+		_, ok := c.Get("key1")
+		assert.False(t, ok)
+		c.Set("key1", []byte("data1"))
+		data, ok := c.Get("key1")
+		assert.True(t, ok)
+		assert.Equal(t, []byte("data1"), data)
+	})
+
+	t.Run("expired entries removed", func(t *testing.T) {
+		dir, cleanup := setup(t)
+		defer cleanup()
+		c, err := NewDiskCache(dir, 10)
+		require.NoError(t, err)
+
+		c.Set("key1", []byte("data1"))
+		data, ok := c.GetFresh("key1", time.Hour)
+		assert.True(t, ok)
+		assert.Equal(t, []byte("data1"), data)
+		c.entries["key1"].Value.(*cacheEntry).created = time.Now().Add(-2 * time.Hour)
+		_, ok = c.GetFresh("key1", time.Hour)
+		assert.False(t, ok)
+		_, ok = c.Get("key1")
+		assert.False(t, ok)
+		_, err = os.Stat(filepath.Join(dir, "key1"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("entries from previous runs keep their creation time", func(t *testing.T) {
+		dir, cleanup := setup(t)
+		defer cleanup()
+		p := filepath.Join(dir, "key1")
+		require.NoError(t, ioutil.WriteFile(p, []byte("data1"), 0600))
+		mtime := time.Now().Add(-2 * time.Hour)
+		require.NoError(t, os.Chtimes(p, mtime, mtime))
+
+		c, err := NewDiskCache(dir, 10)
+		require.NoError(t, err)
+		_, ok := c.GetFresh("key1", 3*time.Hour)
+		assert.True(t, ok)
+		_, ok = c.GetFresh("key1", time.Hour)
+		assert.False(t, ok)
+	})
+
+	t.Run("invalid keys and entries too large are ignored", func(t *testing.T) {
+		dir, cleanup := setup(t)
+		defer cleanup()
+		c, err := NewDiskCache(dir, 10)
+		require.NoError(t, err)
+
+		c.Set("../key1", []byte("data1"))
+		c.Set("key2", []byte("data larger than max size"))
+		_, ok := c.Get("../key1")
+		assert.False(t, ok)
+		_, ok = c.Get("key2")
+		assert.False(t, ok)
+		files, _ := ioutil.ReadDir(dir)
+		assert.Empty(t, files)
+	})
+
+	t.Run("least recently used entries evicted", func(t *testing.T) {
+		dir, cleanup := setup(t)
+		defer cleanup()
+		c, err := NewDiskCache(dir, 10)
+		require.NoError(t, err)
+
+		c.Set("key1", []byte("data1"))
+		c.Set("key2", []byte("data2"))
+		_, _ = c.Get("key1")
+		c.Set("key3", []byte("data3"))
+		_, ok := c.Get("key2")
+		assert.False(t, ok)
+		_, ok = c.Get("key1")
+		assert.True(t, ok)
+		_, ok = c.Get("key3")
+		assert.True(t, ok)
+		_, err = os.Stat(filepath.Join(dir, "key2"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("entries from previous runs loaded", func(t *testing.T) {
+		dir, cleanup := setup(t)
+		defer cleanup()
+		now := time.Now()
+		for i, key := range []string{"key1", "key2", "key3"} {
+			p := filepath.Join(dir, key)
+			require.NoError(t, ioutil.WriteFile(p, []byte("data"+key[3:]), 0600))
+			mtime := now.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, os.Chtimes(p, mtime, mtime))
+		}
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, cacheTmpPrefix+"1"), []byte("tmp"), 0600))
+
+		c, err := NewDiskCache(dir, 10)
+		require.NoError(t, err)
+		_, ok := c.Get("key1")
+		assert.False(t, ok)
+		data, ok := c.Get("key3")
+		assert.True(t, ok)
+		assert.Equal(t, []byte("data3"), data)
+		_, err = os.Stat(filepath.Join(dir, cacheTmpPrefix+"1"))
+		assert.True(t, os.IsNotExist(err))
+	})
+	t.Run("concurrent access", func(t *testing.T) {
+		dir, cleanup := setup(t)
+		defer cleanup()
+		c, err := NewDiskCache(dir, 50)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					key := fmt.Sprintf("key%d", (i+j)%15)
+					c.Set(key, []byte("data-"+key))
+					if data, ok := c.Get(key); ok {
+						assert.Equal(t, []byte("data-"+key), data)
+					}
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		assert.LessOrEqual(t, c.size, c.maxSize)
+		assert.Equal(t, c.ll.Len(), len(c.entries))
+		files, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		for _, f := range files {
+			assert.False(t, strings.HasPrefix(f.Name(), cacheTmpPrefix))
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+)
+
+var (
+	// changelogVersionRE is a regexp used to match the headings of the
+	// versions sections in a changelog file (i.e. ## [1.0.0] - 2020-05-01).
+	changelogVersionRE = regexp.MustCompile(`^##\s+\[?v?([^\]\s]+)\]?`)
+
+	// changelogKindRE is a regexp used to match the headings of the kinds of
+	// changes subsections in a changelog file (i.e. ### Added).
+	changelogKindRE = regexp.MustCompile(`^###\s+(.+?)\s*$`)
+
+	// changelogEntryRE is a regexp used to match the entries of a changelog
+	// file.
+	changelogEntryRE = regexp.MustCompile(`^[-*+]\s+(.+?)\s*$`)
+)
+
+// parseChangelog extracts the changes of the version provided from a
+// changelog file, which is expected to follow the Keep a Changelog format:
+// a section per version, with a subsection per kind of change listing the
+// changes. Changes not listed in a kind subsection get a kind inferred from
+// their description.
+func parseChangelog(data []byte, version string) []*hub.Change {
+	var changes []*hub.Change
+	var inVersion bool
+	var kind hub.ChangeKind
+	var last *hub.Change
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := s.Text()
+		if m := changelogVersionRE.FindStringSubmatch(line); m != nil {
+			if inVersion {
+				break
+			}
+			inVersion = m[1] == version
+			continue
+		}
+		if !inVersion {
+			continue
+		}
+		if m := changelogKindRE.FindStringSubmatch(line); m != nil {
+			kind = normalizeChangeKind(m[1])
+			last = nil
+			continue
+		}
+		if m := changelogEntryRE.FindStringSubmatch(line); m != nil {
+			last = &hub.Change{Kind: kind, Description: m[1]}
+			if last.Kind == "" {
+				last.Kind = inferChangeKind(m[1])
+			}
+			changes = append(changes, last)
+			continue
+		}
+		if text := strings.TrimSpace(line); text != "" && last != nil && line != text {
+			// Indented lines continue the previous entry
+			last.Description += " " + strings.TrimLeft(text, "-*+ ")
+		} else if text == "" {
+			last = nil
+		}
+	}
+	return changes
+}
+
+// normalizeChangeKind returns the kind of change corresponding to the name of
+// a kind of change provided (i.e. the ones used in Keep a Changelog).
+func normalizeChangeKind(name string) hub.ChangeKind {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "added", "add", "new":
+		return hub.Added
+	case "fixed", "fix", "bugfix", "bugfixes":
+		return hub.Fixed
+	case "security":
+		return hub.Security
+	default:
+		return hub.Changed
+	}
+}
+
+// inferChangeKind returns the kind of a change from its description, using
+// the verb it starts with. Changes that mention a CVE are security changes.
+func inferChangeKind(description string) hub.ChangeKind {
+	d := strings.ToLower(description)
+	if strings.Contains(d, "cve-") || strings.HasPrefix(d, "security") {
+		return hub.Security
+	}
+	verb := d
+	if i := strings.IndexAny(d, " :"); i >= 0 {
+		verb = d[:i]
+	}
+	switch verb {
+	case "add", "added", "adds", "new":
+		return hub.Added
+	case "fix", "fixed", "fixes", "bugfix":
+		return hub.Fixed
+	default:
+		return hub.Changed
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChangelog(t *testing.T) {
+	changelog := []byte(`# Changelog
+
+## [Unreleased]
+
+### Added
+- Something not released yet
+
+## [1.1.0] - 2020-06-01
+
+### Added
+- Support for ingress
+  annotations
+
+### Fixed
+- Service port name
+
+### Security
+- Bump nginx image to 1.19
+
+## v1.0.0
+
+- Add initial chart
+- Fix labels
+- Update readme
+`)
+
+	t.Run("changes in kind subsections", func(t *testing.T) {
+		assert.Equal(t, []*hub.Change{
+			{Kind: hub.Added, Description: "Support for ingress annotations"},
+			{Kind: hub.Fixed, Description: "Service port name"},
+			{Kind: hub.Security, Description: "Bump nginx image to 1.19"},
+		}, parseChangelog(changelog, "1.1.0"))
+	})
+
+	t.Run("changes without kind subsections", func(t *testing.T) {
+		assert.Equal(t, []*hub.Change{
+			{Kind: hub.Added, Description: "Add initial chart"},
+			{Kind: hub.Fixed, Description: "Fix labels"},
+			{Kind: hub.Changed, Description: "Update readme"},
+		}, parseChangelog(changelog, "1.0.0"))
+	})
+
+	t.Run("version not found", func(t *testing.T) {
+		assert.Nil(t, parseChangelog(changelog, "2.0.0"))
+	})
+}
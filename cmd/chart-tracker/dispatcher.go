@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/time/rate"
 	"helm.sh/helm/v3/pkg/chart"
@@ -25,39 +26,110 @@ const (
 	Unregister
 )
 
+// reposProcessed counts the chart repositories processed by the dispatcher,
+// labelled by the result of loading their index file (changed, unchanged or
+// failed).
+var reposProcessed = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "chart_tracker_repositories_processed_total",
+		Help: "Number of chart repositories processed by the chart tracker.",
+	},
+	[]string{"result"},
+)
+
 // Job represents a Job for registering or unregistering a given chart release
 // available in the provided chart repository. Jobs are created by the
-// dispatcher and will eventually be handled by a worker.
+// dispatcher and will eventually be handled by a worker. Jobs of repositories
+// of kinds other than Helm charts provide the package to register instead of
+// the chart version.
 type Job struct {
 	Kind         JobKind
 	Repo         *hub.ChartRepository
 	ChartVersion *repo.ChartVersion
+	Package      *hub.Package
 	GetLogo      bool
+
+	wg           *sync.WaitGroup
+	onRegistered func()
+}
+
+// nameVersion returns the name and version of the package the job refers to.
+func (j *Job) nameVersion() (string, string) {
+	if j.Package != nil {
+		return j.Package.Name, j.Package.Version
+	}
+	return j.ChartVersion.Metadata.Name, j.ChartVersion.Metadata.Version
+}
+
+// digest returns the digest of the package version the job refers to.
+func (j *Job) digest() string {
+	if j.Package != nil {
+		return j.Package.Digest
+	}
+	return j.ChartVersion.Digest
+}
+
+// registered notifies that the package version the job refers to has been
+// registered successfully.
+func (j *Job) registered() {
+	if j.onRegistered != nil {
+		j.onRegistered()
+	}
+}
+
+// done marks the job as handled, notifying whoever may be waiting for it.
+func (j *Job) done() {
+	if j.wg != nil {
+		j.wg.Done()
+	}
 }
 
 // Dispatcher is in charge of generating jobs to register or unregister charts
 // releases and dispatching them among the available workers.
 type Dispatcher struct {
-	ctx   context.Context
-	il    hub.ChartRepositoryIndexLoader
-	rm    hub.ChartRepositoryManager
-	ec    ErrorsCollector
-	Queue chan *Job
+	ctx     context.Context
+	il      hub.ChartRepositoryIndexLoader
+	pl      hub.ChartRepositoryPackagesLoader
+	rm      hub.ChartRepositoryManager
+	ec      ErrorsCollector
+	rp      *Reprocess
+	limiter *rate.Limiter
+	Queue   chan *Job
+
+	mu          sync.Mutex
+	indexesInfo map[string]*hub.ChartRepositoryIndexInfo // K: chart repository id
 }
 
 // NewDispatcher creates a new dispatcher instance.
 func NewDispatcher(
 	ctx context.Context,
 	il hub.ChartRepositoryIndexLoader,
+	pl hub.ChartRepositoryPackagesLoader,
 	rm hub.ChartRepositoryManager,
 	ec ErrorsCollector,
+	opts ...func(d *Dispatcher),
 ) *Dispatcher {
-	return &Dispatcher{
-		ctx:   ctx,
-		il:    il,
-		rm:    rm,
-		ec:    ec,
-		Queue: make(chan *Job),
+	d := &Dispatcher{
+		ctx:         ctx,
+		il:          il,
+		pl:          pl,
+		rm:          rm,
+		ec:          ec,
+		limiter:     rate.NewLimiter(25, 25),
+		Queue:       make(chan *Job),
+		indexesInfo: make(map[string]*hub.ChartRepositoryIndexInfo),
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+// WithReprocess allows providing a forced reprocessing run. The package
+// versions selected in it are registered again even if they have not changed.
+func WithReprocess(rp *Reprocess) func(d *Dispatcher) {
+	return func(d *Dispatcher) {
+		d.rp = rp
 	}
 }
 
@@ -67,87 +139,274 @@ func (d *Dispatcher) Run(wg *sync.WaitGroup, repos []*hub.ChartRepository) {
 	defer close(d.Queue)
 
 	var wgRepos sync.WaitGroup
-	limiter := rate.NewLimiter(25, 25)
 	for _, r := range repos {
-		if err := limiter.Wait(d.ctx); err != nil {
+		if err := d.limiter.Wait(d.ctx); err != nil {
 			log.Error().Err(err).Msg("error waiting for limiter")
 			return
 		}
 		wgRepos.Add(1)
-		go d.generateSyncJobs(&wgRepos, r)
+		go func(r *hub.ChartRepository) {
+			defer wgRepos.Done()
+			_ = d.generateSyncJobs(r, nil)
+		}(r)
 	}
 
 	wgRepos.Wait()
 }
 
+// SyncRepository generates the jobs needed to keep the provided repository in
+// sync and waits until all of them have been handled by the workers. Once
+// done, the tracking results of the repository are stored. An error is
+// returned when the repository could not be processed.
+func (d *Dispatcher) SyncRepository(r *hub.ChartRepository) error {
+	if err := d.limiter.Wait(d.ctx); err != nil {
+		return err
+	}
+	d.ec.Init(r.ChartRepositoryID)
+	var jobsWG sync.WaitGroup
+	err := d.generateSyncJobs(r, &jobsWG)
+	jobsWG.Wait()
+	d.saveIndexInfo(r.ChartRepositoryID)
+	d.ec.FlushRepository(r.ChartRepositoryID)
+	return err
+}
+
 // generateSyncJobs generates the jobs to register or unregister chart releases
-// as needed to keep them in sync.
-func (d *Dispatcher) generateSyncJobs(wg *sync.WaitGroup, r *hub.ChartRepository) {
-	defer wg.Done()
+// as needed to keep them in sync. When a wait group is provided, it will be
+// used to track the jobs generated until they are handled.
+func (d *Dispatcher) generateSyncJobs(r *hub.ChartRepository, jobsWG *sync.WaitGroup) error {
+	// Ignore the index file processed last time when the repository has
+	// package versions to reprocess, as they must be registered again
+	forced := d.rp != nil && d.rp.MatchesRepository(r)
+	if forced && r.LastIndexInfo != nil {
+		rCopy := *r
+		rCopy.LastIndexInfo = nil
+		r = &rCopy
+	}
 
-	log.Info().Str("repo", r.Name).Msg("loading chart repository index file")
-	indexFile, err := d.il.LoadIndex(r)
+	var registerJobs []*Job
+	var indexInfo *hub.ChartRepositoryIndexInfo
+	var changed bool
+	var err error
+	if r.Kind == hub.Chart {
+		log.Info().Str("repo", r.Name).Msg("loading chart repository index file")
+		registerJobs, indexInfo, changed, err = d.loadChartVersions(r)
+	} else {
+		log.Info().Str("repo", r.Name).Msg("loading repository packages")
+		registerJobs, indexInfo, changed, err = d.loadPackages(r)
+	}
 	if err != nil {
 		msg := "error loading repository index file"
-		d.ec.Append(r.ChartRepositoryID, fmt.Errorf("%s: %w", msg, err))
+		if r.Kind != hub.Chart {
+			msg = "error loading repository packages"
+		}
+		d.ec.Append(r.ChartRepositoryID, &hub.TrackingError{
+			Code:     hub.IndexUnreachable,
+			Severity: hub.SeverityError,
+			URL:      r.URL,
+			Message:  fmt.Sprintf("%s: %s", msg, err),
+		})
 		log.Error().Err(err).Str("repo", r.Name).Msg(msg)
-		return
+		reposProcessed.WithLabelValues("failed").Inc()
+		if forced {
+			d.rp.Failed(r)
+		}
+		return err
+	}
+	if !changed {
+		log.Info().Str("repo", r.Name).Msg("chart repository index file unchanged, skipping")
+		reposProcessed.WithLabelValues("unchanged").Inc()
+		if indexInfo != nil && (r.LastIndexInfo == nil || *indexInfo != *r.LastIndexInfo) {
+			d.trackIndexInfo(r.ChartRepositoryID, indexInfo)
+		}
+		return nil
 	}
+	reposProcessed.WithLabelValues("changed").Inc()
 
 	log.Info().Str("repo", r.Name).Msg("loading registered packages digest")
 	registeredPackagesDigest, err := d.rm.GetPackagesDigest(d.ctx, r.ChartRepositoryID)
 	if err != nil {
 		log.Error().Err(err).Str("repo", r.Name).Msg("error getting repository packages digest")
-		return
+		if forced {
+			d.rp.Failed(r)
+		}
+		return err
 	}
 
-	// Register new or updated chart releases
+	// Register new or updated chart releases, as well as those selected to
+	// be reprocessed
+	var skipped, reprocessed int
 	chartsAvailable := make(map[string]struct{})
-	for _, chartVersions := range indexFile.Entries {
-		for i, chartVersion := range chartVersions {
-			var getLogo bool
-			if i == 0 {
-				getLogo = true
-			}
-			key := fmt.Sprintf("%s@%s", chartVersion.Metadata.Name, chartVersion.Metadata.Version)
-			chartsAvailable[key] = struct{}{}
-			if chartVersion.Digest != registeredPackagesDigest[key] {
-				d.Queue <- &Job{
-					Kind:         Register,
-					Repo:         r,
-					ChartVersion: chartVersion,
-					GetLogo:      getLogo,
-				}
-			}
-			select {
-			case <-d.ctx.Done():
-				return
-			default:
-			}
+	for _, j := range registerJobs {
+		name, version := j.nameVersion()
+		key := fmt.Sprintf("%s@%s", name, version)
+		chartsAvailable[key] = struct{}{}
+		switch {
+		case forced && d.rp.Pending(r, name, version):
+			j.onRegistered = func() { d.rp.Done(r, name, version) }
+			d.rp.Enqueued()
+			reprocessed++
+			d.enqueue(j, jobsWG)
+		case j.digest() != registeredPackagesDigest[key]:
+			d.enqueue(j, jobsWG)
+		default:
+			skipped++
+		}
+		select {
+		case <-d.ctx.Done():
+			return d.ctx.Err()
+		default:
 		}
 	}
 
+	if skipped > 0 {
+		d.ec.CountVersions(r.ChartRepositoryID, VersionSkipped, skipped)
+	}
+	if reprocessed > 0 {
+		log.Info().Str("repo", r.Name).Int("versions", reprocessed).Msg("package versions enqueued for reprocessing")
+	}
+
 	// Unregister chart releases no longer available in the repository
 	for key := range registeredPackagesDigest {
 		if _, ok := chartsAvailable[key]; !ok {
 			p := strings.Split(key, "@")
 			name := p[0]
 			version := p[1]
-			d.Queue <- &Job{
+			j := &Job{
 				Kind: Unregister,
 				Repo: r,
-				ChartVersion: &repo.ChartVersion{
+			}
+			if r.Kind == hub.Chart {
+				j.ChartVersion = &repo.ChartVersion{
 					Metadata: &chart.Metadata{
 						Name:    name,
 						Version: version,
 					},
-				},
+				}
+			} else {
+				j.Package = &hub.Package{
+					Name:    name,
+					Version: version,
+				}
 			}
+			d.enqueue(j, jobsWG)
 		}
 		select {
 		case <-d.ctx.Done():
-			return
+			return d.ctx.Err()
 		default:
 		}
 	}
+
+	// Keep track of the index file processed, so that it can be stored once
+	// all jobs have been handled
+	if indexInfo != nil {
+		d.trackIndexInfo(r.ChartRepositoryID, indexInfo)
+	}
+	return nil
+}
+
+// loadChartVersions loads the index file of the provided chart repository if
+// it has changed, returning the jobs to register the chart releases available
+// in it.
+func (d *Dispatcher) loadChartVersions(r *hub.ChartRepository) (
+	[]*Job,
+	*hub.ChartRepositoryIndexInfo,
+	bool,
+	error,
+) {
+	indexFile, indexInfo, err := d.il.LoadIndexIfChanged(r)
+	if err != nil || indexFile == nil {
+		return nil, indexInfo, false, err
+	}
+	var jobs []*Job
+	for _, chartVersions := range indexFile.Entries {
+		for i, chartVersion := range chartVersions {
+			var getLogo bool
+			if i == 0 {
+				getLogo = true
+			}
+			jobs = append(jobs, &Job{
+				Kind:         Register,
+				Repo:         r,
+				ChartVersion: chartVersion,
+				GetLogo:      getLogo,
+			})
+		}
+	}
+	return jobs, indexInfo, true, nil
+}
+
+// loadPackages loads the packages available in the provided repository if
+// they have changed, returning the jobs to register them. It's used for
+// repositories of kinds other than Helm charts.
+func (d *Dispatcher) loadPackages(r *hub.ChartRepository) (
+	[]*Job,
+	*hub.ChartRepositoryIndexInfo,
+	bool,
+	error,
+) {
+	packages, indexInfo, err := d.pl.LoadPackagesIfChanged(r)
+	if err != nil || packages == nil {
+		return nil, indexInfo, false, err
+	}
+	jobs := make([]*Job, 0, len(packages))
+	for _, p := range packages {
+		jobs = append(jobs, &Job{
+			Kind:    Register,
+			Repo:    r,
+			Package: p,
+			GetLogo: true,
+		})
+	}
+	return jobs, indexInfo, true, nil
+}
+
+// enqueue sends the provided job to the queue, adding it to the jobs wait
+// group when one is provided.
+func (d *Dispatcher) enqueue(j *Job, jobsWG *sync.WaitGroup) {
+	if jobsWG != nil {
+		jobsWG.Add(1)
+		j.wg = jobsWG
+	}
+	d.Queue <- j
+}
+
+// trackIndexInfo keeps track of the information about the index file
+// processed for the provided chart repository.
+func (d *Dispatcher) trackIndexInfo(chartRepositoryID string, indexInfo *hub.ChartRepositoryIndexInfo) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.indexesInfo[chartRepositoryID] = indexInfo
+}
+
+// SaveIndexesInfo stores the information about the index files processed of
+// the repositories that were tracked without errors. This allows skipping them
+// in the next runs as long as their index file does not change. It must be
+// called once all the jobs generated by the dispatcher have been handled.
+func (d *Dispatcher) SaveIndexesInfo() {
+	d.mu.Lock()
+	reposIDs := make([]string, 0, len(d.indexesInfo))
+	for chartRepositoryID := range d.indexesInfo {
+		reposIDs = append(reposIDs, chartRepositoryID)
+	}
+	d.mu.Unlock()
+	for _, chartRepositoryID := range reposIDs {
+		d.saveIndexInfo(chartRepositoryID)
+	}
+}
+
+// saveIndexInfo stores the information about the index file processed of the
+// provided repository, as long as it was tracked without errors.
+func (d *Dispatcher) saveIndexInfo(chartRepositoryID string) {
+	d.mu.Lock()
+	indexInfo, ok := d.indexesInfo[chartRepositoryID]
+	delete(d.indexesInfo, chartRepositoryID)
+	d.mu.Unlock()
+	if !ok || d.ctx.Err() != nil || d.ec.HasErrors(chartRepositoryID) {
+		return
+	}
+	if err := d.rm.SetLastIndexInfo(d.ctx, chartRepositoryID, indexInfo); err != nil {
+		log.Error().Err(err).Str("repoID", chartRepositoryID).Msg("error saving last index info")
+	}
 }
@@ -3,13 +3,18 @@ package main
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sync"
 	"testing"
 
 	"github.com/artifacthub/hub/internal/chartrepo"
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/repo"
 )
@@ -31,7 +36,7 @@ func TestDispatcher(t *testing.T) {
 		// Setup dispatcher and expectations
 		r := &hub.ChartRepository{ChartRepositoryID: "repo1"}
 		dw := newDispatcherWrapper(context.Background())
-		dw.il.On("LoadIndex", r).Return(nil, errFake)
+		dw.il.On("LoadIndexIfChanged", r).Return(nil, nil, errFake)
 		dw.ec.On("Append", r.ChartRepositoryID, mock.Anything).Return()
 
 		// Run dispatcher and check expectations
@@ -43,7 +48,7 @@ func TestDispatcher(t *testing.T) {
 		// Setup dispatcher and expectations
 		r := &hub.ChartRepository{ChartRepositoryID: "repo1"}
 		dw := newDispatcherWrapper(context.Background())
-		dw.il.On("LoadIndex", r).Return(nil, nil)
+		dw.il.On("LoadIndexIfChanged", r).Return(&repo.IndexFile{}, &hub.ChartRepositoryIndexInfo{}, nil)
 		dw.rm.On("GetPackagesDigest", dw.d.ctx, r.ChartRepositoryID).Return(nil, errFake)
 
 		// Run dispatcher and check expectations
@@ -51,6 +56,157 @@ func TestDispatcher(t *testing.T) {
 		dw.assertExpectations(t, nil)
 	})
 
+	t.Run("chart repository index file unchanged", func(t *testing.T) {
+		// Setup dispatcher and expectations
+		indexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest"}
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1", LastIndexInfo: indexInfo}
+		dw := newDispatcherWrapper(context.Background())
+		dw.il.On("LoadIndexIfChanged", r).Return(nil, indexInfo, nil)
+
+		// Run dispatcher and check expectations
+		dw.d.Run(dw.wg, []*hub.ChartRepository{r})
+		dw.assertExpectations(t, nil)
+		assert.Empty(t, dw.d.indexesInfo)
+	})
+
+	t.Run("unchanged chart versions counted as skipped", func(t *testing.T) {
+		// Setup dispatcher and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1"}
+		indexFile := &repo.IndexFile{
+			Entries: map[string]repo.ChartVersions{
+				"pkg1": []*repo.ChartVersion{
+					{Metadata: &chart.Metadata{Name: "pkg1", Version: "1.0.0"}, Digest: "pkg1-1.0.0"},
+					{Metadata: &chart.Metadata{Name: "pkg1", Version: "2.0.0"}, Digest: "pkg1-2.0.0"},
+				},
+			},
+		}
+		dw := newDispatcherWrapper(context.Background())
+		dw.il.On("LoadIndexIfChanged", r).Return(indexFile, nil, nil)
+		dw.rm.On("GetPackagesDigest", dw.d.ctx, r.ChartRepositoryID).Return(map[string]string{
+			"pkg1@1.0.0": "pkg1-1.0.0",
+			"pkg1@2.0.0": "pkg1-2.0.0",
+		}, nil)
+		dw.ec.On("CountVersions", r.ChartRepositoryID, VersionSkipped, 2).Return()
+
+		// Run dispatcher and check expectations
+		dw.d.Run(dw.wg, []*hub.ChartRepository{r})
+		dw.assertExpectations(t, nil)
+	})
+
+	t.Run("unchanged chart versions selected to be reprocessed", func(t *testing.T) {
+		// Setup reprocessing run, resuming one already reprocessed
+		stateDir, err := ioutil.TempDir("", "chart-tracker-reprocess")
+		require.NoError(t, err)
+		defer os.RemoveAll(stateDir)
+		cfg := viper.New()
+		cfg.Set("tracker.forceReprocess.repositories", []string{"repo1"})
+		cfg.Set("tracker.forceReprocess.stateFile", filepath.Join(stateDir, "state"))
+		rp, err := NewReprocess(cfg)
+		require.NoError(t, err)
+		defer rp.Finish(true)
+		indexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest"}
+		r := &hub.ChartRepository{ChartRepositoryID: "id1", Name: "repo1", LastIndexInfo: indexInfo}
+		rp.Done(r, "pkg1", "1.0.0")
+
+		// Setup dispatcher and expectations
+		indexFile := &repo.IndexFile{
+			Entries: map[string]repo.ChartVersions{
+				"pkg1": []*repo.ChartVersion{
+					{Metadata: &chart.Metadata{Name: "pkg1", Version: "1.0.0"}, Digest: "pkg1-1.0.0"},
+					{Metadata: &chart.Metadata{Name: "pkg1", Version: "2.0.0"}, Digest: "pkg1-2.0.0"},
+				},
+			},
+		}
+		dw := newDispatcherWrapper(context.Background())
+		dw.d.rp = rp
+		dw.il.On("LoadIndexIfChanged", mock.MatchedBy(func(r *hub.ChartRepository) bool {
+			return r.ChartRepositoryID == "id1" && r.LastIndexInfo == nil
+		})).Return(indexFile, indexInfo, nil)
+		dw.rm.On("GetPackagesDigest", dw.d.ctx, r.ChartRepositoryID).Return(map[string]string{
+			"pkg1@1.0.0": "pkg1-1.0.0",
+			"pkg1@2.0.0": "pkg1-2.0.0",
+		}, nil)
+		dw.ec.On("CountVersions", r.ChartRepositoryID, VersionSkipped, 1).Return()
+
+		// Run dispatcher and check expectations
+		dw.d.Run(dw.wg, []*hub.ChartRepository{r})
+		dw.wg.Wait()
+		dw.il.AssertExpectations(t)
+		dw.rm.AssertExpectations(t)
+		dw.ec.AssertExpectations(t)
+		require.Len(t, *dw.queuedJobs, 1)
+		j := (*dw.queuedJobs)[0]
+		assert.Equal(t, "2.0.0", j.ChartVersion.Metadata.Version)
+		assert.True(t, rp.Pending(r, "pkg1", "2.0.0"))
+		j.registered()
+		assert.False(t, rp.Pending(r, "pkg1", "2.0.0"))
+	})
+
+	t.Run("error loading repository packages", func(t *testing.T) {
+		// Setup dispatcher and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1", Kind: hub.Falco}
+		dw := newDispatcherWrapper(context.Background())
+		dw.pl.On("LoadPackagesIfChanged", r).Return(nil, nil, errFake)
+		dw.ec.On("Append", r.ChartRepositoryID, mock.Anything).Return()
+
+		// Run dispatcher and check expectations
+		dw.d.Run(dw.wg, []*hub.ChartRepository{r})
+		dw.assertExpectations(t, nil)
+	})
+
+	t.Run("repository packages unchanged", func(t *testing.T) {
+		// Setup dispatcher and expectations
+		indexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest"}
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1", Kind: hub.OPA, LastIndexInfo: indexInfo}
+		dw := newDispatcherWrapper(context.Background())
+		dw.pl.On("LoadPackagesIfChanged", r).Return(nil, indexInfo, nil)
+
+		// Run dispatcher and check expectations
+		dw.d.Run(dw.wg, []*hub.ChartRepository{r})
+		dw.assertExpectations(t, nil)
+		assert.Empty(t, dw.d.indexesInfo)
+	})
+
+	t.Run("repository packages synced", func(t *testing.T) {
+		// Setup dispatcher and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1", Kind: hub.Falco}
+		pkg1 := &hub.Package{Kind: hub.Falco, Name: "pkg1", Version: "1.0.0", Digest: "pkg1-1.0.0"}
+		pkg2 := &hub.Package{Kind: hub.Falco, Name: "pkg2", Version: "1.0.0", Digest: "pkg2-1.0.0-updated"}
+		pkg3 := &hub.Package{Kind: hub.Falco, Name: "pkg3", Version: "1.0.0", Digest: "pkg3-1.0.0"}
+		indexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest"}
+		dw := newDispatcherWrapper(context.Background())
+		dw.pl.On("LoadPackagesIfChanged", r).Return([]*hub.Package{pkg1, pkg2, pkg3}, indexInfo, nil)
+		dw.rm.On("GetPackagesDigest", dw.d.ctx, r.ChartRepositoryID).Return(map[string]string{
+			"pkg1@1.0.0": "pkg1-1.0.0",
+			"pkg2@1.0.0": "pkg2-1.0.0",
+			"pkg4@1.0.0": "pkg4-1.0.0",
+		}, nil)
+		dw.ec.On("CountVersions", r.ChartRepositoryID, VersionSkipped, 1).Return()
+
+		// Run dispatcher and check expectations
+		dw.d.Run(dw.wg, []*hub.ChartRepository{r})
+		dw.assertExpectations(t, []*Job{
+			{
+				Kind:    Register,
+				Repo:    r,
+				Package: pkg2,
+				GetLogo: true,
+			},
+			{
+				Kind:    Register,
+				Repo:    r,
+				Package: pkg3,
+				GetLogo: true,
+			},
+			{
+				Kind:    Unregister,
+				Repo:    r,
+				Package: &hub.Package{Name: "pkg4", Version: "1.0.0"},
+			},
+		})
+		assert.Equal(t, indexInfo, dw.d.indexesInfo[r.ChartRepositoryID])
+	})
+
 	t.Run("dispatcher completed successfully", func(t *testing.T) {
 		repo1 := &hub.ChartRepository{
 			ChartRepositoryID: "repo1",
@@ -446,9 +602,10 @@ func TestDispatcher(t *testing.T) {
 				// Setup dispatcher and expectations
 				dw := newDispatcherWrapper(context.Background())
 				for _, r := range tc.repos {
-					dw.il.On("LoadIndex", r).Return(tc.indexFile[r.ChartRepositoryID], nil)
+					dw.il.On("LoadIndexIfChanged", r).Return(tc.indexFile[r.ChartRepositoryID], nil, nil)
 					dw.rm.On("GetPackagesDigest", dw.d.ctx, r.ChartRepositoryID).
 						Return(tc.packagesDigest[r.ChartRepositoryID], nil)
+					dw.ec.On("CountVersions", r.ChartRepositoryID, VersionSkipped, mock.Anything).Maybe()
 				}
 
 				// Run dispatcher and check expectations
@@ -459,9 +616,121 @@ func TestDispatcher(t *testing.T) {
 	})
 }
 
+func TestDispatcherSaveIndexesInfo(t *testing.T) {
+	repo1 := &hub.ChartRepository{ChartRepositoryID: "repo1"}
+	repo2 := &hub.ChartRepository{ChartRepositoryID: "repo2"}
+	repo1IndexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest1", ETag: "etag1"}
+	repo2IndexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest2", ETag: "etag2"}
+
+	t.Run("index info saved only for repositories without errors", func(t *testing.T) {
+		// Setup dispatcher and expectations
+		dw := newDispatcherWrapper(context.Background())
+		dw.il.On("LoadIndexIfChanged", repo1).Return(&repo.IndexFile{}, repo1IndexInfo, nil)
+		dw.il.On("LoadIndexIfChanged", repo2).Return(&repo.IndexFile{}, repo2IndexInfo, nil)
+		dw.rm.On("GetPackagesDigest", dw.d.ctx, mock.Anything).Return(nil, nil)
+		dw.ec.On("HasErrors", "repo1").Return(false)
+		dw.ec.On("HasErrors", "repo2").Return(true)
+		dw.rm.On("SetLastIndexInfo", dw.d.ctx, "repo1", repo1IndexInfo).Return(nil)
+
+		// Run dispatcher, save indexes info and check expectations
+		dw.d.Run(dw.wg, []*hub.ChartRepository{repo1, repo2})
+		dw.wg.Wait()
+		dw.d.SaveIndexesInfo()
+		dw.assertExpectations(t, nil)
+	})
+
+	t.Run("index info updated when unchanged index etag changes", func(t *testing.T) {
+		// Setup dispatcher and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1", LastIndexInfo: repo1IndexInfo}
+		newIndexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest1", ETag: "etag1-updated"}
+		dw := newDispatcherWrapper(context.Background())
+		dw.il.On("LoadIndexIfChanged", r).Return(nil, newIndexInfo, nil)
+		dw.ec.On("HasErrors", "repo1").Return(false)
+		dw.rm.On("SetLastIndexInfo", dw.d.ctx, "repo1", newIndexInfo).Return(nil)
+
+		// Run dispatcher, save indexes info and check expectations
+		dw.d.Run(dw.wg, []*hub.ChartRepository{r})
+		dw.wg.Wait()
+		dw.d.SaveIndexesInfo()
+		dw.assertExpectations(t, nil)
+	})
+
+	t.Run("index info not saved when context is done", func(t *testing.T) {
+		// Setup dispatcher
+		ctx, cancel := context.WithCancel(context.Background())
+		dw := newDispatcherWrapper(ctx)
+		dw.d.indexesInfo["repo1"] = repo1IndexInfo
+		cancel()
+
+		// Save indexes info and check expectations
+		dw.d.SaveIndexesInfo()
+		dw.d.Run(dw.wg, nil)
+		dw.assertExpectations(t, nil)
+	})
+}
+
+func TestDispatcherSyncRepository(t *testing.T) {
+	pkg1V1 := &repo.ChartVersion{
+		Metadata: &chart.Metadata{
+			Name:    "pkg1",
+			Version: "1.0.0",
+		},
+		Digest: "pkg1-1.0.0",
+	}
+
+	t.Run("error loading chart repository index file", func(t *testing.T) {
+		// Setup dispatcher and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1"}
+		dw := newDispatcherWrapper(context.Background())
+		dw.ec.On("Init", r.ChartRepositoryID).Return()
+		dw.il.On("LoadIndexIfChanged", r).Return(nil, nil, errFake)
+		dw.ec.On("Append", r.ChartRepositoryID, mock.Anything).Return()
+		dw.ec.On("FlushRepository", r.ChartRepositoryID).Return()
+
+		// Sync repository and check expectations
+		err := dw.d.SyncRepository(r)
+		assert.Equal(t, errFake, err)
+		dw.wg.Done()
+		close(dw.d.Queue)
+		dw.assertExpectations(t, nil)
+	})
+
+	t.Run("repository synced successfully", func(t *testing.T) {
+		// Setup dispatcher and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1"}
+		indexFile := &repo.IndexFile{
+			Entries: map[string]repo.ChartVersions{
+				"pkg1": []*repo.ChartVersion{pkg1V1},
+			},
+		}
+		indexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest1"}
+		dw := newDispatcherWrapper(context.Background())
+		dw.ec.On("Init", r.ChartRepositoryID).Return()
+		dw.il.On("LoadIndexIfChanged", r).Return(indexFile, indexInfo, nil)
+		dw.rm.On("GetPackagesDigest", dw.d.ctx, r.ChartRepositoryID).Return(nil, nil)
+		dw.ec.On("HasErrors", r.ChartRepositoryID).Return(false)
+		dw.rm.On("SetLastIndexInfo", dw.d.ctx, r.ChartRepositoryID, indexInfo).Return(nil)
+		dw.ec.On("FlushRepository", r.ChartRepositoryID).Return()
+
+		// Sync repository and check expectations
+		err := dw.d.SyncRepository(r)
+		assert.NoError(t, err)
+		dw.wg.Done()
+		close(dw.d.Queue)
+		dw.wg.Wait()
+		dw.il.AssertExpectations(t)
+		dw.rm.AssertExpectations(t)
+		dw.ec.AssertExpectations(t)
+		assert.Len(t, *dw.queuedJobs, 1)
+		assert.Equal(t, pkg1V1, (*dw.queuedJobs)[0].ChartVersion)
+		assert.Empty(t, dw.d.indexesInfo)
+	})
+}
+
 type dispatcherWrapper struct {
 	wg         *sync.WaitGroup
 	il         *chartrepo.IndexLoaderMock
+	pl         *chartrepo.PackagesLoaderMock
 	rm         *chartrepo.ManagerMock
 	ec         *ErrorsCollectorMock
 	d          *Dispatcher
@@ -471,9 +740,10 @@ type dispatcherWrapper struct {
 func newDispatcherWrapper(ctx context.Context) *dispatcherWrapper {
 	// Setup dispatcher
 	il := &chartrepo.IndexLoaderMock{}
+	pl := &chartrepo.PackagesLoaderMock{}
 	rm := &chartrepo.ManagerMock{}
 	ec := &ErrorsCollectorMock{}
-	d := NewDispatcher(ctx, il, rm, ec)
+	d := NewDispatcher(ctx, il, pl, rm, ec)
 
 	// Wait group used for Dispatcher.Run()
 	var wg sync.WaitGroup
@@ -486,6 +756,7 @@ func newDispatcherWrapper(ctx context.Context) *dispatcherWrapper {
 		defer wg.Done()
 		for job := range d.Queue {
 			queuedJobs = append(queuedJobs, job)
+			job.done()
 		}
 	}()
 
@@ -493,6 +764,7 @@ func newDispatcherWrapper(ctx context.Context) *dispatcherWrapper {
 		wg:         &wg,
 		ec:         ec,
 		il:         il,
+		pl:         pl,
 		rm:         rm,
 		d:          d,
 		queuedJobs: &queuedJobs,
@@ -503,6 +775,7 @@ func (dw *dispatcherWrapper) assertExpectations(t *testing.T, expectedJobs []*Jo
 	dw.wg.Wait()
 
 	dw.il.AssertExpectations(t)
+	dw.pl.AssertExpectations(t)
 	dw.rm.AssertExpectations(t)
 	dw.ec.AssertExpectations(t)
 
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// printPlannedChanges prints the changes planned by the jobs received from the
+// queue provided until it's closed, without handling them. It's used when the
+// chart tracker runs in dry run mode. The number of versions that would have
+// been registered and unregistered is returned.
+func printPlannedChanges(out io.Writer, queue <-chan *Job) (registered, unregistered int) {
+	for j := range queue {
+		name, version := j.nameVersion()
+		var action string
+		switch j.Kind {
+		case Register:
+			action = "register"
+			registered++
+		case Unregister:
+			action = "unregister"
+			unregistered++
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", action, j.Repo.Name, name, version)
+		j.done()
+	}
+	return
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestPrintPlannedChanges(t *testing.T) {
+	repo1 := &hub.ChartRepository{Name: "repo1"}
+	repo2 := &hub.ChartRepository{Name: "repo2", Kind: hub.Falco}
+	queue := make(chan *Job, 3)
+	queue <- &Job{
+		Kind: Register,
+		Repo: repo1,
+		ChartVersion: &repo.ChartVersion{
+			Metadata: &chart.Metadata{Name: "pkg1", Version: "1.0.0"},
+		},
+	}
+	queue <- &Job{
+		Kind: Unregister,
+		Repo: repo1,
+		ChartVersion: &repo.ChartVersion{
+			Metadata: &chart.Metadata{Name: "pkg1", Version: "0.9.0"},
+		},
+	}
+	queue <- &Job{
+		Kind:    Unregister,
+		Repo:    repo2,
+		Package: &hub.Package{Name: "Nginx", Version: "1.0.0"},
+	}
+	close(queue)
+
+	var out bytes.Buffer
+	registered, unregistered := printPlannedChanges(&out, queue)
+	assert.Equal(t, 1, registered)
+	assert.Equal(t, 2, unregistered)
+	assert.Equal(t, "register\trepo1\tpkg1\t1.0.0\n"+
+		"unregister\trepo1\tpkg1\t0.9.0\n"+
+		"unregister\trepo2\tNginx\t1.0.0\n", out.String())
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog/log"
 	"github.com/stretchr/testify/mock"
 )
@@ -14,24 +17,57 @@ import (
 // implementation should provide.
 type ErrorsCollector interface {
 	Append(chartRepositoryID string, err error)
+	CountVersions(chartRepositoryID string, result VersionResult, n int)
 	Flush()
+	FlushRepository(chartRepositoryID string)
+	HasErrors(chartRepositoryID string) bool
+	Init(chartRepositoryID string)
 }
 
+// VersionResult represents the result of processing a chart version, which
+// can be registered, unregistered or skipped (unchanged).
+type VersionResult int
+
+const (
+	// VersionRegistered represents a chart version registered successfully.
+	VersionRegistered VersionResult = iota
+
+	// VersionUnregistered represents a chart version unregistered
+	// successfully.
+	VersionUnregistered
+
+	// VersionSkipped represents a chart version that was not processed as it
+	// had not changed.
+	VersionSkipped
+)
+
 const (
 	// maxErrorsPerChartRepository represents the maximum number of errors we
 	// want to collect for a given chart repository.
 	maxErrorsPerChartRepository = 100
 )
 
+// trackingErrors counts the errors collected while tracking chart
+// repositories, labelled by their code and severity.
+var trackingErrors = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "chart_tracker_tracking_errors_total",
+		Help: "Number of errors collected while tracking chart repositories.",
+	},
+	[]string{"code", "severity"},
+)
+
 // DBErrorsCollector is in charge of collecting errors that happen while chart
-// repositories are being processed. Once all the processing is done, the
-// collected errors can be flushed, which will store them in the database.
+// repositories are being processed, as well as the number of chart versions
+// registered, unregistered or skipped. Once all the processing is done, the
+// collected results can be flushed, which will store them in the database as
+// a tracking run.
 type DBErrorsCollector struct {
 	ctx              context.Context
 	chartRepoManager hub.ChartRepositoryManager
 
-	mu     sync.Mutex
-	errors map[string][]error // K: chart repository id
+	mu   sync.Mutex
+	runs map[string]*hub.TrackingRun // K: chart repository id
 }
 
 // NewDBErrorsCollector creates a new DBErrorsCollector instance.
@@ -43,38 +79,137 @@ func NewDBErrorsCollector(
 	ec := &DBErrorsCollector{
 		ctx:              ctx,
 		chartRepoManager: chartRepoManager,
-		errors:           make(map[string][]error),
+		runs:             make(map[string]*hub.TrackingRun),
 	}
 	for _, r := range repos {
-		ec.errors[r.ChartRepositoryID] = nil
+		ec.Init(r.ChartRepositoryID)
 	}
 	return ec
 }
 
+// Init starts a new tracking run for the provided chart repository, discarding
+// any results collected previously that have not been flushed.
+func (c *DBErrorsCollector) Init(chartRepositoryID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.runs[chartRepositoryID] = newTrackingRun(chartRepositoryID)
+}
+
 // Append adds the error provided to the chart repository's list of errors.
+// Errors that are not tracking errors are collected as unknown errors.
 func (c *DBErrorsCollector) Append(chartRepositoryID string, err error) {
+	var trackingErr *hub.TrackingError
+	if !errors.As(err, &trackingErr) {
+		trackingErr = &hub.TrackingError{
+			Code:     hub.UnknownTrackingError,
+			Severity: hub.SeverityError,
+			Message:  err.Error(),
+		}
+	}
+	trackingErrors.WithLabelValues(string(trackingErr.Code), string(trackingErr.Severity)).Inc()
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if len(c.errors[chartRepositoryID]) < maxErrorsPerChartRepository {
-		c.errors[chartRepositoryID] = append(c.errors[chartRepositoryID], err)
+	run := c.getRun(chartRepositoryID)
+	if len(run.Errors) < maxErrorsPerChartRepository {
+		run.Errors = append(run.Errors, trackingErr)
 	}
 }
 
-// Flush aggregates all errors collected per chart repository as a single text
-// and stores it in the database.
-func (c *DBErrorsCollector) Flush() {
-	for chartRepositoryID, errors := range c.errors {
-		var errStr strings.Builder
-		for _, err := range errors {
-			errStr.WriteString(err.Error())
-			errStr.WriteString("\n")
-		}
-		err := c.chartRepoManager.SetLastTrackingResults(c.ctx, chartRepositoryID, errStr.String())
-		if err != nil {
-			log.Error().Err(err).Str("repoID", chartRepositoryID).Send()
+// CountVersions adds the number of chart versions provided to the chart
+// repository's count for the given result.
+func (c *DBErrorsCollector) CountVersions(chartRepositoryID string, result VersionResult, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	run := c.getRun(chartRepositoryID)
+	switch result {
+	case VersionRegistered:
+		run.VersionsRegistered += n
+	case VersionUnregistered:
+		run.VersionsUnregistered += n
+	case VersionSkipped:
+		run.VersionsSkipped += n
+	}
+}
+
+// HasErrors returns whether any error has been collected for the provided
+// chart repository. Warnings are not taken into account.
+func (c *DBErrorsCollector) HasErrors(chartRepositoryID string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	run, ok := c.runs[chartRepositoryID]
+	if !ok {
+		return false
+	}
+	for _, err := range run.Errors {
+		if err.Severity != hub.SeverityWarning {
+			return true
 		}
 	}
+	return false
+}
+
+// Flush stores the results collected for all chart repositories in the
+// database.
+func (c *DBErrorsCollector) Flush() {
+	c.mu.Lock()
+	reposIDs := make([]string, 0, len(c.runs))
+	for chartRepositoryID := range c.runs {
+		reposIDs = append(reposIDs, chartRepositoryID)
+	}
+	c.mu.Unlock()
+	for _, chartRepositoryID := range reposIDs {
+		c.FlushRepository(chartRepositoryID)
+	}
+}
+
+// FlushRepository stores the results collected for the provided chart
+// repository in the database as a tracking run. The errors collected are also
+// aggregated as a single text and stored as the last tracking errors of the
+// repository. Once stored, the results collected for the repository are
+// cleared.
+func (c *DBErrorsCollector) FlushRepository(chartRepositoryID string) {
+	c.mu.Lock()
+	run := c.getRun(chartRepositoryID)
+	delete(c.runs, chartRepositoryID)
+	c.mu.Unlock()
+	run.FinishedAt = time.Now().Unix()
+
+	var errStr strings.Builder
+	for _, err := range run.Errors {
+		errStr.WriteString(err.Error())
+		errStr.WriteString("\n")
+	}
+	err := c.chartRepoManager.SetLastTrackingResults(c.ctx, chartRepositoryID, errStr.String())
+	if err != nil {
+		log.Error().Err(err).Str("repoID", chartRepositoryID).Send()
+	}
+	if err := c.chartRepoManager.RegisterTrackingRun(c.ctx, run); err != nil {
+		log.Error().Err(err).Str("repoID", chartRepositoryID).Msg("error registering tracking run")
+	}
+}
+
+// getRun returns the tracking run of the provided chart repository, starting
+// a new one if needed. It must be called with the mutex held.
+func (c *DBErrorsCollector) getRun(chartRepositoryID string) *hub.TrackingRun {
+	run, ok := c.runs[chartRepositoryID]
+	if !ok {
+		run = newTrackingRun(chartRepositoryID)
+		c.runs[chartRepositoryID] = run
+	}
+	return run
+}
+
+// newTrackingRun creates a new tracking run for the provided chart repository.
+func newTrackingRun(chartRepositoryID string) *hub.TrackingRun {
+	return &hub.TrackingRun{
+		ChartRepositoryID: chartRepositoryID,
+		StartedAt:         time.Now().Unix(),
+	}
 }
 
 // ErrorsCollectorMock is mock ErrorsCollector implementation.
@@ -87,7 +222,28 @@ func (m *ErrorsCollectorMock) Append(chartRepositoryID string, err error) {
 	m.Called(chartRepositoryID, err)
 }
 
+// CountVersions implements the ErrorsCollector interface.
+func (m *ErrorsCollectorMock) CountVersions(chartRepositoryID string, result VersionResult, n int) {
+	m.Called(chartRepositoryID, result, n)
+}
+
 // Flush implements the ErrorsCollector interface.
 func (m *ErrorsCollectorMock) Flush() {
 	m.Called()
 }
+
+// FlushRepository implements the ErrorsCollector interface.
+func (m *ErrorsCollectorMock) FlushRepository(chartRepositoryID string) {
+	m.Called(chartRepositoryID)
+}
+
+// HasErrors implements the ErrorsCollector interface.
+func (m *ErrorsCollectorMock) HasErrors(chartRepositoryID string) bool {
+	args := m.Called(chartRepositoryID)
+	return args.Bool(0)
+}
+
+// Init implements the ErrorsCollector interface.
+func (m *ErrorsCollectorMock) Init(chartRepositoryID string) {
+	m.Called(chartRepositoryID)
+}
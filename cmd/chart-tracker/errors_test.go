@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDBErrorsCollector(t *testing.T) {
+	repoID := "00000000-0000-0000-0000-000000000001"
+
+	t.Run("tracking run registered when repository is flushed", func(t *testing.T) {
+		// Setup errors collector and expectations
+		rm := &chartrepo.ManagerMock{}
+		ec := NewDBErrorsCollector(context.Background(), rm, []*hub.ChartRepository{
+			{ChartRepositoryID: repoID},
+		})
+		runErr := &hub.TrackingError{
+			Code:     hub.ChartDownloadFailed,
+			Severity: hub.SeverityError,
+			Chart:    "pkg1",
+			Version:  "1.0.0",
+			Message:  "error loading chart",
+		}
+		rm.On("SetLastTrackingResults", mock.Anything, repoID, "error loading chart\nfake error for tests\n").
+			Return(nil)
+		rm.On("RegisterTrackingRun", mock.Anything, mock.MatchedBy(func(run *hub.TrackingRun) bool {
+			return run.ChartRepositoryID == repoID &&
+				run.StartedAt > 0 &&
+				run.FinishedAt >= run.StartedAt &&
+				run.VersionsRegistered == 2 &&
+				run.VersionsUnregistered == 1 &&
+				run.VersionsSkipped == 3 &&
+				assert.ObjectsAreEqual([]*hub.TrackingError{
+					runErr,
+					{Code: hub.UnknownTrackingError, Severity: hub.SeverityError, Message: errFake.Error()},
+				}, run.Errors)
+		})).Return(nil)
+
+		// Collect some results, flush them and check expectations
+		ec.CountVersions(repoID, VersionRegistered, 1)
+		ec.CountVersions(repoID, VersionRegistered, 1)
+		ec.CountVersions(repoID, VersionUnregistered, 1)
+		ec.CountVersions(repoID, VersionSkipped, 3)
+		ec.Append(repoID, runErr)
+		ec.Append(repoID, errFake)
+		assert.True(t, ec.HasErrors(repoID))
+		ec.FlushRepository(repoID)
+		assert.False(t, ec.HasErrors(repoID))
+		rm.AssertExpectations(t)
+	})
+
+	t.Run("warnings are not considered errors", func(t *testing.T) {
+		rm := &chartrepo.ManagerMock{}
+		ec := NewDBErrorsCollector(context.Background(), rm, []*hub.ChartRepository{
+			{ChartRepositoryID: repoID},
+		})
+		ec.Append(repoID, &hub.TrackingError{
+			Code:     hub.LogoFailed,
+			Severity: hub.SeverityWarning,
+			Message:  "error getting logo image",
+		})
+		assert.False(t, ec.HasErrors(repoID))
+		ec.Append(repoID, &hub.TrackingError{
+			Code:     hub.RegisterFailed,
+			Severity: hub.SeverityError,
+			Message:  "error registering package",
+		})
+		assert.True(t, ec.HasErrors(repoID))
+	})
+
+	t.Run("tracking run without errors registered on flush", func(t *testing.T) {
+		// Setup errors collector and expectations
+		rm := &chartrepo.ManagerMock{}
+		ec := NewDBErrorsCollector(context.Background(), rm, []*hub.ChartRepository{
+			{ChartRepositoryID: repoID},
+		})
+		rm.On("SetLastTrackingResults", mock.Anything, repoID, "").Return(nil)
+		rm.On("RegisterTrackingRun", mock.Anything, mock.MatchedBy(func(run *hub.TrackingRun) bool {
+			return run.ChartRepositoryID == repoID && len(run.Errors) == 0
+		})).Return(nil)
+
+		// Flush results and check expectations
+		ec.Flush()
+		rm.AssertExpectations(t)
+	})
+}
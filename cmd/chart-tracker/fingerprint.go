@@ -0,0 +1,56 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+// getContentFingerprint returns a fingerprint of the contents of the provided
+// chart, computed by hashing its templates and default values. The chart
+// metadata is not taken into account, so the same chart republished in other
+// repositories gets the same fingerprint even if its name or version changed.
+// An empty fingerprint is returned for charts without templates nor values.
+func getContentFingerprint(c *chart.Chart) string {
+	files := make([]*chart.File, 0, len(c.Templates)+1)
+	files = append(files, c.Templates...)
+	if values := getRawFile(c, "values.yaml"); values != nil {
+		files = append(files, values)
+	}
+	if len(files) == 0 {
+		return ""
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	h := sha256.New()
+	for _, file := range files {
+		fmt.Fprintf(h, "%s\x00%d\x00", file.Name, len(file.Data))
+		h.Write(file.Data)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// getUpstreamSource returns a reference to the upstream source of the chart
+// provided, built from its first source url and its name (i.e.
+// github.com/org/repo#chart). Forks of a chart usually keep its sources, so
+// they can be linked to the original chart even when their contents differ.
+func getUpstreamSource(md *chart.Metadata) string {
+	for _, source := range md.Sources {
+		u, err := url.Parse(strings.TrimSpace(source))
+		if err != nil || u.Host == "" {
+			continue
+		}
+		host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+		p := strings.TrimSuffix(strings.Trim(strings.ToLower(u.Path), "/"), ".git")
+		if p == "" {
+			return host + "#" + md.Name
+		}
+		return host + "/" + p + "#" + md.Name
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestGetContentFingerprint(t *testing.T) {
+	newChart := func(name, version string, templates ...*chart.File) *chart.Chart {
+		return &chart.Chart{
+			Metadata:  &chart.Metadata{Name: name, Version: version},
+			Templates: templates,
+			Raw:       []*chart.File{{Name: "values.yaml", Data: []byte("key: value\n")}},
+		}
+	}
+	deployment := &chart.File{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment\n")}
+	service := &chart.File{Name: "templates/service.yaml", Data: []byte("kind: Service\n")}
+
+	t.Run("same contents with different metadata share fingerprint", func(t *testing.T) {
+		fp1 := getContentFingerprint(newChart("pkg1", "1.0.0", deployment, service))
+		fp2 := getContentFingerprint(newChart("pkg1-fork", "2.0.0", service, deployment))
+		assert.NotEmpty(t, fp1)
+		assert.Equal(t, fp1, fp2)
+	})
+
+	t.Run("different contents get different fingerprints", func(t *testing.T) {
+		fp1 := getContentFingerprint(newChart("pkg1", "1.0.0", deployment, service))
+		fp2 := getContentFingerprint(newChart("pkg1", "1.0.0", deployment))
+		assert.NotEqual(t, fp1, fp2)
+	})
+
+	t.Run("no fingerprint for charts without templates nor values", func(t *testing.T) {
+		c := &chart.Chart{Metadata: &chart.Metadata{Name: "pkg1", Version: "1.0.0"}}
+		assert.Empty(t, getContentFingerprint(c))
+	})
+}
+
+func TestGetUpstreamSource(t *testing.T) {
+	testCases := []struct {
+		sources  []string
+		expected string
+	}{
+		{nil, ""},
+		{[]string{"invalid"}, ""},
+		{[]string{"https://github.com/org/repo"}, "github.com/org/repo#pkg1"},
+		{[]string{"https://www.GitHub.com/Org/Repo.git/"}, "github.com/org/repo#pkg1"},
+		{[]string{"invalid", "http://pkg1.io"}, "pkg1.io#pkg1"},
+	}
+	for _, tc := range testCases {
+		md := &chart.Metadata{Name: "pkg1", Sources: tc.sources}
+		assert.Equal(t, tc.expected, getUpstreamSource(md))
+	}
+}
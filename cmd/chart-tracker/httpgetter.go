@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultHTTPTimeout represents the maximum amount of time a request made
+	// by the http getter can take.
+	defaultHTTPTimeout = 10 * time.Second
+
+	// defaultHTTPMaxRetries represents the maximum number of times a request
+	// is retried when it fails with a retryable status code.
+	defaultHTTPMaxRetries = 3
+
+	// defaultHTTPBackoffBase represents the base delay used when retrying a
+	// request that failed.
+	defaultHTTPBackoffBase = 1 * time.Second
+
+	// defaultHTTPBackoffMax represents the maximum delay used when retrying a
+	// request that failed, including the one requested by the server.
+	defaultHTTPBackoffMax = 30 * time.Second
+
+	// defaultHTTPMaxConnsPerHost represents the maximum number of concurrent
+	// requests made to the same host.
+	defaultHTTPMaxConnsPerHost = 5
+
+	// defaultHTTPUserAgent represents the user agent used in the requests.
+	defaultHTTPUserAgent = "artifacthub-chart-tracker (+https://github.com/artifacthub/hub)"
+)
+
+// RetryingHTTPGetter is an HTTPGetter implementation that retries the requests
+// failing with a 5xx or 429 status code using an exponential backoff, honoring
+// the Retry-After header when provided. The number of concurrent requests made
+// to the same host is limited, so that hosts serving many charts (i.e. GitHub
+// Pages) are not hammered by the workers.
+type RetryingHTTPGetter struct {
+	ctx             context.Context
+	hc              *http.Client
+	userAgent       string
+	maxRetries      int
+	backoffBase     time.Duration
+	backoffMax      time.Duration
+	maxConnsPerHost int
+	sleep           func(ctx context.Context, d time.Duration)
+
+	mu    sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+// NewRetryingHTTPGetter creates a new RetryingHTTPGetter instance, using the
+// settings available in the tracker.http configuration section.
+func NewRetryingHTTPGetter(ctx context.Context, cfg *viper.Viper) *RetryingHTTPGetter {
+	g := &RetryingHTTPGetter{
+		ctx:             ctx,
+		hc:              &http.Client{Timeout: defaultHTTPTimeout},
+		userAgent:       defaultHTTPUserAgent,
+		maxRetries:      defaultHTTPMaxRetries,
+		backoffBase:     defaultHTTPBackoffBase,
+		backoffMax:      defaultHTTPBackoffMax,
+		maxConnsPerHost: defaultHTTPMaxConnsPerHost,
+		sleep:           sleep,
+		hosts:           make(map[string]chan struct{}),
+	}
+	if cfg.IsSet("tracker.http.timeout") {
+		g.hc.Timeout = cfg.GetDuration("tracker.http.timeout")
+	}
+	if cfg.IsSet("tracker.http.userAgent") {
+		g.userAgent = cfg.GetString("tracker.http.userAgent")
+	}
+	if cfg.IsSet("tracker.http.maxRetries") {
+		g.maxRetries = cfg.GetInt("tracker.http.maxRetries")
+	}
+	if cfg.IsSet("tracker.http.backoffBase") {
+		g.backoffBase = cfg.GetDuration("tracker.http.backoffBase")
+	}
+	if cfg.IsSet("tracker.http.backoffMax") {
+		g.backoffMax = cfg.GetDuration("tracker.http.backoffMax")
+	}
+	if cfg.IsSet("tracker.http.maxConnsPerHost") {
+		g.maxConnsPerHost = cfg.GetInt("tracker.http.maxConnsPerHost")
+	}
+	return g
+}
+
+// Get sends a GET request to the url provided, retrying it when needed. The
+// response of the last attempt is returned. Its body must be closed once
+// processed, as that releases the slot used for its host.
+func (g *RetryingHTTPGetter) Get(u string) (*http.Response, error) {
+	return g.get(g.hc, u)
+}
+
+// GetFromRepository works like Get, but the request is sent using the TLS
+// settings and credentials of the chart repository provided, which the url
+// must belong to.
+func (g *RetryingHTTPGetter) GetFromRepository(r *hub.ChartRepository, u string) (*http.Response, error) {
+	t, err := chartrepo.Transport(r, g.hc.Transport)
+	if err != nil {
+		return nil, err
+	}
+	return g.get(&http.Client{Timeout: g.hc.Timeout, Transport: t}, u)
+}
+
+// get sends a GET request to the url provided using the http client given,
+// retrying it when needed.
+func (g *RetryingHTTPGetter) get(hc *http.Client, u string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(g.ctx)
+	req.Header.Set("User-Agent", g.userAgent)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := g.do(hc, req)
+		if err != nil || !isRetryableStatus(resp.StatusCode) || attempt > g.maxRetries {
+			return resp, err
+		}
+		delay := g.backoff(attempt)
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			delay = retryAfter
+			if delay > g.backoffMax {
+				delay = g.backoffMax
+			}
+		}
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		log.Debug().
+			Str("url", u).
+			Int("statusCode", resp.StatusCode).
+			Int("attempt", attempt).
+			Dur("delay", delay).
+			Msg("retrying request")
+		g.sleep(g.ctx, delay)
+		if g.ctx.Err() != nil {
+			return nil, g.ctx.Err()
+		}
+	}
+}
+
+// do sends the request provided using the http client given once a slot for
+// its host is available. The slot is released when the response body is
+// closed or the request fails.
+func (g *RetryingHTTPGetter) do(hc *http.Client, req *http.Request) (*http.Response, error) {
+	release, err := g.acquire(req.URL.Host)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// acquire waits until a slot for the host provided is available, returning a
+// function that must be called to release it.
+func (g *RetryingHTTPGetter) acquire(host string) (func(), error) {
+	if g.maxConnsPerHost <= 0 {
+		return func() {}, nil
+	}
+	g.mu.Lock()
+	slots, ok := g.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, g.maxConnsPerHost)
+		g.hosts[host] = slots
+	}
+	g.mu.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+	case <-g.ctx.Done():
+		return nil, g.ctx.Err()
+	}
+	var once sync.Once
+	return func() { once.Do(func() { <-slots }) }, nil
+}
+
+// backoff returns the delay to wait before retrying a request that failed the
+// number of consecutive times provided. The delay grows exponentially up to
+// the maximum configured, and half of it is jittered.
+func (g *RetryingHTTPGetter) backoff(failures int) time.Duration {
+	delay := float64(g.backoffBase) * math.Pow(2, float64(failures-1))
+	if delay > float64(g.backoffMax) {
+		delay = float64(g.backoffMax)
+	}
+	return time.Duration(delay/2 + rand.Float64()*delay/2) // #nosec
+}
+
+// releasingBody is an io.ReadCloser wrapper that releases the host slot used
+// by the request when the response body is closed.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close implements the io.Closer interface.
+func (b *releasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
+}
+
+// isRetryableStatus checks if a request that received the status code
+// provided should be retried.
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// parseRetryAfter parses the value of the Retry-After header provided, which
+// can be a number of seconds or an http date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		delay := time.Until(t)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleep pauses the current goroutine for the duration provided or until the
+// context is done.
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryingHTTPGetter(t *testing.T) {
+	newGetter := func(cfg *viper.Viper) (*RetryingHTTPGetter, *[]time.Duration) {
+		g := NewRetryingHTTPGetter(context.Background(), cfg)
+		var delays []time.Duration
+		g.sleep = func(ctx context.Context, d time.Duration) {
+			delays = append(delays, d)
+		}
+		return g, &delays
+	}
+
+	t.Run("request succeeds at first attempt", func(t *testing.T) {
+		var userAgent string
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userAgent = r.Header.Get("User-Agent")
+		}))
+		defer s.Close()
+
+		cfg := viper.New()
+		cfg.Set("tracker.http.userAgent", "test-agent")
+		g, delays := newGetter(cfg)
+		resp, err := g.Get(s.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "test-agent", userAgent)
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("request retried until it succeeds", func(t *testing.T) {
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer s.Close()
+
+		cfg := viper.New()
+		cfg.Set("tracker.http.backoffBase", "1s")
+		g, delays := newGetter(cfg)
+		resp, err := g.Get(s.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(3), attempts)
+		require.Len(t, *delays, 2)
+		assert.True(t, (*delays)[0] >= 500*time.Millisecond && (*delays)[0] <= 1*time.Second)
+		assert.True(t, (*delays)[1] >= 1*time.Second && (*delays)[1] <= 2*time.Second)
+	})
+
+	t.Run("last response returned when retries are exhausted", func(t *testing.T) {
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer s.Close()
+
+		cfg := viper.New()
+		cfg.Set("tracker.http.maxRetries", 2)
+		g, delays := newGetter(cfg)
+		resp, err := g.Get(s.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		assert.Equal(t, int32(3), attempts)
+		assert.Len(t, *delays, 2)
+	})
+
+	t.Run("retry after header honored", func(t *testing.T) {
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(http.StatusTooManyRequests)
+			}
+		}))
+		defer s.Close()
+
+		g, delays := newGetter(viper.New())
+		resp, err := g.Get(s.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []time.Duration{7 * time.Second}, *delays)
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		var attempts int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&attempts, 1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer s.Close()
+
+		g, delays := newGetter(viper.New())
+		resp, err := g.Get(s.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, int32(1), attempts)
+		assert.Empty(t, *delays)
+	})
+
+	t.Run("concurrent requests per host limited", func(t *testing.T) {
+		var current, max int32
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			n := atomic.AddInt32(&current, 1)
+			for {
+				m := atomic.LoadInt32(&max)
+				if n <= m || atomic.CompareAndSwapInt32(&max, m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&current, -1)
+		}))
+		defer s.Close()
+
+		cfg := viper.New()
+		cfg.Set("tracker.http.maxConnsPerHost", 2)
+		g, _ := newGetter(cfg)
+		var wg sync.WaitGroup
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := g.Get(s.URL)
+				if assert.NoError(t, err) {
+					resp.Body.Close()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.LessOrEqual(t, max, int32(2))
+	})
+
+	t.Run("request to private repository retried using its credentials and tls settings", func(t *testing.T) {
+		var attempts int32
+		s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			if atomic.AddInt32(&attempts, 1) < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer s.Close()
+		r := &hub.ChartRepository{
+			URL:         s.URL,
+			Credentials: &hub.ChartRepositoryCredentials{Username: "user", Password: "pass"},
+			TLSConfig: &hub.ChartRepositoryTLSConfig{
+				CABundle: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})),
+			},
+		}
+
+		g, delays := newGetter(viper.New())
+		resp, err := g.GetFromRepository(r, s.URL+"/pkg1-1.0.0.tgz")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(2), attempts)
+		assert.Len(t, *delays, 1)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	testCases := []struct {
+		value         string
+		expectedOK    bool
+		expectedDelay time.Duration
+	}{
+		{"", false, 0},
+		{"invalid", false, 0},
+		{"30", true, 30 * time.Second},
+		{time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat), true, 0},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			delay, ok := parseRetryAfter(tc.value)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedDelay, delay)
+		})
+	}
+}
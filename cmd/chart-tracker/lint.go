@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/lint"
+	"helm.sh/helm/v3/pkg/lint/support"
+)
+
+// lintChart runs the Helm lint rules on the provided chart archive, returning
+// the messages reported grouped by severity. The archive is extracted to a
+// temporary directory, as the linter works on the chart files.
+func lintChart(name string, archive []byte) (*hub.LintReport, error) {
+	tmpDir, err := ioutil.TempDir("", "chart-tracker-lint")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	if err := chartutil.Expand(tmpDir, bytes.NewReader(archive)); err != nil {
+		return nil, err
+	}
+
+	linter := lint.All(filepath.Join(tmpDir, name), nil, "", false)
+	report := &hub.LintReport{}
+	for _, msg := range linter.Messages {
+		m := &hub.LintMessage{
+			Path:    msg.Path,
+			Message: msg.Err.Error(),
+		}
+		switch msg.Severity {
+		case support.ErrorSev:
+			report.Errors = append(report.Errors, m)
+		case support.WarningSev:
+			report.Warnings = append(report.Warnings, m)
+		default:
+			report.Info = append(report.Info, m)
+		}
+	}
+	return report, nil
+}
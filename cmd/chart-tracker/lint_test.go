@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintChart(t *testing.T) {
+	t.Run("invalid archive", func(t *testing.T) {
+		_, err := lintChart("pkg1", []byte("invalid"))
+		assert.Error(t, err)
+	})
+
+	t.Run("chart with warnings", func(t *testing.T) {
+		archive, err := ioutil.ReadFile("testdata/pkg1-1.0.0.tgz")
+		require.NoError(t, err)
+		report, err := lintChart("pkg1", archive)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.LintReport{
+			Warnings: []*hub.LintMessage{
+				{Path: "templates/", Message: "directory not found"},
+			},
+			Info: []*hub.LintMessage{
+				{Path: "values.yaml", Message: "file does not exist"},
+			},
+		}, report)
+		assert.False(t, report.IsClean())
+	})
+
+	t.Run("clean chart", func(t *testing.T) {
+		archive, err := ioutil.ReadFile("testdata/pkg3-1.0.0.tgz")
+		require.NoError(t, err)
+		report, err := lintChart("pkg3", archive)
+		require.NoError(t, err)
+		assert.Equal(t, &hub.LintReport{
+			Info: []*hub.LintMessage{
+				{Path: "Chart.yaml", Message: "icon is recommended"},
+			},
+		}, report)
+		assert.True(t, report.IsClean())
+	})
+}
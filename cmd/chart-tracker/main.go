@@ -2,28 +2,53 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
-	"time"
 
 	"github.com/artifacthub/hub/internal/chartrepo"
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/img"
 	"github.com/artifacthub/hub/internal/pkg"
 	"github.com/artifacthub/hub/internal/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 )
 
+var (
+	dryRun                 = flag.Bool("dry-run", false, "Print the changes planned without applying them")
+	forceReprocess         = flag.Bool("force-reprocess", false, "Register again all package versions, even if they have not changed")
+	forceReprocessRepos    = flag.String("force-reprocess-repos", "", "Comma separated list of repositories whose package versions will be registered again")
+	forceReprocessPackages = flag.String("force-reprocess-packages", "", "Comma separated list of packages (repository/package) whose versions will be registered again")
+)
+
 func main() {
+	flag.Parse()
+
 	// Setup configuration and logger
 	cfg, err := util.SetupConfig("chart-tracker")
 	if err != nil {
 		log.Fatal().Err(err).Msg("configuration setup failed")
 	}
+	if *dryRun {
+		cfg.Set("tracker.dryRun", true)
+	}
+	if *forceReprocess {
+		cfg.Set("tracker.forceReprocess.all", true)
+	}
+	if *forceReprocessRepos != "" {
+		cfg.Set("tracker.forceReprocess.repositories", strings.Split(*forceReprocessRepos, ","))
+	}
+	if *forceReprocessPackages != "" {
+		cfg.Set("tracker.forceReprocess.packages", strings.Split(*forceReprocessPackages, ","))
+	}
 	fields := map[string]interface{}{
 		"cmd": "chart-tracker",
 	}
@@ -48,34 +73,207 @@ func main() {
 		log.Fatal().Err(err).Msg("database setup failed")
 	}
 	il := &chartrepo.IndexLoader{}
-	rm := chartrepo.NewManager(db)
+	pl := &chartrepo.PackagesLoader{}
+	rm := chartrepo.NewManager(db, chartrepo.WithCredentialsKey(cfg.GetString("credentials.encryptionKey")))
 	pm := pkg.NewManager(db)
 	is, err := util.SetupImageStore(cfg, db)
 	if err != nil {
 		log.Fatal().Err(err).Msg("image store setup failed")
 	}
 
+	// Setup and launch metrics server when enabled
+	prometheus.MustRegister(reposProcessed, trackingErrors)
+	if addr := cfg.GetString("tracker.metricsAddr"); addr != "" {
+		go func() {
+			http.Handle("/metrics", promhttp.Handler())
+			if err := http.ListenAndServe(addr, nil); err != nil {
+				log.Error().Err(err).Msg("metrics server ListenAndServe failed")
+			}
+		}()
+	}
+
+	// Launch dispatcher and workers in the mode configured
+	// The http getter is not tied to the context, as in daemon mode jobs in
+	// flight are completed before shutting down.
+	hc := NewRetryingHTTPGetter(context.Background(), cfg)
+	op := &chartrepo.OCIClient{}
+	c, err := setupCache(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cache setup failed")
+	}
+	switch mode := cfg.GetString("tracker.mode"); mode {
+	case "", "oneshot":
+		if cfg.GetBool("tracker.dryRun") {
+			runDryRun(ctx, cfg, il, pl, rm)
+			break
+		}
+		rp, err := NewReprocess(cfg)
+		if err != nil {
+			log.Fatal().Err(err).Msg("forced reprocessing setup failed")
+		}
+		runOneShot(ctx, cfg, il, pl, rm, pm, is, hc, op, c, rp)
+	case "daemon":
+		if cfg.GetBool("tracker.dryRun") {
+			log.Fatal().Msg("dry run is only supported in oneshot mode")
+		}
+		if cfg.GetBool("tracker.forceReprocess.all") ||
+			len(cfg.GetStringSlice("tracker.forceReprocess.repositories")) > 0 ||
+			len(cfg.GetStringSlice("tracker.forceReprocess.packages")) > 0 {
+			log.Fatal().Msg("forced reprocessing is only supported in oneshot mode")
+		}
+		runDaemon(ctx, cfg, il, pl, rm, pm, is, hc, op, c)
+	default:
+		log.Fatal().Str("mode", mode).Msg("invalid tracker mode")
+	}
+	log.Info().Msg("chart tracker finished")
+}
+
+// runOneShot processes the chart repositories configured once, waiting for
+// the dispatcher and workers to finish before returning.
+func runOneShot(
+	ctx context.Context,
+	cfg *viper.Viper,
+	il hub.ChartRepositoryIndexLoader,
+	pl hub.ChartRepositoryPackagesLoader,
+	rm hub.ChartRepositoryManager,
+	pm hub.PackageManager,
+	is img.Store,
+	hc HTTPGetter,
+	op OCIPuller,
+	c Cache,
+	rp *Reprocess,
+) {
 	// Get chart repositories to process
 	repos, err := getChartRepositories(cfg, rm)
 	if err != nil {
 		log.Fatal().Err(err).Send()
 	}
 
+	// Claim pending sync requests of the chart repositories to process, so
+	// that they can be completed once they have been tracked
+	reposIDs := make([]string, 0, len(repos))
+	for _, r := range repos {
+		reposIDs = append(reposIDs, r.ChartRepositoryID)
+	}
+	syncRequests, err := rm.ClaimSyncRequests(ctx, reposIDs)
+	if err != nil {
+		log.Error().Err(err).Msg("error claiming sync requests")
+	}
+
 	// Launch dispatcher and workers and wait for them to finish
 	var wg sync.WaitGroup
 	ec := NewDBErrorsCollector(ctx, rm, repos)
-	dispatcher := NewDispatcher(ctx, il, rm, ec)
+	dispatcher := NewDispatcher(ctx, il, pl, rm, ec, WithReprocess(rp))
 	wg.Add(1)
 	go dispatcher.Run(&wg, repos)
-	hc := &http.Client{Timeout: 10 * time.Second}
 	for i := 0; i < cfg.GetInt("tracker.numWorkers"); i++ {
-		w := NewWorker(ctx, i, pm, is, ec, hc)
+		w := NewWorker(ctx, i, pm, is, ec, hc, op, c)
 		wg.Add(1)
 		go w.Run(&wg, dispatcher.Queue)
 	}
 	wg.Wait()
+	finishOneShot(dispatcher, ec)
+
+	// Finish forced reprocessing run
+	if rp != nil {
+		rp.Finish(ctx.Err() != nil)
+	}
+
+	// Complete sync requests claimed
+	if len(syncRequests) > 0 {
+		syncRequestsIDs := make([]string, 0, len(syncRequests))
+		for _, req := range syncRequests {
+			syncRequestsIDs = append(syncRequestsIDs, req.SyncRequestID)
+		}
+		if err := rm.CompleteSyncRequests(context.Background(), syncRequestsIDs); err != nil {
+			log.Error().Err(err).Msg("error completing sync requests")
+		}
+	}
+}
+
+// finishOneShot saves the information of the indexes processed and stores the
+// results collected once all the jobs have been handled. The indexes
+// information must be saved first, as flushing the results clears the errors
+// used to decide if it can be saved.
+func finishOneShot(d *Dispatcher, ec ErrorsCollector) {
+	d.SaveIndexesInfo()
 	ec.Flush()
-	log.Info().Msg("chart tracker finished")
+}
+
+// runDryRun processes the chart repositories configured once, printing the
+// changes planned to keep their packages in sync instead of applying them.
+// Nothing is stored in the database, not even the tracking results.
+func runDryRun(
+	ctx context.Context,
+	cfg *viper.Viper,
+	il hub.ChartRepositoryIndexLoader,
+	pl hub.ChartRepositoryPackagesLoader,
+	rm hub.ChartRepositoryManager,
+) {
+	// Get chart repositories to process
+	repos, err := getChartRepositories(cfg, rm)
+	if err != nil {
+		log.Fatal().Err(err).Send()
+	}
+
+	// Launch dispatcher and print the changes planned
+	var wg sync.WaitGroup
+	ec := NewDBErrorsCollector(ctx, rm, repos)
+	dispatcher := NewDispatcher(ctx, il, pl, rm, ec)
+	wg.Add(1)
+	go dispatcher.Run(&wg, repos)
+	registered, unregistered := printPlannedChanges(os.Stdout, dispatcher.Queue)
+	wg.Wait()
+	log.Info().
+		Int("register", registered).
+		Int("unregister", unregistered).
+		Msg("dry run completed, no changes applied")
+}
+
+// runDaemon keeps processing the chart repositories configured periodically,
+// each one on its own interval, until the context is done. Workers are not
+// tied to the context, so that the jobs in flight can be completed before
+// shutting down.
+func runDaemon(
+	ctx context.Context,
+	cfg *viper.Viper,
+	il hub.ChartRepositoryIndexLoader,
+	pl hub.ChartRepositoryPackagesLoader,
+	rm hub.ChartRepositoryManager,
+	pm hub.PackageManager,
+	is img.Store,
+	hc HTTPGetter,
+	op OCIPuller,
+	c Cache,
+) {
+	var wg sync.WaitGroup
+	ec := NewDBErrorsCollector(context.Background(), rm, nil)
+	dispatcher := NewDispatcher(ctx, il, pl, rm, ec)
+	scheduler := NewScheduler(ctx, cfg, dispatcher, func() ([]*hub.ChartRepository, error) {
+		return getChartRepositories(cfg, rm)
+	})
+	wg.Add(1)
+	go scheduler.Run(&wg)
+	for i := 0; i < cfg.GetInt("tracker.numWorkers"); i++ {
+		w := NewWorker(context.Background(), i, pm, is, ec, hc, op, c)
+		wg.Add(1)
+		go w.Run(&wg, dispatcher.Queue)
+	}
+	wg.Wait()
+}
+
+// setupCache creates the disk cache used to store the chart archives and logos
+// downloaded, when a directory has been configured for it.
+func setupCache(cfg *viper.Viper) (Cache, error) {
+	dir := cfg.GetString("tracker.cache.dir")
+	if dir == "" {
+		return nil, nil
+	}
+	maxSize := int64(defaultCacheMaxSize)
+	if cfg.IsSet("tracker.cache.maxSize") {
+		maxSize = int64(cfg.GetSizeInBytes("tracker.cache.maxSize"))
+	}
+	return NewDiskCache(dir, maxSize)
 }
 
 // getChartRepositories gets the details of the chart repositories the chart
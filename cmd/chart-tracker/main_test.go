@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"os"
 	"testing"
 
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/artifacthub/hub/internal/hub"
 	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/mock"
 )
 
 var errFake = errors.New("fake error for tests")
@@ -14,3 +18,29 @@ func TestMain(m *testing.M) {
 	zerolog.SetGlobalLevel(zerolog.Disabled)
 	os.Exit(m.Run())
 }
+
+func TestFinishOneShot(t *testing.T) {
+	t.Run("index info not saved for repositories with errors", func(t *testing.T) {
+		// Setup dispatcher, errors collector and expectations
+		ctx := context.Background()
+		repo1IndexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest1"}
+		repo2IndexInfo := &hub.ChartRepositoryIndexInfo{Digest: "digest2"}
+		rm := &chartrepo.ManagerMock{}
+		ec := NewDBErrorsCollector(ctx, rm, []*hub.ChartRepository{
+			{ChartRepositoryID: "repo1"},
+			{ChartRepositoryID: "repo2"},
+		})
+		d := NewDispatcher(ctx, &chartrepo.IndexLoaderMock{}, &chartrepo.PackagesLoaderMock{}, rm, ec)
+		d.indexesInfo["repo1"] = repo1IndexInfo
+		d.indexesInfo["repo2"] = repo2IndexInfo
+		ec.Append("repo1", errFake)
+		rm.On("SetLastIndexInfo", ctx, "repo2", repo2IndexInfo).Return(nil)
+		rm.On("SetLastTrackingResults", ctx, mock.Anything, mock.Anything).Return(nil)
+		rm.On("RegisterTrackingRun", ctx, mock.Anything).Return(nil)
+
+		// Finish run and check expectations
+		finishOneShot(d, ec)
+		rm.AssertExpectations(t)
+		rm.AssertNotCalled(t, "SetLastIndexInfo", ctx, "repo1", mock.Anything)
+	})
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	// reprocessStateFile represents the name of the file used by default to
+	// keep track of the progress of a forced reprocessing run.
+	reprocessStateFile = "chart-tracker-reprocess.state"
+
+	// reprocessProgressInterval represents how often, in number of package
+	// versions reprocessed, the progress of a forced run is reported.
+	reprocessProgressInterval = 100
+)
+
+// Reprocess represents a forced reprocessing run, in which the package
+// versions selected are registered again even if their digest has not
+// changed. The package versions reprocessed are recorded in a state file, so
+// that an interrupted run can be resumed without starting from scratch.
+type Reprocess struct {
+	all          bool
+	repositories map[string]struct{} // K: chart repository name
+	packages     map[string]struct{} // K: chart repository name/package name
+
+	mu       sync.Mutex
+	f        *os.File
+	done     map[string]struct{} // K: chart repository id package@version
+	resumed  int
+	enqueued int
+	handled  int
+	failed   []string
+}
+
+// NewReprocess creates a new Reprocess instance from the tracker.forceReprocess
+// configuration section. When no package versions have been selected to be
+// reprocessed, nil is returned. If the state file of a previous run using the
+// same selection exists, the run is resumed from it.
+func NewReprocess(cfg *viper.Viper) (*Reprocess, error) {
+	rp := &Reprocess{
+		all:          cfg.GetBool("tracker.forceReprocess.all"),
+		repositories: make(map[string]struct{}),
+		packages:     make(map[string]struct{}),
+		done:         make(map[string]struct{}),
+	}
+	for _, name := range cfg.GetStringSlice("tracker.forceReprocess.repositories") {
+		if name != "" {
+			rp.repositories[name] = struct{}{}
+		}
+	}
+	for _, name := range cfg.GetStringSlice("tracker.forceReprocess.packages") {
+		if name == "" {
+			continue
+		}
+		if !strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid package to reprocess %s (repository/package expected)", name)
+		}
+		rp.packages[name] = struct{}{}
+	}
+	if !rp.all && len(rp.repositories) == 0 && len(rp.packages) == 0 {
+		return nil, nil
+	}
+
+	stateFile := cfg.GetString("tracker.forceReprocess.stateFile")
+	if stateFile == "" {
+		stateFile = filepath.Join(os.TempDir(), reprocessStateFile)
+	}
+	if err := rp.loadState(stateFile); err != nil {
+		return nil, err
+	}
+	return rp, nil
+}
+
+// loadState loads the package versions already reprocessed from the state
+// file provided when it belongs to a run with the same selection, preparing
+// it to record the progress of this run.
+func (rp *Reprocess) loadState(stateFile string) error {
+	selection := rp.selection()
+	if prev, err := os.Open(stateFile); err == nil {
+		s := bufio.NewScanner(prev)
+		if s.Scan() && s.Text() == selection {
+			for s.Scan() {
+				rp.done[s.Text()] = struct{}{}
+			}
+		}
+		prev.Close()
+	}
+	rp.resumed = len(rp.done)
+
+	f, err := os.OpenFile(stateFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(rp.done))
+	for key := range rp.done {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	w := bufio.NewWriter(f)
+	fmt.Fprintln(w, selection)
+	for _, key := range keys {
+		fmt.Fprintln(w, key)
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	rp.f = f
+	if rp.resumed > 0 {
+		log.Info().Int("reprocessed", rp.resumed).Msg("resuming forced reprocessing run")
+	}
+	return nil
+}
+
+// selection returns a string representation of the package versions selected
+// to be reprocessed, used to identify the run in the state file.
+func (rp *Reprocess) selection() string {
+	if rp.all {
+		return "all"
+	}
+	entries := make([]string, 0, len(rp.repositories)+len(rp.packages))
+	for name := range rp.repositories {
+		entries = append(entries, "repo:"+name)
+	}
+	for name := range rp.packages {
+		entries = append(entries, "pkg:"+name)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// MatchesRepository checks if any of the package versions of the provided
+// chart repository have been selected to be reprocessed.
+func (rp *Reprocess) MatchesRepository(r *hub.ChartRepository) bool {
+	if rp.all {
+		return true
+	}
+	if _, ok := rp.repositories[r.Name]; ok {
+		return true
+	}
+	for name := range rp.packages {
+		if strings.HasPrefix(name, r.Name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending checks if the package version provided has been selected to be
+// reprocessed and has not been reprocessed yet in this run.
+func (rp *Reprocess) Pending(r *hub.ChartRepository, name, version string) bool {
+	if !rp.all {
+		_, repoSelected := rp.repositories[r.Name]
+		_, pkgSelected := rp.packages[r.Name+"/"+name]
+		if !repoSelected && !pkgSelected {
+			return false
+		}
+	}
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	_, done := rp.done[stateKey(r, name, version)]
+	return !done
+}
+
+// Enqueued records that a job to reprocess a package version has been
+// enqueued.
+func (rp *Reprocess) Enqueued() {
+	rp.mu.Lock()
+	rp.enqueued++
+	rp.mu.Unlock()
+}
+
+// Done records that the package version provided has been reprocessed, so
+// that it's skipped if the run is resumed.
+func (rp *Reprocess) Done(r *hub.ChartRepository, name, version string) {
+	key := stateKey(r, name, version)
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if _, ok := rp.done[key]; ok {
+		return
+	}
+	rp.done[key] = struct{}{}
+	rp.handled++
+	if _, err := fmt.Fprintln(rp.f, key); err != nil {
+		log.Warn().Err(err).Msg("error recording reprocessing progress")
+	}
+	if rp.handled%reprocessProgressInterval == 0 {
+		log.Info().
+			Int("reprocessed", rp.handled).
+			Int("enqueued", rp.enqueued).
+			Int("resumed", rp.resumed).
+			Msg("forced reprocessing progress")
+	}
+}
+
+// Failed records that the package versions of the repository provided could
+// not be loaded to be reprocessed.
+func (rp *Reprocess) Failed(r *hub.ChartRepository) {
+	rp.mu.Lock()
+	rp.failed = append(rp.failed, r.Name)
+	rp.mu.Unlock()
+}
+
+// Finish finishes the run. When all the package versions selected have been
+// reprocessed successfully, the state file is removed. Otherwise it's kept,
+// so that the remaining ones are processed when the run is resumed.
+func (rp *Reprocess) Finish(interrupted bool) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.f.Close()
+	logger := log.Info().
+		Int("reprocessed", rp.handled).
+		Int("enqueued", rp.enqueued).
+		Int("resumed", rp.resumed)
+	if interrupted || len(rp.failed) > 0 || rp.handled < rp.enqueued {
+		logger.
+			Strs("failedRepos", rp.failed).
+			Str("stateFile", rp.f.Name()).
+			Msg("forced reprocessing incomplete, it will be resumed on the next run")
+		return
+	}
+	logger.Msg("forced reprocessing completed")
+	if err := os.Remove(rp.f.Name()); err != nil {
+		log.Warn().Err(err).Msg("error removing reprocessing state file")
+	}
+}
+
+// stateKey returns the key used to identify the package version provided in
+// the state file.
+func stateKey(r *hub.ChartRepository, name, version string) string {
+	return fmt.Sprintf("%s %s@%s", r.ChartRepositoryID, name, version)
+}
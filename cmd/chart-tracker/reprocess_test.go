@@ -0,0 +1,118 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReprocess(t *testing.T) {
+	repo1 := &hub.ChartRepository{ChartRepositoryID: "id1", Name: "repo1"}
+	repo2 := &hub.ChartRepository{ChartRepositoryID: "id2", Name: "repo2"}
+	setup := func(t *testing.T) (*viper.Viper, string, func()) {
+		dir, err := ioutil.TempDir("", "chart-tracker-reprocess")
+		require.NoError(t, err)
+		cfg := viper.New()
+		stateFile := filepath.Join(dir, "state")
+		cfg.Set("tracker.forceReprocess.stateFile", stateFile)
+		return cfg, stateFile, func() { os.RemoveAll(dir) }
+	}
+
+	t.Run("nothing selected to reprocess", func(t *testing.T) {
+		cfg, stateFile, cleanup := setup(t)
+		defer cleanup()
+		rp, err := NewReprocess(cfg)
+		require.NoError(t, err)
+		assert.Nil(t, rp)
+		_, err = os.Stat(stateFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("invalid package selected", func(t *testing.T) {
+		cfg, _, cleanup := setup(t)
+		defer cleanup()
+		cfg.Set("tracker.forceReprocess.packages", []string{"pkg1"})
+		_, err := NewReprocess(cfg)
+		assert.Error(t, err)
+	})
+
+	t.Run("package versions selected", func(t *testing.T) {
+		cfg, _, cleanup := setup(t)
+		defer cleanup()
+		cfg.Set("tracker.forceReprocess.packages", []string{"repo2/pkg1"})
+		rp, err := NewReprocess(cfg)
+		require.NoError(t, err)
+		defer rp.Finish(true)
+
+		assert.False(t, rp.MatchesRepository(repo1))
+		assert.True(t, rp.MatchesRepository(repo2))
+		assert.True(t, rp.Pending(repo2, "pkg1", "1.0.0"))
+		assert.False(t, rp.Pending(repo2, "pkg2", "1.0.0"))
+		rp.Done(repo2, "pkg1", "1.0.0")
+		assert.False(t, rp.Pending(repo2, "pkg1", "1.0.0"))
+	})
+
+	t.Run("interrupted run resumed", func(t *testing.T) {
+		cfg, stateFile, cleanup := setup(t)
+		defer cleanup()
+		cfg.Set("tracker.forceReprocess.repositories", []string{"repo1"})
+
+		// First run, interrupted
+		rp, err := NewReprocess(cfg)
+		require.NoError(t, err)
+		assert.True(t, rp.MatchesRepository(repo1))
+		assert.False(t, rp.MatchesRepository(repo2))
+		rp.Enqueued()
+		rp.Enqueued()
+		rp.Done(repo1, "pkg1", "1.0.0")
+		rp.Finish(true)
+		_, err = os.Stat(stateFile)
+		require.NoError(t, err)
+
+		// Second run, resumed and completed
+		rp, err = NewReprocess(cfg)
+		require.NoError(t, err)
+		assert.False(t, rp.Pending(repo1, "pkg1", "1.0.0"))
+		assert.True(t, rp.Pending(repo1, "pkg1", "2.0.0"))
+		rp.Enqueued()
+		rp.Done(repo1, "pkg1", "2.0.0")
+		rp.Finish(false)
+		_, err = os.Stat(stateFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("run not resumed when selection changes", func(t *testing.T) {
+		cfg, _, cleanup := setup(t)
+		defer cleanup()
+		cfg.Set("tracker.forceReprocess.all", true)
+		rp, err := NewReprocess(cfg)
+		require.NoError(t, err)
+		rp.Done(repo1, "pkg1", "1.0.0")
+		rp.Finish(true)
+
+		cfg.Set("tracker.forceReprocess.all", false)
+		cfg.Set("tracker.forceReprocess.repositories", []string{"repo1"})
+		rp, err = NewReprocess(cfg)
+		require.NoError(t, err)
+		defer rp.Finish(true)
+		assert.True(t, rp.Pending(repo1, "pkg1", "1.0.0"))
+	})
+
+	t.Run("run kept when a repository fails", func(t *testing.T) {
+		cfg, stateFile, cleanup := setup(t)
+		defer cleanup()
+		cfg.Set("tracker.forceReprocess.all", true)
+		rp, err := NewReprocess(cfg)
+		require.NoError(t, err)
+		rp.Failed(repo1)
+		rp.Finish(false)
+		_, err = os.Stat(stateFile)
+		assert.NoError(t, err)
+	})
+}
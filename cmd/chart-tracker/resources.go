@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/pkg"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// crdKind represents the kind of the Kubernetes custom resource
+	// definitions.
+	crdKind = "CustomResourceDefinition"
+)
+
+var (
+	// manifestSeparatorRE is a regexp used to split multi-document yaml files.
+	manifestSeparatorRE = regexp.MustCompile(`(?m)^---\s*$`)
+
+	// templateKindRE is a regexp used to extract the kind of the resources
+	// defined in templates that could not be rendered.
+	templateKindRE = regexp.MustCompile(`(?m)^kind:\s*["']?([A-Za-z][A-Za-z0-9]*)["']?\s*$`)
+
+	// templateImageRE is a regexp used to extract the container images used
+	// in templates that could not be rendered.
+	templateImageRE = regexp.MustCompile(`(?m)^\s*(?:-\s+)?image:\s*["']?([^\s"'{}]+)["']?\s*$`)
+)
+
+// resources represents the Kubernetes resources installed by a chart.
+type resources struct {
+	kinds  []string
+	crds   []*CRD
+	images []string
+}
+
+// CRD represents a custom resource definition shipped by a chart.
+type CRD struct {
+	Name    string `json:"name"`
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+}
+
+// manifest represents the subset of the fields of a Kubernetes manifest
+// needed to extract the resources kinds and custom resource definitions.
+type manifest struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group   string `json:"group"`
+		Version string `json:"version"`
+		Names   struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Versions []struct {
+			Name string `json:"name"`
+		} `json:"versions"`
+	} `json:"spec"`
+}
+
+// getResources returns the kinds of the Kubernetes resources installed by the
+// chart provided, the custom resource definitions it ships and the container
+// images it uses. The chart templates are rendered using the default values.
+// When that's not possible, the templates are inspected statically.
+func getResources(c *chart.Chart) *resources {
+	var manifests []string
+	for _, f := range c.CRDs() {
+		manifests = append(manifests, string(f.Data))
+	}
+	rendered, ok := renderTemplates(c)
+	if ok {
+		manifests = append(manifests, rendered...)
+	}
+
+	r := &resources{}
+	kindsSet := make(map[string]struct{})
+	imagesSet := make(map[string]struct{})
+	for _, m := range manifests {
+		for _, doc := range manifestSeparatorRE.Split(m, -1) {
+			var md manifest
+			if err := yaml.Unmarshal([]byte(doc), &md); err != nil || md.Kind == "" {
+				continue
+			}
+			kindsSet[md.Kind] = struct{}{}
+			if md.Kind == crdKind {
+				r.crds = append(r.crds, getCRDs(&md)...)
+				continue
+			}
+			var obj interface{}
+			if err := yaml.Unmarshal([]byte(doc), &obj); err == nil {
+				collectImages(obj, imagesSet)
+			}
+		}
+	}
+	if !ok {
+		for _, t := range c.Templates {
+			for _, m := range templateKindRE.FindAllSubmatch(t.Data, -1) {
+				kindsSet[string(m[1])] = struct{}{}
+			}
+			for _, m := range templateImageRE.FindAllSubmatch(t.Data, -1) {
+				addImage(string(m[1]), imagesSet)
+			}
+		}
+	}
+
+	r.kinds = sortedKeys(kindsSet)
+	r.images = sortedKeys(imagesSet)
+	return r
+}
+
+// collectImages walks the provided manifest object collecting the container
+// images referenced in it.
+func collectImages(obj interface{}, images map[string]struct{}) {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if image, ok := value.(string); ok && key == "image" {
+				addImage(image, images)
+				continue
+			}
+			collectImages(value, images)
+		}
+	case []interface{}:
+		for _, value := range v {
+			collectImages(value, images)
+		}
+	}
+}
+
+// addImage adds the provided image reference to the images set given once
+// normalized. Invalid references are ignored.
+func addImage(ref string, images map[string]struct{}) {
+	image, err := pkg.NormalizeImageReference(ref, true)
+	if err != nil {
+		return
+	}
+	images[image] = struct{}{}
+}
+
+// sortedKeys returns the keys of the set provided sorted.
+func sortedKeys(set map[string]struct{}) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// renderTemplates renders the templates of the chart provided using its
+// default values, returning the manifests generated.
+func renderTemplates(c *chart.Chart) (manifests []string, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			manifests, ok = nil, false
+		}
+	}()
+
+	values, err := chartutil.ToRenderValues(c, c.Values, chartutil.ReleaseOptions{
+		Name:      "release-name",
+		Namespace: "default",
+		Revision:  1,
+		IsInstall: true,
+	}, nil)
+	if err != nil {
+		return nil, false
+	}
+	files, err := engine.Render(c, values)
+	if err != nil {
+		return nil, false
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.HasPrefix(path.Base(name), "_") || !isManifest(name) {
+			continue
+		}
+		if content := bytes.TrimSpace([]byte(files[name])); len(content) > 0 {
+			manifests = append(manifests, string(content))
+		}
+	}
+	return manifests, true
+}
+
+// getCRDs returns the custom resources defined by the provided custom
+// resource definition manifest, one for each version available.
+func getCRDs(md *manifest) []*CRD {
+	versions := make([]string, 0, len(md.Spec.Versions))
+	for _, v := range md.Spec.Versions {
+		versions = append(versions, v.Name)
+	}
+	if len(versions) == 0 && md.Spec.Version != "" {
+		versions = append(versions, md.Spec.Version)
+	}
+	crds := make([]*CRD, 0, len(versions))
+	for _, version := range versions {
+		crds = append(crds, &CRD{
+			Name:    md.Metadata.Name,
+			Group:   md.Spec.Group,
+			Version: version,
+			Kind:    md.Spec.Names.Kind,
+		})
+	}
+	return crds
+}
+
+// isManifest checks if the file provided is a Kubernetes manifest.
+func isManifest(name string) bool {
+	switch path.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
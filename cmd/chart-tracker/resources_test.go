@@ -0,0 +1,103 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestGetResources(t *testing.T) {
+	t.Run("templates rendered and crds directory parsed", func(t *testing.T) {
+		c, err := loader.Load("testdata/pkg3-1.0.0.tgz")
+		require.NoError(t, err)
+
+		r := getResources(c)
+		assert.Equal(t, []string{"CustomResourceDefinition", "Deployment", "Service"}, r.kinds)
+		assert.Equal(t, []*CRD{
+			{
+				Name:    "crontabs.stable.example.com",
+				Group:   "stable.example.com",
+				Version: "v1",
+				Kind:    "CronTab",
+			},
+		}, r.crds)
+		assert.Equal(t, []string{"docker.io/library/nginx:1.17"}, r.images)
+	})
+
+	t.Run("kinds and images extracted from templates that cannot be rendered", func(t *testing.T) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "pkg1", Version: "1.0.0"},
+			Templates: []*chart.File{
+				{
+					Name: "templates/deployment.yaml",
+					Data: []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ required "name required" .Values.name }}
+spec:
+  template:
+    spec:
+      containers:
+        - name: etcd
+          image: "quay.io/coreos/etcd:v3.4.7"
+        - name: app
+          image: {{ .Values.image }}
+`),
+				},
+				{
+					Name: "templates/crd.yaml",
+					Data: []byte("{{- if .Values.crd }}\napiVersion: apiextensions.k8s.io/v1beta1\nkind: \"CustomResourceDefinition\"\n{{- end }}\n"),
+				},
+			},
+		}
+
+		r := getResources(c)
+		assert.Equal(t, []string{"CustomResourceDefinition", "Deployment"}, r.kinds)
+		assert.Empty(t, r.crds)
+		assert.Equal(t, []string{"quay.io/coreos/etcd:v3.4.7"}, r.images)
+	})
+
+	t.Run("crd defined in templates using a single version", func(t *testing.T) {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{Name: "pkg1", Version: "1.0.0"},
+			Templates: []*chart.File{
+				{
+					Name: "templates/crd.yaml",
+					Data: []byte(`
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  version: v1beta1
+  names:
+    kind: CronTab
+---
+apiVersion: v1
+kind: ConfigMap
+`),
+				},
+				{
+					Name: "templates/NOTES.txt",
+					Data: []byte("kind: Notes"),
+				},
+			},
+		}
+
+		r := getResources(c)
+		assert.Equal(t, []string{"ConfigMap", "CustomResourceDefinition"}, r.kinds)
+		assert.Equal(t, []*CRD{
+			{
+				Name:    "crontabs.stable.example.com",
+				Group:   "stable.example.com",
+				Version: "v1beta1",
+				Kind:    "CronTab",
+			},
+		}, r.crds)
+		assert.Empty(t, r.images)
+	})
+}
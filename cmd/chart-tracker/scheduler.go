@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultTrackingInterval represents the interval used to track chart
+	// repositories that don't have a specific one set.
+	defaultTrackingInterval = 30 * time.Minute
+
+	// defaultBackoffBase represents the base delay used when retrying the
+	// tracking of a chart repository that failed.
+	defaultBackoffBase = 1 * time.Minute
+
+	// defaultBackoffMax represents the maximum delay used when retrying the
+	// tracking of a chart repository that failed.
+	defaultBackoffMax = 6 * time.Hour
+
+	// defaultRefreshInterval represents how often the scheduler reloads the
+	// chart repositories available and checks which ones are due.
+	defaultRefreshInterval = 1 * time.Minute
+
+	// defaultSyncCheckInterval represents how often the scheduler checks if
+	// there are pending sync requests registered from the API.
+	defaultSyncCheckInterval = 10 * time.Second
+)
+
+// scheduledRepo represents a chart repository handled by the scheduler.
+type scheduledRepo struct {
+	repo         *hub.ChartRepository
+	nextRun      time.Time
+	failures     int
+	running      bool
+	syncRequests []string
+}
+
+// Scheduler is in charge of tracking the chart repositories periodically when
+// the chart tracker runs in daemon mode. Each repository is tracked on its own
+// interval, and those failing are retried using a jittered exponential
+// backoff. Repositories with pending sync requests are tracked right away.
+type Scheduler struct {
+	ctx               context.Context
+	d                 *Dispatcher
+	getRepos          func() ([]*hub.ChartRepository, error)
+	defaultInterval   time.Duration
+	backoffBase       time.Duration
+	backoffMax        time.Duration
+	refreshInterval   time.Duration
+	syncCheckInterval time.Duration
+
+	mu    sync.Mutex
+	repos map[string]*scheduledRepo // K: chart repository id
+}
+
+// NewScheduler creates a new scheduler instance.
+func NewScheduler(
+	ctx context.Context,
+	cfg *viper.Viper,
+	d *Dispatcher,
+	getRepos func() ([]*hub.ChartRepository, error),
+) *Scheduler {
+	s := &Scheduler{
+		ctx:               ctx,
+		d:                 d,
+		getRepos:          getRepos,
+		defaultInterval:   defaultTrackingInterval,
+		backoffBase:       defaultBackoffBase,
+		backoffMax:        defaultBackoffMax,
+		refreshInterval:   defaultRefreshInterval,
+		syncCheckInterval: defaultSyncCheckInterval,
+		repos:             make(map[string]*scheduledRepo),
+	}
+	if cfg.IsSet("tracker.daemon.defaultInterval") {
+		s.defaultInterval = cfg.GetDuration("tracker.daemon.defaultInterval")
+	}
+	if cfg.IsSet("tracker.daemon.backoffBase") {
+		s.backoffBase = cfg.GetDuration("tracker.daemon.backoffBase")
+	}
+	if cfg.IsSet("tracker.daemon.backoffMax") {
+		s.backoffMax = cfg.GetDuration("tracker.daemon.backoffMax")
+	}
+	if cfg.IsSet("tracker.daemon.refreshInterval") {
+		s.refreshInterval = cfg.GetDuration("tracker.daemon.refreshInterval")
+	}
+	if cfg.IsSet("tracker.daemon.syncCheckInterval") {
+		s.syncCheckInterval = cfg.GetDuration("tracker.daemon.syncCheckInterval")
+	}
+	return s
+}
+
+// Run instructs the scheduler to start tracking the chart repositories as they
+// become due. It will keep running until the context is done. At that point
+// no more repositories will be scheduled, and it will return once the ones in
+// progress have been completely processed.
+func (s *Scheduler) Run(wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer close(s.d.Queue)
+
+	var wgRepos sync.WaitGroup
+	launch := func(repos []*scheduledRepo) {
+		for _, sr := range repos {
+			wgRepos.Add(1)
+			go s.track(&wgRepos, sr)
+		}
+	}
+	refreshTicker := time.NewTicker(s.refreshInterval)
+	defer refreshTicker.Stop()
+	syncCheckTicker := time.NewTicker(s.syncCheckInterval)
+	defer syncCheckTicker.Stop()
+	refresh := true
+	for {
+		if refresh {
+			if err := s.refresh(); err != nil {
+				log.Error().Err(err).Msg("error refreshing chart repositories")
+			}
+			launch(s.due(time.Now()))
+		}
+		launch(s.requested())
+		select {
+		case <-refreshTicker.C:
+			refresh = true
+		case <-syncCheckTicker.C:
+			refresh = false
+		case <-s.ctx.Done():
+			wgRepos.Wait()
+			return
+		}
+	}
+}
+
+// refresh reloads the chart repositories to track, adding the new ones and
+// removing those no longer available.
+func (s *Scheduler) refresh() error {
+	repos, err := s.getRepos()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	available := make(map[string]struct{}, len(repos))
+	for _, r := range repos {
+		available[r.ChartRepositoryID] = struct{}{}
+		if sr, ok := s.repos[r.ChartRepositoryID]; ok {
+			sr.repo = r
+		} else {
+			s.repos[r.ChartRepositoryID] = &scheduledRepo{repo: r}
+		}
+	}
+	for chartRepositoryID, sr := range s.repos {
+		if _, ok := available[chartRepositoryID]; !ok && !sr.running {
+			delete(s.repos, chartRepositoryID)
+		}
+	}
+	return nil
+}
+
+// due returns the chart repositories that should be tracked at the time
+// provided, marking them as running.
+func (s *Scheduler) due(now time.Time) []*scheduledRepo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var repos []*scheduledRepo
+	for _, sr := range s.repos {
+		if !sr.running && !now.Before(sr.nextRun) {
+			sr.running = true
+			repos = append(repos, sr)
+		}
+	}
+	return repos
+}
+
+// requested claims the pending sync requests of the chart repositories that
+// are not being tracked at the moment, returning those repositories marked as
+// running. Only the goroutine running the scheduler marks repositories as
+// running, so the ones selected remain idle while the requests are claimed.
+func (s *Scheduler) requested() []*scheduledRepo {
+	s.mu.Lock()
+	reposIDs := make([]string, 0, len(s.repos))
+	for chartRepositoryID, sr := range s.repos {
+		if !sr.running {
+			reposIDs = append(reposIDs, chartRepositoryID)
+		}
+	}
+	s.mu.Unlock()
+	if len(reposIDs) == 0 {
+		return nil
+	}
+
+	syncRequests, err := s.d.rm.ClaimSyncRequests(s.ctx, reposIDs)
+	if err != nil {
+		log.Error().Err(err).Msg("error claiming sync requests")
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var repos []*scheduledRepo
+	for _, req := range syncRequests {
+		sr, ok := s.repos[req.ChartRepositoryID]
+		if !ok {
+			continue
+		}
+		if !sr.running {
+			sr.running = true
+			repos = append(repos, sr)
+		}
+		sr.syncRequests = append(sr.syncRequests, req.SyncRequestID)
+	}
+	return repos
+}
+
+// track tracks the provided chart repository, scheduling its next run based
+// on the result.
+func (s *Scheduler) track(wg *sync.WaitGroup, sr *scheduledRepo) {
+	defer wg.Done()
+
+	s.mu.Lock()
+	r := sr.repo
+	syncRequests := sr.syncRequests
+	sr.syncRequests = nil
+	s.mu.Unlock()
+	err := s.d.SyncRepository(r)
+	if len(syncRequests) > 0 {
+		if err := s.d.rm.CompleteSyncRequests(context.Background(), syncRequests); err != nil {
+			log.Error().Err(err).Str("repo", r.Name).Msg("error completing sync requests")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sr.running = false
+	if err != nil {
+		sr.failures++
+		delay := s.backoff(sr.failures)
+		sr.nextRun = time.Now().Add(delay)
+		log.Warn().Err(err).Str("repo", r.Name).Int("failures", sr.failures).
+			Str("retryIn", delay.String()).Msg("chart repository tracking failed")
+		return
+	}
+	sr.failures = 0
+	sr.nextRun = time.Now().Add(s.interval(r))
+}
+
+// interval returns the tracking interval of the provided chart repository.
+func (s *Scheduler) interval(r *hub.ChartRepository) time.Duration {
+	if r.TrackingInterval > 0 {
+		return time.Duration(r.TrackingInterval) * time.Second
+	}
+	return s.defaultInterval
+}
+
+// backoff returns the delay to wait before tracking again a chart repository
+// that failed the number of consecutive times provided. The delay grows
+// exponentially up to the maximum configured, and half of it is jittered to
+// avoid retrying many repositories at the same time.
+func (s *Scheduler) backoff(failures int) time.Duration {
+	delay := float64(s.backoffBase) * math.Pow(2, float64(failures-1))
+	if delay > float64(s.backoffMax) {
+		delay = float64(s.backoffMax)
+	}
+	return time.Duration(delay/2 + rand.Float64()*delay/2) // #nosec
+}
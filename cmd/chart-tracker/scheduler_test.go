@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSchedulerRun(t *testing.T) {
+	t.Run("error getting chart repositories", func(t *testing.T) {
+		// Setup scheduler
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		dw := newDispatcherWrapper(ctx)
+		s := NewScheduler(ctx, viper.New(), dw.d, func() ([]*hub.ChartRepository, error) {
+			return nil, errFake
+		})
+
+		// Run scheduler and check expectations
+		s.Run(dw.wg)
+		dw.assertExpectations(t, nil)
+		assert.Empty(t, s.repos)
+	})
+
+	t.Run("due repositories tracked until context is done", func(t *testing.T) {
+		// Setup scheduler and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1"}
+		ctx, cancel := context.WithCancel(context.Background())
+		dw := newDispatcherWrapper(ctx)
+		dw.ec.On("Init", r.ChartRepositoryID).Return()
+		dw.il.On("LoadIndexIfChanged", r).Return(nil, nil, nil)
+		dw.ec.On("FlushRepository", r.ChartRepositoryID).Run(func(args mock.Arguments) {
+			cancel()
+		}).Return()
+		s := NewScheduler(ctx, viper.New(), dw.d, func() ([]*hub.ChartRepository, error) {
+			return []*hub.ChartRepository{r}, nil
+		})
+
+		// Run scheduler and check expectations
+		s.Run(dw.wg)
+		dw.assertExpectations(t, nil)
+		assert.Equal(t, 0, s.repos["repo1"].failures)
+		assert.False(t, s.repos["repo1"].running)
+		assert.True(t, s.repos["repo1"].nextRun.After(time.Now().Add(29*time.Minute)))
+	})
+}
+
+func TestSchedulerRefresh(t *testing.T) {
+	repo1 := &hub.ChartRepository{ChartRepositoryID: "repo1"}
+	repo2 := &hub.ChartRepository{ChartRepositoryID: "repo2"}
+	repo3 := &hub.ChartRepository{ChartRepositoryID: "repo3"}
+	repo1Updated := &hub.ChartRepository{ChartRepositoryID: "repo1", TrackingInterval: 60}
+	s := &Scheduler{
+		repos: map[string]*scheduledRepo{
+			"repo1": {repo: repo1},
+			"repo2": {repo: repo2},
+			"repo3": {repo: repo3, running: true},
+		},
+	}
+	s.getRepos = func() ([]*hub.ChartRepository, error) {
+		return []*hub.ChartRepository{repo1Updated}, nil
+	}
+
+	err := s.refresh()
+	assert.NoError(t, err)
+	assert.Len(t, s.repos, 2)
+	assert.Equal(t, repo1Updated, s.repos["repo1"].repo)
+	assert.Contains(t, s.repos, "repo3")
+}
+
+func TestSchedulerRequested(t *testing.T) {
+	t.Run("error claiming sync requests", func(t *testing.T) {
+		// Setup scheduler and expectations
+		dw := newDispatcherWrapper(context.Background())
+		dw.rm.On("ClaimSyncRequests", mock.Anything, []string{"repo1"}).Return(nil, errFake)
+		s := NewScheduler(context.Background(), viper.New(), dw.d, nil)
+		s.repos["repo1"] = &scheduledRepo{repo: &hub.ChartRepository{ChartRepositoryID: "repo1"}}
+
+		// Check requested repositories and expectations
+		assert.Empty(t, s.requested())
+		dw.wg.Done()
+		close(dw.d.Queue)
+		dw.assertExpectations(t, nil)
+	})
+
+	t.Run("repositories with sync requests returned", func(t *testing.T) {
+		// Setup scheduler and expectations
+		dw := newDispatcherWrapper(context.Background())
+		dw.rm.On("ClaimSyncRequests", mock.Anything, []string{"repo1"}).Return([]*hub.ChartRepositorySyncRequest{
+			{SyncRequestID: "sr1", ChartRepositoryID: "repo1"},
+			{SyncRequestID: "sr2", ChartRepositoryID: "repo1"},
+		}, nil)
+		s := NewScheduler(context.Background(), viper.New(), dw.d, nil)
+		s.repos["repo1"] = &scheduledRepo{
+			repo:    &hub.ChartRepository{ChartRepositoryID: "repo1"},
+			nextRun: time.Now().Add(time.Hour),
+		}
+		s.repos["repo2"] = &scheduledRepo{
+			repo:    &hub.ChartRepository{ChartRepositoryID: "repo2"},
+			running: true,
+		}
+
+		// Check requested repositories and expectations
+		repos := s.requested()
+		assert.Equal(t, []*scheduledRepo{s.repos["repo1"]}, repos)
+		assert.True(t, s.repos["repo1"].running)
+		assert.Equal(t, []string{"sr1", "sr2"}, s.repos["repo1"].syncRequests)
+		dw.wg.Done()
+		close(dw.d.Queue)
+		dw.assertExpectations(t, nil)
+	})
+}
+
+func TestSchedulerTrack(t *testing.T) {
+	t.Run("repository failed, backoff applied", func(t *testing.T) {
+		// Setup scheduler and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1"}
+		dw := newDispatcherWrapper(context.Background())
+		dw.ec.On("Init", r.ChartRepositoryID).Return()
+		dw.il.On("LoadIndexIfChanged", r).Return(nil, nil, errFake)
+		dw.ec.On("Append", r.ChartRepositoryID, mock.Anything).Return()
+		dw.ec.On("FlushRepository", r.ChartRepositoryID).Return()
+		s := NewScheduler(context.Background(), viper.New(), dw.d, nil)
+		sr := &scheduledRepo{repo: r, failures: 2, running: true}
+
+		// Track repository and check expectations
+		var wg sync.WaitGroup
+		wg.Add(1)
+		start := time.Now()
+		s.track(&wg, sr)
+		assert.Equal(t, 3, sr.failures)
+		assert.False(t, sr.running)
+		assert.True(t, sr.nextRun.After(start.Add(2*time.Minute)))
+		assert.True(t, sr.nextRun.Before(time.Now().Add(4*time.Minute)))
+		dw.wg.Done()
+		close(dw.d.Queue)
+		dw.assertExpectations(t, nil)
+	})
+
+	t.Run("sync requests completed once repository has been tracked", func(t *testing.T) {
+		// Setup scheduler and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1"}
+		dw := newDispatcherWrapper(context.Background())
+		dw.ec.On("Init", r.ChartRepositoryID).Return()
+		dw.il.On("LoadIndexIfChanged", r).Return(nil, nil, nil)
+		dw.ec.On("FlushRepository", r.ChartRepositoryID).Return()
+		dw.rm.On("CompleteSyncRequests", mock.Anything, []string{"sr1"}).Return(nil)
+		s := NewScheduler(context.Background(), viper.New(), dw.d, nil)
+		sr := &scheduledRepo{repo: r, running: true, syncRequests: []string{"sr1"}}
+
+		// Track repository and check expectations
+		var wg sync.WaitGroup
+		wg.Add(1)
+		s.track(&wg, sr)
+		assert.Empty(t, sr.syncRequests)
+		assert.False(t, sr.running)
+		dw.wg.Done()
+		close(dw.d.Queue)
+		dw.assertExpectations(t, nil)
+	})
+
+	t.Run("repository tracked successfully, interval applied", func(t *testing.T) {
+		// Setup scheduler and expectations
+		r := &hub.ChartRepository{ChartRepositoryID: "repo1", TrackingInterval: 3600}
+		dw := newDispatcherWrapper(context.Background())
+		dw.ec.On("Init", r.ChartRepositoryID).Return()
+		dw.il.On("LoadIndexIfChanged", r).Return(nil, nil, nil)
+		dw.ec.On("FlushRepository", r.ChartRepositoryID).Return()
+		s := NewScheduler(context.Background(), viper.New(), dw.d, nil)
+		sr := &scheduledRepo{repo: r, failures: 2, running: true}
+
+		// Track repository and check expectations
+		var wg sync.WaitGroup
+		wg.Add(1)
+		start := time.Now()
+		s.track(&wg, sr)
+		assert.Equal(t, 0, sr.failures)
+		assert.False(t, sr.running)
+		assert.False(t, sr.nextRun.Before(start.Add(time.Hour)))
+		dw.wg.Done()
+		close(dw.d.Queue)
+		dw.assertExpectations(t, nil)
+	})
+}
+
+func TestSchedulerBackoff(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("tracker.daemon.backoffBase", "1m")
+	cfg.Set("tracker.daemon.backoffMax", "10m")
+	s := NewScheduler(context.Background(), cfg, nil, nil)
+
+	testCases := []struct {
+		failures int
+		min      time.Duration
+		max      time.Duration
+	}{
+		{1, 30 * time.Second, 1 * time.Minute},
+		{2, 1 * time.Minute, 2 * time.Minute},
+		{4, 4 * time.Minute, 8 * time.Minute},
+		{10, 5 * time.Minute, 10 * time.Minute},
+	}
+	for _, tc := range testCases {
+		delay := s.backoff(tc.failures)
+		assert.True(t, delay >= tc.min && delay <= tc.max, "failures: %d, delay: %s", tc.failures, delay)
+	}
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"image"
@@ -12,7 +15,10 @@ import (
 	"runtime/debug"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/Masterminds/semver/v3"
+	"github.com/artifacthub/hub/internal/chartrepo"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/img"
 	"github.com/rs/zerolog"
@@ -22,9 +28,24 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 )
 
+// logoCacheMaxAge represents the maximum amount of time a logo downloaded is
+// served from the cache, so that logos updated under the same url are picked
+// up eventually.
+const logoCacheMaxAge = 24 * time.Hour
+
+// errInvalidChartArchive indicates that the chart archive downloaded could not
+// be loaded.
+var errInvalidChartArchive = errors.New("invalid chart archive")
+
 // HTTPGetter defines the methods an HTTPGetter implementation must provide.
 type HTTPGetter interface {
 	Get(url string) (*http.Response, error)
+	GetFromRepository(r *hub.ChartRepository, url string) (*http.Response, error)
+}
+
+// OCIPuller defines the methods an OCIPuller implementation must provide.
+type OCIPuller interface {
+	PullChart(r *hub.ChartRepository, ref string) ([]byte, error)
 }
 
 // Worker is in charge of handling chart releases register and unregister jobs
@@ -36,6 +57,8 @@ type Worker struct {
 	is     img.Store
 	ec     ErrorsCollector
 	hg     HTTPGetter
+	op     OCIPuller
+	c      Cache
 	logger zerolog.Logger
 }
 
@@ -47,6 +70,8 @@ func NewWorker(
 	is img.Store,
 	ec ErrorsCollector,
 	httpClient HTTPGetter,
+	ociPuller OCIPuller,
+	c Cache,
 ) *Worker {
 	return &Worker{
 		ctx:    ctx,
@@ -55,6 +80,8 @@ func NewWorker(
 		is:     is,
 		ec:     ec,
 		hg:     httpClient,
+		op:     ociPuller,
+		c:      c,
 		logger: log.With().Int("worker", id).Logger(),
 	}
 }
@@ -69,17 +96,21 @@ func (w *Worker) Run(wg *sync.WaitGroup, queue chan *Job) {
 			if !ok {
 				return
 			}
-			md := j.ChartVersion.Metadata
+			name, version := j.nameVersion()
 			w.logger.Debug().
 				Str("repo", j.Repo.Name).
-				Str("chart", md.Name).
-				Str("version", md.Version).
+				Str("chart", name).
+				Str("version", version).
 				Int("jobKind", int(j.Kind)).
 				Msg("handling job")
 			var err error
 			switch j.Kind {
 			case Register:
-				err = w.handleRegisterJob(j)
+				if j.Package != nil {
+					err = w.handleRegisterPackageJob(j)
+				} else {
+					err = w.handleRegisterJob(j)
+				}
 			case Unregister:
 				err = w.handleUnregisterJob(j)
 			}
@@ -87,11 +118,12 @@ func (w *Worker) Run(wg *sync.WaitGroup, queue chan *Job) {
 				w.logger.Error().
 					Err(err).
 					Str("repo", j.Repo.Name).
-					Str("chart", md.Name).
-					Str("version", md.Version).
+					Str("chart", name).
+					Str("version", version).
 					Int("jobKind", int(j.Kind)).
 					Msg("error handling job")
 			}
+			j.done()
 		case <-w.ctx.Done():
 			return
 		}
@@ -99,7 +131,8 @@ func (w *Worker) Run(wg *sync.WaitGroup, queue chan *Job) {
 }
 
 // getImage gets the image located at the url provided. If it's a data url the
-// image is extracted from it. Otherwise it's downloaded using the url.
+// image is extracted from it. Otherwise it's downloaded using the url, unless
+// it's available in the cache and has not expired yet.
 func (w *Worker) getImage(u string) ([]byte, error) {
 	// Image in data url
 	if strings.HasPrefix(u, "data:") {
@@ -110,16 +143,31 @@ func (w *Worker) getImage(u string) ([]byte, error) {
 		return dataURL.Data, nil
 	}
 
+	// Get image from cache when available
+	cacheKey := fmt.Sprintf("logo-%x", sha256.Sum256([]byte(u)))
+	if w.c != nil {
+		if data, ok := w.c.GetFresh(cacheKey, logoCacheMaxAge); ok {
+			return data, nil
+		}
+	}
+
 	// Download image using url provided
 	resp, err := w.hg.Get(u)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusOK {
-		return ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
 	}
-	return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	if w.c != nil {
+		w.c.Set(cacheKey, data)
+	}
+	return data, nil
 }
 
 // handleRegisterJob handles the provided chart release registration job. This
@@ -143,7 +191,14 @@ func (w *Worker) handleRegisterJob(j *Job) error {
 	if _, err := url.ParseRequestURI(u); err != nil {
 		tmp, err := url.Parse(j.Repo.URL)
 		if err != nil {
-			w.ec.Append(j.Repo.ChartRepositoryID, fmt.Errorf("invalid chart url: %s", u))
+			w.ec.Append(j.Repo.ChartRepositoryID, &hub.TrackingError{
+				Code:     hub.InvalidChartURL,
+				Severity: hub.SeverityError,
+				Chart:    j.ChartVersion.Metadata.Name,
+				Version:  j.ChartVersion.Metadata.Version,
+				URL:      u,
+				Message:  fmt.Sprintf("invalid chart url: %s", u),
+			})
 			w.logger.Error().Str("url", u).Msg("invalid url")
 			return err
 		}
@@ -152,9 +207,40 @@ func (w *Worker) handleRegisterJob(j *Job) error {
 	}
 
 	// Load chart from remote archive
-	chart, err := w.loadChart(u)
+	chart, archive, err := w.loadChart(j.Repo, u, j.ChartVersion.Digest)
 	if err != nil {
-		w.ec.Append(j.Repo.ChartRepositoryID, fmt.Errorf("error loading chart %s: %w", u, err))
+		var e *chartrepo.DigestMismatchError
+		if errors.As(err, &e) {
+			w.ec.Append(j.Repo.ChartRepositoryID, &hub.TrackingError{
+				Code:     hub.DigestMismatch,
+				Severity: hub.SeverityError,
+				Chart:    j.ChartVersion.Metadata.Name,
+				Version:  j.ChartVersion.Metadata.Version,
+				URL:      u,
+				Message:  fmt.Sprintf("chart archive %s %s, not registered", u, err),
+			})
+			w.logger.Warn().
+				Str("repo", j.Repo.Name).
+				Str("chart", j.ChartVersion.Metadata.Name).
+				Str("version", j.ChartVersion.Metadata.Version).
+				Str("url", u).
+				Str("expectedDigest", e.Expected).
+				Str("actualDigest", e.Actual).
+				Msg("chart archive digest mismatch")
+			return nil
+		}
+		code := hub.ChartDownloadFailed
+		if errors.Is(err, errInvalidChartArchive) {
+			code = hub.InvalidChart
+		}
+		w.ec.Append(j.Repo.ChartRepositoryID, &hub.TrackingError{
+			Code:     code,
+			Severity: hub.SeverityError,
+			Chart:    j.ChartVersion.Metadata.Name,
+			Version:  j.ChartVersion.Metadata.Version,
+			URL:      u,
+			Message:  fmt.Sprintf("error loading chart %s: %s", u, err),
+		})
 		w.logger.Warn().
 			Str("repo", j.Repo.Name).
 			Str("chart", j.ChartVersion.Metadata.Name).
@@ -167,63 +253,179 @@ func (w *Worker) handleRegisterJob(j *Job) error {
 
 	// Store chart logo when available if requested
 	var logoURL, logoImageID string
-	if j.GetLogo {
-		if md.Icon != "" {
-			logoURL = md.Icon
-			data, err := w.getImage(md.Icon)
-			if err != nil {
-				w.ec.Append(j.Repo.ChartRepositoryID, fmt.Errorf("error getting logo image %s: %w", md.Icon, err))
-				w.logger.Debug().Err(err).Str("url", md.Icon).Msg("get image failed")
-			} else {
-				logoImageID, err = w.is.SaveImage(w.ctx, data)
-				if err != nil && !errors.Is(err, image.ErrFormat) {
-					w.logger.Warn().Err(err).Str("url", md.Icon).Msg("save image failed")
-				}
-			}
-		}
+	if j.GetLogo && md.Icon != "" {
+		logoURL = md.Icon
+		logoImageID = w.saveLogo(j, md.Name, md.Version, md.Icon)
+	}
+
+	// Verify chart provenance file when available
+	var signatureStatus hub.SignatureStatus
+	var signatureKeyFingerprint string
+	if !chartrepo.IsOCIReference(u) {
+		signatureStatus, signatureKeyFingerprint = w.verifyProvenance(j.Repo, u, archive)
 	}
 
 	// Prepare hub package to be registered
 	p := &hub.Package{
-		Kind:            hub.Chart,
-		Name:            md.Name,
-		LogoURL:         logoURL,
-		LogoImageID:     logoImageID,
-		Description:     md.Description,
-		Keywords:        md.Keywords,
-		HomeURL:         md.Home,
-		Version:         md.Version,
-		AppVersion:      md.AppVersion,
-		Digest:          j.ChartVersion.Digest,
-		Deprecated:      md.Deprecated,
-		ChartRepository: j.Repo,
+		Kind:                    hub.Chart,
+		Name:                    md.Name,
+		LogoURL:                 logoURL,
+		LogoImageID:             logoImageID,
+		Description:             md.Description,
+		Keywords:                md.Keywords,
+		HomeURL:                 md.Home,
+		Version:                 md.Version,
+		AppVersion:              md.AppVersion,
+		Digest:                  j.ChartVersion.Digest,
+		Deprecated:              md.Deprecated,
+		Annotations:             md.Annotations,
+		KubeVersion:             md.KubeVersion,
+		ChartType:               hub.ApplicationChart,
+		Sources:                 md.Sources,
+		APIVersion:              md.APIVersion,
+		SignatureStatus:         signatureStatus,
+		SignatureKeyFingerprint: signatureKeyFingerprint,
+		ChartRepository:         packageChartRepository(j.Repo),
+	}
+	if md.Type != "" {
+		p.ChartType = hub.ChartType(md.Type)
 	}
 	readme := getFile(chart, "README.md")
 	if readme != nil {
 		p.Readme = string(readme.Data)
 	}
+	if values := getRawFile(chart, "values.yaml"); values != nil {
+		p.DefaultValues = string(values.Data)
+	}
+	if len(chart.Schema) > 0 {
+		if json.Valid(chart.Schema) {
+			p.ValuesSchema = chart.Schema
+		} else {
+			w.logger.Debug().Str("chart", md.Name).Str("version", md.Version).Msg("invalid values schema")
+		}
+	}
+	for _, template := range chart.Templates {
+		p.Templates = append(p.Templates, template.Name)
+	}
+	resources := getResources(chart)
+	if len(resources.kinds) > 0 || len(resources.crds) > 0 {
+		p.Data = map[string]interface{}{
+			"kinds": resources.kinds,
+			"crds":  resources.crds,
+		}
+	}
+	p.Images = resources.images
+	if err := enrichPackageFromAnnotations(p, md.Annotations); err != nil {
+		w.ec.Append(j.Repo.ChartRepositoryID, &hub.TrackingError{
+			Code:     hub.InvalidAnnotations,
+			Severity: hub.SeverityWarning,
+			Chart:    md.Name,
+			Version:  md.Version,
+			URL:      u,
+			Message:  err.Error(),
+		})
+	}
+	if len(p.Changes) == 0 {
+		if changelog := getFile(chart, "CHANGELOG.md"); changelog != nil {
+			p.Changes = parseChangelog(changelog.Data, md.Version)
+		}
+	}
+	p.ContentFingerprint = getContentFingerprint(chart)
+	p.UpstreamSource = getUpstreamSource(md)
+	lintReport, err := lintChart(md.Name, archive)
+	if err != nil {
+		w.logger.Debug().Err(err).Str("chart", md.Name).Str("version", md.Version).Msg("chart lint failed")
+	}
+	p.Lint = lintReport
 	var maintainers []*hub.Maintainer
 	for _, entry := range md.Maintainers {
-		if entry.Email != "" {
+		if entry.Name != "" || entry.Email != "" {
 			maintainers = append(maintainers, &hub.Maintainer{
 				Name:  entry.Name,
 				Email: entry.Email,
+				URL:   entry.URL,
 			})
 		}
 	}
 	if len(maintainers) > 0 {
 		p.Maintainers = maintainers
 	}
+	for _, dependency := range md.Dependencies {
+		p.Dependencies = append(p.Dependencies, &hub.Dependency{
+			Name:       dependency.Name,
+			Version:    dependency.Version,
+			Repository: dependency.Repository,
+		})
+	}
 
 	// Register package
-	err = w.pm.Register(w.ctx, p)
+	return w.registerPackage(j, p)
+}
+
+// handleRegisterPackageJob handles the provided package registration job,
+// used for repositories of kinds other than Helm charts. The package has
+// already been loaded by the dispatcher, so only its logo must be fetched
+// before registering it.
+func (w *Worker) handleRegisterPackageJob(j *Job) error {
+	p := *j.Package
+	if j.GetLogo && p.LogoURL != "" {
+		p.LogoImageID = w.saveLogo(j, p.Name, p.Version, p.LogoURL)
+	}
+	p.ChartRepository = packageChartRepository(j.Repo)
+	return w.registerPackage(j, &p)
+}
+
+// saveLogo gets the logo image located at the url provided and stores it in
+// the image store, returning the id of the image saved. Errors getting the
+// image are collected as tracking errors.
+func (w *Worker) saveLogo(j *Job, name, version, u string) string {
+	data, err := w.getImage(u)
 	if err != nil {
-		w.ec.Append(
-			j.Repo.ChartRepositoryID,
-			fmt.Errorf("error registering package %s version %s: %w", p.Name, p.Version, err),
-		)
+		w.ec.Append(j.Repo.ChartRepositoryID, &hub.TrackingError{
+			Code:     hub.LogoFailed,
+			Severity: hub.SeverityWarning,
+			Chart:    name,
+			Version:  version,
+			URL:      u,
+			Message:  fmt.Sprintf("error getting logo image %s: %s", u, err),
+		})
+		w.logger.Debug().Err(err).Str("url", u).Msg("get image failed")
+		return ""
+	}
+	logoImageID, err := w.is.SaveImage(w.ctx, data)
+	if err != nil && !errors.Is(err, image.ErrFormat) {
+		w.logger.Warn().Err(err).Str("url", u).Msg("save image failed")
 	}
-	return err
+	return logoImageID
+}
+
+// registerPackage registers the provided package, collecting the result.
+// Packages whose version is not a valid semantic version are not registered.
+func (w *Worker) registerPackage(j *Job, p *hub.Package) error {
+	if _, err := semver.StrictNewVersion(p.Version); err != nil {
+		w.ec.Append(j.Repo.ChartRepositoryID, &hub.TrackingError{
+			Code:     hub.InvalidSemver,
+			Severity: hub.SeverityError,
+			Chart:    p.Name,
+			Version:  p.Version,
+			Message:  fmt.Sprintf("invalid version %s of package %s (semantic version expected)", p.Version, p.Name),
+		})
+		return err
+	}
+	err := w.pm.Register(w.ctx, p)
+	if err != nil {
+		w.ec.Append(j.Repo.ChartRepositoryID, &hub.TrackingError{
+			Code:     hub.RegisterFailed,
+			Severity: hub.SeverityError,
+			Chart:    p.Name,
+			Version:  p.Version,
+			Message:  fmt.Sprintf("error registering package %s version %s: %s", p.Name, p.Version, err),
+		})
+		return err
+	}
+	w.ec.CountVersions(j.Repo.ChartRepositoryID, VersionRegistered, 1)
+	j.registered()
+	return nil
 }
 
 // handleUnregisterJob handles the provided chart release unregistration job.
@@ -231,37 +433,158 @@ func (w *Worker) handleRegisterJob(j *Job) error {
 // release.
 func (w *Worker) handleUnregisterJob(j *Job) error {
 	// Unregister package
+	name, version := j.nameVersion()
 	p := &hub.Package{
-		Kind:            hub.Chart,
-		Name:            j.ChartVersion.Name,
-		Version:         j.ChartVersion.Version,
-		ChartRepository: j.Repo,
+		Kind:            j.Repo.Kind,
+		Name:            name,
+		Version:         version,
+		ChartRepository: packageChartRepository(j.Repo),
 	}
 	err := w.pm.Unregister(w.ctx, p)
 	if err != nil {
-		w.ec.Append(
-			j.Repo.ChartRepositoryID,
-			fmt.Errorf("error unregistering package %s version %s: %w", p.Name, p.Version, err),
-		)
+		w.ec.Append(j.Repo.ChartRepositoryID, &hub.TrackingError{
+			Code:     hub.UnregisterFailed,
+			Severity: hub.SeverityError,
+			Chart:    p.Name,
+			Version:  p.Version,
+			Message:  fmt.Sprintf("error unregistering package %s version %s: %s", p.Name, p.Version, err),
+		})
+		return err
 	}
-	return err
+	w.ec.CountVersions(j.Repo.ChartRepositoryID, VersionUnregistered, 1)
+	return nil
 }
 
-// loadChart loads a chart from a remote archive located at the url provided.
-func (w *Worker) loadChart(u string) (*chart.Chart, error) {
-	resp, err := w.hg.Get(u)
+// loadChart loads a chart from a remote archive located at the url provided,
+// returning it along with the archive content. Charts stored in OCI registries
+// are pulled from the registry. The archive downloaded is verified against the
+// digest provided, which is also used to look it up in the cache.
+func (w *Worker) loadChart(r *hub.ChartRepository, u, digest string) (*chart.Chart, []byte, error) {
+	var err error
+	data := w.getCachedArchive(digest)
+	if data == nil {
+		if chartrepo.IsOCIReference(u) {
+			data, err = w.op.PullChart(r, u)
+		} else {
+			data, err = w.download(r, u)
+			if err == nil {
+				err = chartrepo.VerifyDigest(data, digest)
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		w.cacheArchive(digest, data)
+	}
+	chart, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", errInvalidChartArchive, err)
+	}
+	return chart, data, nil
+}
+
+// getCachedArchive returns the chart archive identified by the digest provided
+// from the cache, if available. Archives are stored by the hash of their
+// content, which is looked up using the digest they were downloaded with (the
+// index one for http repositories or the manifest one for OCI registries).
+func (w *Worker) getCachedArchive(digest string) []byte {
+	if w.c == nil || digest == "" {
+		return nil
+	}
+	hash, ok := w.c.Get(archiveRefCacheKey(digest))
+	if !ok {
+		return nil
+	}
+	data, ok := w.c.Get("archive-" + string(hash))
+	if !ok || fmt.Sprintf("%x", sha256.Sum256(data)) != string(hash) {
+		return nil
+	}
+	return data
+}
+
+// cacheArchive stores the chart archive provided in the cache by the hash of
+// its content, recording it as the archive identified by the digest given.
+func (w *Worker) cacheArchive(digest string, data []byte) {
+	if w.c == nil || digest == "" {
+		return
+	}
+	hash := fmt.Sprintf("%x", sha256.Sum256(data))
+	w.c.Set("archive-"+hash, data)
+	w.c.Set(archiveRefCacheKey(digest), []byte(hash))
+}
+
+// archiveRefCacheKey returns the key of the cache entry that records the hash
+// of the chart archive identified by the digest provided.
+func archiveRefCacheKey(digest string) string {
+	return fmt.Sprintf("archive-ref-%x", sha256.Sum256([]byte(strings.ToLower(digest))))
+}
+
+// download downloads the file located at the url provided, which belongs to
+// the chart repository given. The chart repository credentials and TLS
+// settings are used when available.
+func (w *Worker) download(r *hub.ChartRepository, u string) ([]byte, error) {
+	var resp *http.Response
+	var err error
+	if r.Credentials != nil || r.TLSConfig != nil {
+		resp, err = w.hg.GetFromRepository(r, u)
+	} else {
+		resp, err = w.hg.Get(u)
+	}
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusOK {
-		chart, err := loader.LoadArchive(resp.Body)
-		if err != nil {
-			return nil, err
+		return ioutil.ReadAll(resp.Body)
+	}
+	return nil, &unexpectedStatusError{resp.StatusCode}
+}
+
+// verifyProvenance downloads the provenance file of the chart archive located
+// at the url provided and verifies it using the chart repository keyring. No
+// signature status is returned when the provenance file is not available.
+func (w *Worker) verifyProvenance(
+	r *hub.ChartRepository,
+	u string,
+	archive []byte,
+) (hub.SignatureStatus, string) {
+	prov, err := w.download(r, u+".prov")
+	if err != nil {
+		var e *unexpectedStatusError
+		if !errors.As(err, &e) || e.statusCode != http.StatusNotFound {
+			w.logger.Debug().Err(err).Str("url", u+".prov").Msg("get provenance file failed")
 		}
-		return chart, nil
+		return "", ""
+	}
+	archiveName := path.Base(u)
+	if tmp, err := url.Parse(u); err == nil {
+		archiveName = path.Base(tmp.Path)
+	}
+	return chartrepo.VerifyProvenance(archive, archiveName, prov, r.Keyring)
+}
+
+// unexpectedStatusError represents an error caused by receiving an unexpected
+// http status code when downloading a file.
+type unexpectedStatusError struct {
+	statusCode int
+}
+
+// Error implements the error interface.
+func (e *unexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code received: %d", e.statusCode)
+}
+
+// packageChartRepository returns the chart repository reference that should
+// be used in the packages registered or unregistered. Only the identifier is
+// required, so the repository credentials are not sent to the database.
+func packageChartRepository(r *hub.ChartRepository) *hub.ChartRepository {
+	return &hub.ChartRepository{
+		ChartRepositoryID: r.ChartRepositoryID,
+		Name:              r.Name,
+		DisplayName:       r.DisplayName,
+		URL:               r.URL,
+		UserID:            r.UserID,
 	}
-	return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
 }
 
 // getFile returns the file requested from the provided chart.
@@ -273,3 +596,15 @@ func getFile(chart *chart.Chart, name string) *chart.File {
 	}
 	return nil
 }
+
+// getRawFile returns the file requested from the raw files of the provided
+// chart, which include the ones processed when loading the chart, like the
+// values.yaml file.
+func getRawFile(chart *chart.Chart, name string) *chart.File {
+	for _, file := range chart.Raw {
+		if file.Name == name {
+			return file
+		}
+	}
+	return nil
+}
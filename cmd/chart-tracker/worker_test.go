@@ -1,19 +1,30 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
+	"reflect"
 	"strings"
 	"sync"
 	"testing"
 
+	"github.com/artifacthub/hub/internal/chartrepo"
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/img"
 	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/repo"
 )
@@ -42,6 +53,15 @@ func TestWorker(t *testing.T) {
 				"http://tests/pkg2-1.0.0.tgz",
 			},
 		}
+		pkg3V1 := &repo.ChartVersion{
+			Metadata: &chart.Metadata{
+				Name:    "pkg3",
+				Version: "1.0.0",
+			},
+			URLs: []string{
+				"http://tests/pkg3-1.0.0.tgz",
+			},
+		}
 		job := &Job{
 			Kind:         Register,
 			Repo:         repo1,
@@ -88,9 +108,14 @@ func TestWorker(t *testing.T) {
 				Body:       f,
 				StatusCode: http.StatusOK,
 			}, nil)
+			ww.hg.On("Get", job.ChartVersion.URLs[0]+".prov").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				StatusCode: http.StatusNotFound,
+			}, nil)
 			ww.hg.On("Get", mock.Anything).Return(nil, errFake)
 			ww.ec.On("Append", job.Repo.ChartRepositoryID, mock.Anything).Return()
 			ww.pm.On("Register", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", job.Repo.ChartRepositoryID, VersionRegistered, 1).Return()
 
 			// Run worker and check expectations
 			ww.w.Run(ww.wg, ww.queue)
@@ -107,12 +132,17 @@ func TestWorker(t *testing.T) {
 				Body:       f,
 				StatusCode: http.StatusOK,
 			}, nil)
+			ww.hg.On("Get", job.ChartVersion.URLs[0]+".prov").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				StatusCode: http.StatusNotFound,
+			}, nil)
 			ww.hg.On("Get", mock.Anything).Return(&http.Response{
 				Body:       ioutil.NopCloser(strings.NewReader("")),
 				StatusCode: http.StatusUnauthorized,
 			}, nil)
 			ww.ec.On("Append", job.Repo.ChartRepositoryID, mock.Anything).Return()
 			ww.pm.On("Register", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", job.Repo.ChartRepositoryID, VersionRegistered, 1).Return()
 
 			// Run worker and check expectations
 			ww.w.Run(ww.wg, ww.queue)
@@ -129,12 +159,17 @@ func TestWorker(t *testing.T) {
 				Body:       f,
 				StatusCode: http.StatusOK,
 			}, nil)
+			ww.hg.On("Get", job.ChartVersion.URLs[0]+".prov").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				StatusCode: http.StatusNotFound,
+			}, nil)
 			ww.hg.On("Get", mock.Anything).Return(&http.Response{
 				Body:       ioutil.NopCloser(strings.NewReader("imageData")),
 				StatusCode: http.StatusOK,
 			}, nil)
 			ww.is.On("SaveImage", mock.Anything, []byte("imageData")).Return("", errFake)
 			ww.pm.On("Register", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", job.Repo.ChartRepositoryID, VersionRegistered, 1).Return()
 
 			// Run worker and check expectations
 			ww.w.Run(ww.wg, ww.queue)
@@ -151,6 +186,10 @@ func TestWorker(t *testing.T) {
 				Body:       f,
 				StatusCode: http.StatusOK,
 			}, nil)
+			ww.hg.On("Get", job.ChartVersion.URLs[0]+".prov").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				StatusCode: http.StatusNotFound,
+			}, nil)
 			ww.hg.On("Get", mock.Anything).Return(&http.Response{
 				Body:       ioutil.NopCloser(strings.NewReader("imageData")),
 				StatusCode: http.StatusOK,
@@ -174,18 +213,105 @@ func TestWorker(t *testing.T) {
 				Body:       f,
 				StatusCode: http.StatusOK,
 			}, nil)
+			ww.hg.On("Get", job.ChartVersion.URLs[0]+".prov").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				StatusCode: http.StatusNotFound,
+			}, nil)
 			ww.hg.On("Get", mock.Anything).Return(&http.Response{
 				Body:       ioutil.NopCloser(strings.NewReader("imageData")),
 				StatusCode: http.StatusOK,
 			}, nil)
 			ww.is.On("SaveImage", mock.Anything, []byte("imageData")).Return("imageID", nil)
 			ww.pm.On("Register", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", job.Repo.ChartRepositoryID, VersionRegistered, 1).Return()
+
+			// Run worker and check expectations
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+		})
+
+		t.Run("chart archive digest mismatch", func(t *testing.T) {
+			// Setup worker and expectations
+			cv := *job.ChartVersion
+			cv.Digest = fmt.Sprintf("%x", sha256.Sum256([]byte("other")))
+			ww := newWorkerWrapper(context.Background())
+			ww.queue <- &Job{
+				Kind:         Register,
+				Repo:         repo1,
+				ChartVersion: &cv,
+				GetLogo:      true,
+			}
+			close(ww.queue)
+			f, _ := os.Open("testdata/" + path.Base(cv.URLs[0]))
+			ww.hg.On("Get", cv.URLs[0]).Return(&http.Response{
+				Body:       f,
+				StatusCode: http.StatusOK,
+			}, nil)
+			ww.ec.On("Append", job.Repo.ChartRepositoryID, mock.MatchedBy(func(e *hub.TrackingError) bool {
+				return e.Code == hub.DigestMismatch && e.Chart == cv.Metadata.Name
+			})).Return()
 
 			// Run worker and check expectations
 			ww.w.Run(ww.wg, ww.queue)
 			ww.assertExpectations(t)
 		})
 
+		t.Run("package registered successfully using cached archive and logo", func(t *testing.T) {
+			cacheDir, err := ioutil.TempDir("", "chart-tracker-cache")
+			require.NoError(t, err)
+			defer os.RemoveAll(cacheDir)
+			c, err := NewDiskCache(cacheDir, 1<<20)
+			require.NoError(t, err)
+			archive, err := ioutil.ReadFile("testdata/" + path.Base(job.ChartVersion.URLs[0]))
+			require.NoError(t, err)
+			cv := *job.ChartVersion
+			cv.Digest = fmt.Sprintf("%x", sha256.Sum256(archive))
+			cachedJob := &Job{
+				Kind:         Register,
+				Repo:         repo1,
+				ChartVersion: &cv,
+				GetLogo:      true,
+			}
+
+			// First run: archive and logo are downloaded and cached
+			ww := newWorkerWrapper(context.Background())
+			ww.w.c = c
+			ww.queue <- cachedJob
+			close(ww.queue)
+			ww.hg.On("Get", cv.URLs[0]).Return(&http.Response{
+				Body:       ioutil.NopCloser(bytes.NewReader(archive)),
+				StatusCode: http.StatusOK,
+			}, nil).Once()
+			ww.hg.On("Get", cv.URLs[0]+".prov").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				StatusCode: http.StatusNotFound,
+			}, nil)
+			ww.hg.On("Get", mock.Anything).Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("imageData")),
+				StatusCode: http.StatusOK,
+			}, nil).Once()
+			ww.is.On("SaveImage", mock.Anything, []byte("imageData")).Return("imageID", nil)
+			ww.pm.On("Register", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", job.Repo.ChartRepositoryID, VersionRegistered, 1).Return()
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+
+			// Second run: only the provenance file is downloaded
+			ww = newWorkerWrapper(context.Background())
+			ww.w.c = c
+			ww.queue <- cachedJob
+			close(ww.queue)
+			ww.hg.On("Get", cv.URLs[0]+".prov").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				StatusCode: http.StatusNotFound,
+			}, nil)
+			ww.is.On("SaveImage", mock.Anything, []byte("imageData")).Return("imageID", nil)
+			ww.pm.On("Register", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", job.Repo.ChartRepositoryID, VersionRegistered, 1).Return()
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+		})
+
 		t.Run("package with logo in data url registered successfully", func(t *testing.T) {
 			// Setup worker and expectations
 			ww := newWorkerWrapper(context.Background())
@@ -202,9 +328,56 @@ func TestWorker(t *testing.T) {
 				Body:       f,
 				StatusCode: http.StatusOK,
 			}, nil)
+			ww.hg.On("Get", job.ChartVersion.URLs[0]+".prov").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				StatusCode: http.StatusNotFound,
+			}, nil)
 			expectedLogoData, _ := ioutil.ReadFile("testdata/red-dot.png")
 			ww.is.On("SaveImage", mock.Anything, expectedLogoData).Return("imageID", nil)
 			ww.pm.On("Register", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", job.Repo.ChartRepositoryID, VersionRegistered, 1).Return()
+
+			// Run worker and check expectations
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+		})
+
+		t.Run("package with values, templates, resources, dependencies and images registered successfully", func(t *testing.T) {
+			// Setup worker and expectations
+			ww := newWorkerWrapper(context.Background())
+			job := &Job{
+				Kind:         Register,
+				Repo:         repo1,
+				ChartVersion: pkg3V1,
+			}
+			ww.queue <- job
+			close(ww.queue)
+			f, _ := os.Open("testdata/" + path.Base(job.ChartVersion.URLs[0]))
+			ww.hg.On("Get", job.ChartVersion.URLs[0]).Return(&http.Response{
+				Body:       f,
+				StatusCode: http.StatusOK,
+			}, nil)
+			ww.hg.On("Get", job.ChartVersion.URLs[0]+".prov").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("")),
+				StatusCode: http.StatusNotFound,
+			}, nil)
+			ww.pm.On("Register", mock.Anything, mock.MatchedBy(func(p *hub.Package) bool {
+				var schema map[string]interface{}
+				return p.Name == "pkg3" &&
+					p.DefaultValues == "replicaCount: 1\nimage:\n  repository: nginx\n" &&
+					json.Unmarshal(p.ValuesSchema, &schema) == nil && schema["type"] == "object" &&
+					reflect.DeepEqual(p.Templates, []string{"templates/deployment.yaml", "templates/service.yaml"}) &&
+					reflect.DeepEqual(p.Data["kinds"], []string{"CustomResourceDefinition", "Deployment", "Service"}) &&
+					reflect.DeepEqual(p.Dependencies, []*hub.Dependency{
+						{Name: "pkg1", Version: "^1.0.0", Repository: "https://tests"},
+					}) &&
+					reflect.DeepEqual(p.Images, []string{"docker.io/library/nginx:1.17"}) &&
+					p.ContentFingerprint != "" &&
+					p.Lint != nil && p.Lint.IsClean() &&
+					p.ChartType == hub.ApplicationChart &&
+					p.APIVersion == "v2"
+			})).Return(nil)
+			ww.ec.On("CountVersions", job.Repo.ChartRepositoryID, VersionRegistered, 1).Return()
 
 			// Run worker and check expectations
 			ww.w.Run(ww.wg, ww.queue)
@@ -212,6 +385,152 @@ func TestWorker(t *testing.T) {
 		})
 	})
 
+	t.Run("handle register job of signed chart in private repository", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			http.ServeFile(w, r, "testdata"+r.URL.Path)
+		}))
+		defer s.Close()
+		keyring, _ := ioutil.ReadFile("testdata/keyring.asc")
+		privateRepo := &hub.ChartRepository{
+			ChartRepositoryID: "repo1",
+			URL:               s.URL,
+			Credentials: &hub.ChartRepositoryCredentials{
+				Username: "user",
+				Password: "pass",
+			},
+			Keyring: string(keyring),
+		}
+
+		// Setup worker and expectations
+		ww := newWorkerWrapper(context.Background())
+		ww.w.hg = NewRetryingHTTPGetter(context.Background(), viper.New())
+		ww.queue <- &Job{
+			Kind: Register,
+			Repo: privateRepo,
+			ChartVersion: &repo.ChartVersion{
+				Metadata: &chart.Metadata{
+					Name:    "pkg1",
+					Version: "1.0.0",
+				},
+				URLs: []string{
+					"pkg1-1.0.0.tgz",
+				},
+			},
+		}
+		close(ww.queue)
+		ww.pm.On("Register", mock.Anything, mock.MatchedBy(func(p *hub.Package) bool {
+			return p.Name == "pkg1" &&
+				p.ChartRepository.Credentials == nil &&
+				p.SignatureStatus == hub.Verified &&
+				p.SignatureKeyFingerprint == "4406DF30E253D851D126075FEA45EC1D7E93770A"
+		})).Return(nil)
+		ww.ec.On("CountVersions", privateRepo.ChartRepositoryID, VersionRegistered, 1).Return()
+
+		// Run worker and check expectations
+		ww.w.Run(ww.wg, ww.queue)
+		ww.assertExpectations(t)
+	})
+
+	t.Run("handle register job of chart stored in oci registry", func(t *testing.T) {
+		registry := tests.NewOCIRegistry()
+		defer registry.Close()
+		archive, _ := ioutil.ReadFile("testdata/pkg1-1.0.0.tgz")
+		registry.PushChart("charts/pkg1", "1.0.0", archive)
+		newJob := func(tag string) *Job {
+			return &Job{
+				Kind: Register,
+				Repo: repo1,
+				ChartVersion: &repo.ChartVersion{
+					Metadata: &chart.Metadata{
+						Name:    "pkg1",
+						Version: tag,
+					},
+					URLs: []string{
+						"oci://" + registry.Host() + "/charts/pkg1:" + tag,
+					},
+				},
+			}
+		}
+
+		t.Run("error pulling chart", func(t *testing.T) {
+			// Setup worker and expectations
+			ww := newWorkerWrapper(context.Background())
+			ww.queue <- newJob("2.0.0")
+			close(ww.queue)
+			ww.ec.On("Append", repo1.ChartRepositoryID, mock.Anything).Return()
+
+			// Run worker and check expectations
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+		})
+
+		t.Run("package registered successfully", func(t *testing.T) {
+			// Setup worker and expectations
+			ww := newWorkerWrapper(context.Background())
+			ww.queue <- newJob("1.0.0")
+			close(ww.queue)
+			ww.pm.On("Register", mock.Anything, mock.MatchedBy(func(p *hub.Package) bool {
+				return p.Name == "pkg1" && p.Version == "1.0.0"
+			})).Return(nil)
+			ww.ec.On("CountVersions", repo1.ChartRepositoryID, VersionRegistered, 1).Return()
+
+			// Run worker and check expectations
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+		})
+
+		t.Run("package registered successfully using cached archive", func(t *testing.T) {
+			registry := tests.NewOCIRegistry()
+			manifestDigest := registry.PushChart("charts/pkg1", "1.0.0", archive)
+			cacheDir, err := ioutil.TempDir("", "chart-tracker-cache")
+			require.NoError(t, err)
+			defer os.RemoveAll(cacheDir)
+			c, err := NewDiskCache(cacheDir, 1<<20)
+			require.NoError(t, err)
+			job := &Job{
+				Kind: Register,
+				Repo: repo1,
+				ChartVersion: &repo.ChartVersion{
+					Metadata: &chart.Metadata{
+						Name:    "pkg1",
+						Version: "1.0.0",
+					},
+					URLs: []string{
+						"oci://" + registry.Host() + "/charts/pkg1:1.0.0",
+					},
+					Digest: manifestDigest,
+				},
+			}
+
+			// First run: archive is pulled from the registry and cached
+			ww := newWorkerWrapper(context.Background())
+			ww.w.c = c
+			ww.queue <- job
+			close(ww.queue)
+			ww.pm.On("Register", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", repo1.ChartRepositoryID, VersionRegistered, 1).Return()
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+			_, ok := c.Get(fmt.Sprintf("archive-%x", sha256.Sum256(archive)))
+			assert.True(t, ok)
+
+			// Second run: registry is no longer available, archive is cached
+			registry.Close()
+			ww = newWorkerWrapper(context.Background())
+			ww.w.c = c
+			ww.queue <- job
+			close(ww.queue)
+			ww.pm.On("Register", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", repo1.ChartRepositoryID, VersionRegistered, 1).Return()
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+		})
+	})
+
 	t.Run("handle unregister job", func(t *testing.T) {
 		job := &Job{
 			Kind: Unregister,
@@ -243,12 +562,115 @@ func TestWorker(t *testing.T) {
 			ww.queue <- job
 			close(ww.queue)
 			ww.pm.On("Unregister", mock.Anything, mock.Anything).Return(nil)
+			ww.ec.On("CountVersions", job.Repo.ChartRepositoryID, VersionUnregistered, 1).Return()
+
+			// Run worker and check expectations
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+		})
+
+		t.Run("falco rules package unregistered successfully", func(t *testing.T) {
+			// Setup worker and expectations
+			ww := newWorkerWrapper(context.Background())
+			ww.queue <- &Job{
+				Kind:    Unregister,
+				Repo:    &hub.ChartRepository{ChartRepositoryID: "repo1", Kind: hub.Falco},
+				Package: &hub.Package{Name: "pkg1", Version: "1.0.0"},
+			}
+			close(ww.queue)
+			ww.pm.On("Unregister", mock.Anything, mock.MatchedBy(func(p *hub.Package) bool {
+				return p.Kind == hub.Falco && p.Name == "pkg1" && p.Version == "1.0.0" &&
+					p.ChartRepository.ChartRepositoryID == "repo1"
+			})).Return(nil)
+			ww.ec.On("CountVersions", "repo1", VersionUnregistered, 1).Return()
 
 			// Run worker and check expectations
 			ww.w.Run(ww.wg, ww.queue)
 			ww.assertExpectations(t)
 		})
 	})
+
+	t.Run("handle register package job", func(t *testing.T) {
+		job := &Job{
+			Kind: Register,
+			Repo: &hub.ChartRepository{
+				ChartRepositoryID: "repo1",
+				Kind:              hub.Falco,
+			},
+			Package: &hub.Package{
+				Kind:    hub.Falco,
+				Name:    "pkg1",
+				Version: "1.0.0",
+				LogoURL: "http://logo.url",
+				Digest:  "digest",
+			},
+			GetLogo: true,
+		}
+
+		t.Run("error registering package", func(t *testing.T) {
+			// Setup worker and expectations
+			ww := newWorkerWrapper(context.Background())
+			ww.queue <- job
+			close(ww.queue)
+			ww.hg.On("Get", "http://logo.url").Return(nil, errFake)
+			ww.ec.On("Append", "repo1", mock.MatchedBy(func(e *hub.TrackingError) bool {
+				return e.Code == hub.LogoFailed && e.Severity == hub.SeverityWarning
+			})).Return()
+			ww.ec.On("Append", "repo1", mock.MatchedBy(func(e *hub.TrackingError) bool {
+				return e.Code == hub.RegisterFailed && e.Severity == hub.SeverityError
+			})).Return()
+			ww.pm.On("Register", mock.Anything, mock.Anything).Return(errFake)
+
+			// Run worker and check expectations
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+		})
+
+		t.Run("package with invalid semver not registered", func(t *testing.T) {
+			// Setup worker and expectations
+			p := *job.Package
+			p.Version = "1.0"
+			p.LogoURL = ""
+			ww := newWorkerWrapper(context.Background())
+			ww.queue <- &Job{Kind: Register, Repo: job.Repo, Package: &p}
+			close(ww.queue)
+			ww.ec.On("Append", "repo1", mock.MatchedBy(func(e *hub.TrackingError) bool {
+				return e.Code == hub.InvalidSemver &&
+					e.Severity == hub.SeverityError &&
+					e.Chart == "pkg1" &&
+					e.Version == "1.0"
+			})).Return()
+
+			// Run worker and check expectations
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+		})
+
+		t.Run("package registered successfully", func(t *testing.T) {
+			// Setup worker and expectations
+			ww := newWorkerWrapper(context.Background())
+			ww.queue <- job
+			close(ww.queue)
+			ww.hg.On("Get", "http://logo.url").Return(&http.Response{
+				Body:       ioutil.NopCloser(strings.NewReader("imageData")),
+				StatusCode: http.StatusOK,
+			}, nil)
+			ww.is.On("SaveImage", mock.Anything, []byte("imageData")).Return("imageID", nil)
+			ww.pm.On("Register", mock.Anything, mock.MatchedBy(func(p *hub.Package) bool {
+				return p.Kind == hub.Falco &&
+					p.Name == "pkg1" &&
+					p.LogoImageID == "imageID" &&
+					p.Digest == "digest" &&
+					p.ChartRepository.ChartRepositoryID == "repo1"
+			})).Return(nil)
+			ww.ec.On("CountVersions", "repo1", VersionRegistered, 1).Return()
+
+			// Run worker and check expectations
+			ww.w.Run(ww.wg, ww.queue)
+			ww.assertExpectations(t)
+			assert.Empty(t, job.Package.LogoImageID)
+		})
+	})
 }
 
 type workerWrapper struct {
@@ -267,7 +689,7 @@ func newWorkerWrapper(ctx context.Context) *workerWrapper {
 	is := &img.StoreMock{}
 	ec := &ErrorsCollectorMock{}
 	hg := &httpGetterMock{}
-	w := NewWorker(ctx, 1, pm, is, ec, hg)
+	w := NewWorker(ctx, 1, pm, is, ec, hg, &chartrepo.OCIClient{}, nil)
 	queue := make(chan *Job, 100)
 
 	// Wait group used for Worker.Run()
@@ -303,3 +725,9 @@ func (m *httpGetterMock) Get(url string) (*http.Response, error) {
 	resp, _ := args.Get(0).(*http.Response)
 	return resp, args.Error(1)
 }
+
+func (m *httpGetterMock) GetFromRepository(r *hub.ChartRepository, url string) (*http.Response, error) {
+	args := m.Called(r, url)
+	resp, _ := args.Get(0).(*http.Response)
+	return resp, args.Error(1)
+}
@@ -3,7 +3,9 @@ package chartrepo
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/artifacthub/hub/cmd/hub/handlers/helpers"
 	"github.com/artifacthub/hub/internal/chartrepo"
@@ -13,6 +15,12 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+const (
+	// defaultTrackingRunsLimit represents the number of tracking runs returned
+	// when no limit is provided.
+	defaultTrackingRunsLimit = 10
+)
+
 // Handlers represents a group of http handlers in charge of handling chart
 // repositories operations.
 type Handlers struct {
@@ -113,6 +121,97 @@ func (h *Handlers) GetOwnedByUser(w http.ResponseWriter, r *http.Request) {
 	helpers.RenderJSON(w, dataJSON, 0)
 }
 
+// GetLintSummary is an http handler that returns a summary of the lint results
+// of the latest version of the packages in the provided chart repository.
+func (h *Handlers) GetLintSummary(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	dataJSON, err := h.chartRepoManager.GetLintSummaryJSON(r.Context(), repoName)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "GetLintSummary").Send()
+		if errors.Is(err, chartrepo.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, chartrepo.ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge)
+}
+
+// GetSyncRequest is an http handler that returns the status of the provided
+// sync request of a chart repository.
+func (h *Handlers) GetSyncRequest(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	syncRequestID := chi.URLParam(r, "syncRequestID")
+	dataJSON, err := h.chartRepoManager.GetSyncRequestJSON(r.Context(), repoName, syncRequestID)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "GetSyncRequest").Send()
+		if errors.Is(err, chartrepo.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, chartrepo.ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, dataJSON, 0)
+}
+
+// GetTrackingRuns is an http handler that returns the most recent tracking
+// runs of the provided chart repository. The number of runs returned can be
+// adjusted using the limit query parameter.
+func (h *Handlers) GetTrackingRuns(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	limit := defaultTrackingRunsLimit
+	if v := r.FormValue("limit"); v != "" {
+		var err error
+		limit, err = strconv.Atoi(v)
+		if err != nil {
+			h.logger.Error().Err(err).Str("method", "GetTrackingRuns").Msg("invalid limit")
+			http.Error(w, fmt.Sprintf("invalid limit: %s", v), http.StatusBadRequest)
+			return
+		}
+	}
+	dataJSON, err := h.chartRepoManager.GetTrackingRunsJSON(r.Context(), repoName, limit)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "GetTrackingRuns").Send()
+		if errors.Is(err, chartrepo.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, dataJSON, 0)
+}
+
+// RequestSync is an http handler that registers a request to track the
+// provided chart repository as soon as possible. The id of the sync request
+// is returned, so that its status can be checked later.
+func (h *Handlers) RequestSync(w http.ResponseWriter, r *http.Request) {
+	repoName := chi.URLParam(r, "repoName")
+	syncRequestID, err := h.chartRepoManager.RequestSync(r.Context(), repoName)
+	if err != nil {
+		h.logger.Error().Err(err).Str("method", "RequestSync").Send()
+		if errors.Is(err, chartrepo.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	dataJSON, _ := json.Marshal(map[string]string{
+		"sync_request_id": syncRequestID,
+	})
+	w.Header().Set("Cache-Control", helpers.BuildCacheControlHeader(0))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = w.Write(dataJSON)
+}
+
 // Update is an http handler that updates the provided chart repository in the
 // database.
 func (h *Handlers) Update(w http.ResponseWriter, r *http.Request) {
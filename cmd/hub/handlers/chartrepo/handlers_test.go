@@ -81,7 +81,8 @@ func TestAdd(t *testing.T) {
 		{
 			"name": "repo1",
 			"display_name": "Repository 1",
-			"url": "https://repo1.url"
+			"url": "https://repo1.url",
+			"tracking_interval": 3600
 		}
 		`
 		testCases := []struct {
@@ -104,7 +105,9 @@ func TestAdd(t *testing.T) {
 			tc := tc
 			t.Run(tc.description, func(t *testing.T) {
 				hw := newHandlersWrapper()
-				hw.rm.On("Add", mock.Anything, "org1", mock.Anything).Return(tc.err)
+				hw.rm.On("Add", mock.Anything, "org1", mock.MatchedBy(func(r *hub.ChartRepository) bool {
+					return r.Name == "repo1" && r.TrackingInterval == 3600
+				})).Return(tc.err)
 
 				w := httptest.NewRecorder()
 				r, _ := http.NewRequest("POST", "/", strings.NewReader(repoJSON))
@@ -383,6 +386,297 @@ func TestGetOwnedByUser(t *testing.T) {
 	})
 }
 
+func TestGetLintSummary(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"repoName"},
+			Values: []string{"repo1"},
+		},
+	}
+
+	t.Run("get lint summary succeeded", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.rm.On("GetLintSummaryJSON", mock.Anything, "repo1").Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		hw.h.GetLintSummary(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.rm.AssertExpectations(t)
+	})
+
+	t.Run("error getting lint summary", func(t *testing.T) {
+		testCases := []struct {
+			rmErr              error
+			expectedStatusCode int
+		}{
+			{
+				chartrepo.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				chartrepo.ErrNotFound,
+				http.StatusNotFound,
+			},
+			{
+				tests.ErrFakeDatabaseFailure,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.rmErr.Error(), func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.rm.On("GetLintSummaryJSON", mock.Anything, "repo1").Return(nil, tc.rmErr)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+				hw.h.GetLintSummary(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.rm.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestGetSyncRequest(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"repoName", "syncRequestID"},
+			Values: []string{"repo1", "syncRequestID"},
+		},
+	}
+
+	t.Run("get sync request succeeded", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.rm.On("GetSyncRequestJSON", mock.Anything, "repo1", "syncRequestID").Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		hw.h.GetSyncRequest(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.rm.AssertExpectations(t)
+	})
+
+	t.Run("error getting sync request", func(t *testing.T) {
+		testCases := []struct {
+			rmErr              error
+			expectedStatusCode int
+		}{
+			{
+				chartrepo.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				chartrepo.ErrNotFound,
+				http.StatusNotFound,
+			},
+			{
+				tests.ErrFakeDatabaseFailure,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.rmErr.Error(), func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.rm.On("GetSyncRequestJSON", mock.Anything, "repo1", "syncRequestID").Return(nil, tc.rmErr)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+				hw.h.GetSyncRequest(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.rm.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestGetTrackingRuns(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"repoName"},
+			Values: []string{"repo1"},
+		},
+	}
+
+	t.Run("invalid limit", func(t *testing.T) {
+		hw := newHandlersWrapper()
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/?limit=a", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		hw.h.GetTrackingRuns(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		hw.rm.AssertExpectations(t)
+	})
+
+	t.Run("get tracking runs succeeded", func(t *testing.T) {
+		testCases := []struct {
+			url           string
+			expectedLimit int
+		}{
+			{"/", defaultTrackingRunsLimit},
+			{"/?limit=5", 5},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.url, func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.rm.On("GetTrackingRunsJSON", mock.Anything, "repo1", tc.expectedLimit).
+					Return([]byte("dataJSON"), nil)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", tc.url, nil)
+				r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+				hw.h.GetTrackingRuns(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+				h := resp.Header
+				data, _ := ioutil.ReadAll(resp.Body)
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode)
+				assert.Equal(t, "application/json", h.Get("Content-Type"))
+				assert.Equal(t, helpers.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+				assert.Equal(t, []byte("dataJSON"), data)
+				hw.rm.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("error getting tracking runs", func(t *testing.T) {
+		testCases := []struct {
+			rmErr              error
+			expectedStatusCode int
+		}{
+			{
+				chartrepo.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				tests.ErrFakeDatabaseFailure,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.rmErr.Error(), func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.rm.On("GetTrackingRunsJSON", mock.Anything, "repo1", defaultTrackingRunsLimit).
+					Return(nil, tc.rmErr)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+				hw.h.GetTrackingRuns(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.rm.AssertExpectations(t)
+			})
+		}
+	})
+}
+
+func TestRequestSync(t *testing.T) {
+	rctx := &chi.Context{
+		URLParams: chi.RouteParams{
+			Keys:   []string{"repoName"},
+			Values: []string{"repo1"},
+		},
+	}
+
+	t.Run("sync requested successfully", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.rm.On("RequestSync", mock.Anything, "repo1").Return("syncRequestID", nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+		r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+		r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+		hw.h.RequestSync(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(0), h.Get("Cache-Control"))
+		assert.JSONEq(t, `{"sync_request_id": "syncRequestID"}`, string(data))
+		hw.rm.AssertExpectations(t)
+	})
+
+	t.Run("error requesting sync", func(t *testing.T) {
+		testCases := []struct {
+			rmErr              error
+			expectedStatusCode int
+		}{
+			{
+				chartrepo.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				tests.ErrFakeDatabaseFailure,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.rmErr.Error(), func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.rm.On("RequestSync", mock.Anything, "repo1").Return("", tc.rmErr)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("POST", "/", nil)
+				r = r.WithContext(context.WithValue(r.Context(), hub.UserIDKey, "userID"))
+				r = r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+				hw.h.RequestSync(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.rm.AssertExpectations(t)
+			})
+		}
+	})
+}
+
 func TestUpdate(t *testing.T) {
 	t.Run("invalid input", func(t *testing.T) {
 		testCases := []struct {
@@ -431,7 +725,8 @@ func TestUpdate(t *testing.T) {
 		repoJSON := `
 		{
 			"display_name": "Repository 1 updated",
-			"url": "https://repo1.url/updated"
+			"url": "https://repo1.url/updated",
+			"tracking_interval": 3600
 		}
 		`
 		testCases := []struct {
@@ -454,7 +749,9 @@ func TestUpdate(t *testing.T) {
 			tc := tc
 			t.Run(tc.description, func(t *testing.T) {
 				hw := newHandlersWrapper()
-				hw.rm.On("Update", mock.Anything, mock.Anything).Return(tc.err)
+				hw.rm.On("Update", mock.Anything, mock.MatchedBy(func(r *hub.ChartRepository) bool {
+					return r.TrackingInterval == 3600
+				})).Return(tc.err)
 
 				w := httptest.NewRecorder()
 				r, _ := http.NewRequest("PUT", "/", strings.NewReader(repoJSON))
@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/http"
 	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -42,6 +43,7 @@ type Metrics struct {
 type Handlers struct {
 	cfg       *viper.Viper
 	svc       *Services
+	kinds     []*hub.PackageKindInfo
 	metrics   *Metrics
 	logger    zerolog.Logger
 	Router    http.Handler
@@ -54,11 +56,13 @@ type Handlers struct {
 	Static            *static.Handlers
 }
 
-// Setup creates a new Handlers instance.
-func Setup(cfg *viper.Viper, svc *Services) *Handlers {
+// Setup creates a new Handlers instance. The package kinds provided are used to
+// define the routes of the packages that are not charts.
+func Setup(cfg *viper.Viper, svc *Services, kinds []*hub.PackageKindInfo) *Handlers {
 	h := &Handlers{
 		cfg:     cfg,
 		svc:     svc,
+		kinds:   kinds,
 		metrics: setupMetrics(),
 		logger:  log.With().Str("handlers", "root").Logger(),
 
@@ -114,10 +118,16 @@ func (h *Handlers) setupRouter() {
 		})
 		r.Route("/package", func(r chi.Router) {
 			r.Route("/chart/{repoName}/{packageName}", func(r chi.Router) {
+				r.Get("/changelog", h.Packages.GetChangelog)
+				r.Get("/dependents", h.Packages.GetDependents)
+				r.Get("/{version}/dependencies", h.Packages.GetDependencies)
+				r.Get("/{version}/values", h.Packages.GetValues)
+				r.Get("/{version}/values-schema", h.Packages.GetValuesSchema)
 				r.Get("/{version}", h.Packages.Get)
 				r.Get("/", h.Packages.Get)
 			})
-			r.Route("/{^falco$|^opa$}/{packageName}", func(r chi.Router) {
+			r.Route(fmt.Sprintf("/{kind:%s}/{packageName}", h.kindsPattern()), func(r chi.Router) {
+				r.Get("/changelog", h.Packages.GetChangelog)
 				r.Get("/{version}", h.Packages.Get)
 				r.Get("/", h.Packages.Get)
 			})
@@ -126,6 +136,7 @@ func (h *Handlers) setupRouter() {
 				r.With(h.Users.RequireLogin).Put("/", h.Packages.ToggleStar)
 			})
 		})
+		r.Get("/chart-repository/{repoName}/lint-summary", h.ChartRepositories.GetLintSummary)
 		r.Post("/users", h.Users.RegisterUser)
 		r.Route("/user", func(r chi.Router) {
 			r.Use(h.Users.RequireLogin)
@@ -140,6 +151,9 @@ func (h *Handlers) setupRouter() {
 			r.Route("/chart-repository/{repoName}", func(r chi.Router) {
 				r.Put("/", h.ChartRepositories.Update)
 				r.Delete("/", h.ChartRepositories.Delete)
+				r.Post("/sync", h.ChartRepositories.RequestSync)
+				r.Get("/sync/{syncRequestID}", h.ChartRepositories.GetSyncRequest)
+				r.Get("/tracking-runs", h.ChartRepositories.GetTrackingRuns)
 			})
 		})
 		r.With(h.Users.RequireLogin).Post("/orgs", h.Organizations.Add)
@@ -161,6 +175,9 @@ func (h *Handlers) setupRouter() {
 				r.Route("/chart-repository/{repoName}", func(r chi.Router) {
 					r.Put("/", h.ChartRepositories.Update)
 					r.Delete("/", h.ChartRepositories.Delete)
+					r.Post("/sync", h.ChartRepositories.RequestSync)
+					r.Get("/sync/{syncRequestID}", h.ChartRepositories.GetSyncRequest)
+					r.Get("/tracking-runs", h.ChartRepositories.GetTrackingRuns)
 				})
 			})
 		})
@@ -193,7 +210,7 @@ func (h *Handlers) setupRouter() {
 			r.With(h.Packages.InjectIndexMeta).Get("/{version}", h.Static.ServeIndex)
 			r.With(h.Packages.InjectIndexMeta).Get("/", h.Static.ServeIndex)
 		})
-		r.Route("/{^falco$|^opa$}/{packageName}", func(r chi.Router) {
+		r.Route(fmt.Sprintf("/{kind:%s}/{packageName}", h.kindsPattern()), func(r chi.Router) {
 			r.With(h.Packages.InjectIndexMeta).Get("/{version}", h.Static.ServeIndex)
 			r.With(h.Packages.InjectIndexMeta).Get("/", h.Static.ServeIndex)
 		})
@@ -218,16 +235,29 @@ func (h *Handlers) setupBotRouter() {
 			r.Get("/{version}", h.Packages.Get)
 			r.Get("/", h.Packages.Get)
 		})
-	})
-	r.Route("/{^falco$|^opa$}/{packageName}", func(r chi.Router) {
-		r.Get("/{version}", h.Packages.Get)
-		r.Get("/", h.Packages.Get)
+		r.Route(fmt.Sprintf("/{kind:%s}/{packageName}", h.kindsPattern()), func(r chi.Router) {
+			r.Get("/{version}", h.Packages.Get)
+			r.Get("/", h.Packages.Get)
+		})
 	})
 	r.NotFound(h.Static.ServeIndex)
 
 	h.BotRouter = r
 }
 
+// kindsPattern returns a regular expression matching the slugs of the package
+// kinds other than charts, which are identified by their repository instead.
+func (h *Handlers) kindsPattern() string {
+	slugs := make([]string, 0, len(h.kinds))
+	for _, k := range h.kinds {
+		if k.Kind != hub.Chart {
+			slugs = append(slugs, fmt.Sprintf("^%s$", k.Slug))
+		}
+	}
+	sort.Strings(slugs)
+	return strings.Join(slugs, "|")
+}
+
 // MetricsCollector is an http middleware that collects some metrics about
 // requests processed.
 func (h *Handlers) MetricsCollector(next http.Handler) http.Handler {
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/chartrepo"
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/img"
+	"github.com/artifacthub/hub/internal/org"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/artifacthub/hub/internal/user"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMain(m *testing.M) {
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	os.Exit(m.Run())
+}
+
+func TestPackageKindRoutes(t *testing.T) {
+	cfg := viper.New()
+	cfg.Set("server.webBuildPath", "static/testdata")
+	pm := &pkg.ManagerMock{}
+	kinds := []*hub.PackageKindInfo{
+		{Kind: hub.Chart, Name: "Helm charts", Slug: "chart"},
+		{Kind: hub.Falco, Name: "Falco rules", Slug: "falco"},
+		{Kind: hub.PackageKind(4), Name: "Kubectl plugins", Slug: "krew"},
+	}
+	h := Setup(cfg, &Services{
+		OrganizationManager:    &org.ManagerMock{},
+		UserManager:            &user.ManagerMock{},
+		PackageManager:         pm,
+		ChartRepositoryManager: &chartrepo.ManagerMock{},
+		ImageStore:             &img.StoreMock{},
+	}, kinds)
+	pm.On("GetJSON", mock.Anything, &hub.GetPackageInput{
+		PackageKind: "falco",
+		PackageName: "stars",
+	}).Return([]byte("dataJSON"), nil)
+	pm.On("GetJSON", mock.Anything, &hub.GetPackageInput{
+		PackageKind: "krew",
+		PackageName: "pkg1",
+	}).Return([]byte("dataJSON"), nil)
+	pm.On("GetStarsJSON", mock.Anything).Return([]byte("dataJSON"), nil)
+
+	// Paths not matching any route are served the index, so a GetJSON call
+	// with an unexpected input would make the packages manager mock panic
+	testCases := []struct {
+		router             http.Handler
+		path               string
+		expectedStatusCode int
+	}{
+		{h.Router, "/api/v1/package/falco/stars", http.StatusOK},
+		{h.Router, "/api/v1/package/00000000-0000-0000-0000-000000000001/stars", http.StatusOK},
+		{h.Router, "/api/v1/package/krew/pkg1", http.StatusOK},
+		{h.Router, "/api/v1/package/opa/pkg1", http.StatusOK},
+		{h.Router, "/api/v1/package/unknown/pkg1", http.StatusOK},
+		{h.BotRouter, "/package/falco/stars", http.StatusOK},
+		{h.BotRouter, "/about/pkg1", http.StatusOK},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest("GET", tc.path, nil)
+			tc.router.ServeHTTP(w, r)
+			resp := w.Result()
+			defer resp.Body.Close()
+			assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+		})
+	}
+	pm.AssertExpectations(t)
+}
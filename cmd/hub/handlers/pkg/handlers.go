@@ -32,9 +32,78 @@ func NewHandlers(pkgManager hub.PackageManager) *Handlers {
 	}
 }
 
+// GetChangelog is an http handler used to get the changes introduced in each
+// of the versions of a package.
+func (h *Handlers) GetChangelog(w http.ResponseWriter, r *http.Request) {
+	input := &hub.GetPackageInput{
+		PackageKind:         chi.URLParam(r, "kind"),
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+	}
+	dataJSON, err := h.pkgManager.GetChangelogJSON(r.Context(), input)
+	if err != nil {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "GetChangelog").Send()
+		if errors.Is(err, pkg.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, pkg.ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge)
+}
+
+// GetDependencies is an http handler used to get the dependencies of a chart
+// package version.
+func (h *Handlers) GetDependencies(w http.ResponseWriter, r *http.Request) {
+	input := &hub.GetPackageInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+	dataJSON, err := h.pkgManager.GetDependenciesJSON(r.Context(), input)
+	if err != nil {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "GetDependencies").Send()
+		if errors.Is(err, pkg.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, pkg.ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge)
+}
+
+// GetDependents is an http handler used to get the packages that depend on a
+// given chart package.
+func (h *Handlers) GetDependents(w http.ResponseWriter, r *http.Request) {
+	input := &hub.GetPackageInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+	}
+	dataJSON, err := h.pkgManager.GetDependentsJSON(r.Context(), input)
+	if err != nil {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "GetDependents").Send()
+		if errors.Is(err, pkg.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, pkg.ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge)
+}
+
 // Get is an http handler used to get a package details.
 func (h *Handlers) Get(w http.ResponseWriter, r *http.Request) {
 	input := &hub.GetPackageInput{
+		PackageKind: chi.URLParam(r, "kind"),
 		PackageName: chi.URLParam(r, "packageName"),
 		Version:     chi.URLParam(r, "version"),
 	}
@@ -109,12 +178,61 @@ func (h *Handlers) GetUpdates(w http.ResponseWriter, r *http.Request) {
 	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge)
 }
 
+// GetValues is an http handler used to get the default values of a chart
+// package version.
+func (h *Handlers) GetValues(w http.ResponseWriter, r *http.Request) {
+	input := &hub.GetPackageInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+	values, err := h.pkgManager.GetValues(r.Context(), input)
+	if err != nil {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "GetValues").Send()
+		if errors.Is(err, pkg.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, pkg.ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.Header().Set("Cache-Control", helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge))
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(values)
+}
+
+// GetValuesSchema is an http handler used to get the values schema of a chart
+// package version.
+func (h *Handlers) GetValuesSchema(w http.ResponseWriter, r *http.Request) {
+	input := &hub.GetPackageInput{
+		ChartRepositoryName: chi.URLParam(r, "repoName"),
+		PackageName:         chi.URLParam(r, "packageName"),
+		Version:             chi.URLParam(r, "version"),
+	}
+	dataJSON, err := h.pkgManager.GetValuesSchemaJSON(r.Context(), input)
+	if err != nil {
+		h.logger.Error().Err(err).Interface("input", input).Str("method", "GetValuesSchema").Send()
+		if errors.Is(err, pkg.ErrInvalidInput) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else if errors.Is(err, pkg.ErrNotFound) {
+			http.NotFound(w, r)
+		} else {
+			http.Error(w, "", http.StatusInternalServerError)
+		}
+		return
+	}
+	helpers.RenderJSON(w, dataJSON, helpers.DefaultAPICacheMaxAge)
+}
+
 // InjectIndexMeta is a middleware that injects the some index metadata related
 // to a given package,
 func (h *Handlers) InjectIndexMeta(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Prepare index metadata from package details
 		input := &hub.GetPackageInput{
+			PackageKind: chi.URLParam(r, "kind"),
 			PackageName: chi.URLParam(r, "packageName"),
 			Version:     chi.URLParam(r, "version"),
 		}
@@ -246,6 +364,42 @@ func buildSearchInput(qs url.Values) (*hub.SearchPackageInput, error) {
 		}
 	}
 
+	// Only signed packages
+	var signed bool
+	if qs.Get("signed") != "" {
+		var err error
+		signed, err = strconv.ParseBool(qs.Get("signed"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid signed: %s", qs.Get("signed"))
+		}
+	}
+
+	// Only packages without lint errors or warnings
+	var lintClean bool
+	if qs.Get("lint_clean") != "" {
+		var err error
+		lintClean, err = strconv.ParseBool(qs.Get("lint_clean"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid lint_clean: %s", qs.Get("lint_clean"))
+		}
+	}
+
+	// Include packages duplicated across repositories
+	var includeDuplicates bool
+	if qs.Get("include_duplicates") != "" {
+		var err error
+		includeDuplicates, err = strconv.ParseBool(qs.Get("include_duplicates"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid include_duplicates: %s", qs.Get("include_duplicates"))
+		}
+	}
+
+	// Chart types
+	chartTypes := make([]hub.ChartType, 0, len(qs["chart_type"]))
+	for _, chartType := range qs["chart_type"] {
+		chartTypes = append(chartTypes, hub.ChartType(chartType))
+	}
+
 	return &hub.SearchPackageInput{
 		Limit:             limit,
 		Offset:            offset,
@@ -256,5 +410,13 @@ func buildSearchInput(qs url.Values) (*hub.SearchPackageInput, error) {
 		Orgs:              qs["org"],
 		ChartRepositories: qs["repo"],
 		Deprecated:        deprecated,
+		Signed:            signed,
+		LintClean:         lintClean,
+		ResourceKinds:     qs["resource_kind"],
+		CRDs:              qs["crd"],
+		Images:            qs["image"],
+		ChartTypes:        chartTypes,
+		KubeVersion:       qs.Get("kube_version"),
+		IncludeDuplicates: includeDuplicates,
 	}, nil
 }
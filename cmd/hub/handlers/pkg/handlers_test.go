@@ -82,6 +82,291 @@ func TestGet(t *testing.T) {
 	})
 }
 
+func TestGetChangelog(t *testing.T) {
+	t.Run("get changelog failed", func(t *testing.T) {
+		testCases := []struct {
+			pmErr              error
+			expectedStatusCode int
+		}{
+			{
+				pkg.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				pkg.ErrNotFound,
+				http.StatusNotFound,
+			},
+			{
+				tests.ErrFakeDatabaseFailure,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.pmErr.Error(), func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.pm.On("GetChangelogJSON", mock.Anything, mock.Anything).Return(nil, tc.pmErr)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				hw.h.GetChangelog(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.pm.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("get changelog succeeded", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.pm.On("GetChangelogJSON", mock.Anything, mock.Anything).Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetChangelog(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.pm.AssertExpectations(t)
+	})
+}
+
+func TestGetDependencies(t *testing.T) {
+	t.Run("get dependencies failed", func(t *testing.T) {
+		testCases := []struct {
+			pmErr              error
+			expectedStatusCode int
+		}{
+			{
+				pkg.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				pkg.ErrNotFound,
+				http.StatusNotFound,
+			},
+			{
+				tests.ErrFakeDatabaseFailure,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.pmErr.Error(), func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.pm.On("GetDependenciesJSON", mock.Anything, mock.Anything).Return(nil, tc.pmErr)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				hw.h.GetDependencies(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.pm.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("get dependencies succeeded", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.pm.On("GetDependenciesJSON", mock.Anything, mock.Anything).Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetDependencies(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.pm.AssertExpectations(t)
+	})
+}
+
+func TestGetDependents(t *testing.T) {
+	t.Run("get dependents failed", func(t *testing.T) {
+		testCases := []struct {
+			pmErr              error
+			expectedStatusCode int
+		}{
+			{
+				pkg.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				pkg.ErrNotFound,
+				http.StatusNotFound,
+			},
+			{
+				tests.ErrFakeDatabaseFailure,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.pmErr.Error(), func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.pm.On("GetDependentsJSON", mock.Anything, mock.Anything).Return(nil, tc.pmErr)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				hw.h.GetDependents(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.pm.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("get dependents succeeded", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.pm.On("GetDependentsJSON", mock.Anything, mock.Anything).Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetDependents(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.pm.AssertExpectations(t)
+	})
+}
+
+func TestGetValues(t *testing.T) {
+	t.Run("get values failed", func(t *testing.T) {
+		testCases := []struct {
+			pmErr              error
+			expectedStatusCode int
+		}{
+			{
+				pkg.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				pkg.ErrNotFound,
+				http.StatusNotFound,
+			},
+			{
+				tests.ErrFakeDatabaseFailure,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.pmErr.Error(), func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.pm.On("GetValues", mock.Anything, mock.Anything).Return(nil, tc.pmErr)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				hw.h.GetValues(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.pm.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("get values succeeded", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.pm.On("GetValues", mock.Anything, mock.Anything).Return([]byte("key: value"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetValues(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/yaml", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("key: value"), data)
+		hw.pm.AssertExpectations(t)
+	})
+}
+
+func TestGetValuesSchema(t *testing.T) {
+	t.Run("get values schema failed", func(t *testing.T) {
+		testCases := []struct {
+			pmErr              error
+			expectedStatusCode int
+		}{
+			{
+				pkg.ErrInvalidInput,
+				http.StatusBadRequest,
+			},
+			{
+				pkg.ErrNotFound,
+				http.StatusNotFound,
+			},
+			{
+				tests.ErrFakeDatabaseFailure,
+				http.StatusInternalServerError,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.pmErr.Error(), func(t *testing.T) {
+				hw := newHandlersWrapper()
+				hw.pm.On("GetValuesSchemaJSON", mock.Anything, mock.Anything).Return(nil, tc.pmErr)
+
+				w := httptest.NewRecorder()
+				r, _ := http.NewRequest("GET", "/", nil)
+				hw.h.GetValuesSchema(w, r)
+				resp := w.Result()
+				defer resp.Body.Close()
+
+				assert.Equal(t, tc.expectedStatusCode, resp.StatusCode)
+				hw.pm.AssertExpectations(t)
+			})
+		}
+	})
+
+	t.Run("get values schema succeeded", func(t *testing.T) {
+		hw := newHandlersWrapper()
+		hw.pm.On("GetValuesSchemaJSON", mock.Anything, mock.Anything).Return([]byte("dataJSON"), nil)
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		hw.h.GetValuesSchema(w, r)
+		resp := w.Result()
+		defer resp.Body.Close()
+		h := resp.Header
+		data, _ := ioutil.ReadAll(resp.Body)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/json", h.Get("Content-Type"))
+		assert.Equal(t, helpers.BuildCacheControlHeader(helpers.DefaultAPICacheMaxAge), h.Get("Cache-Control"))
+		assert.Equal(t, []byte("dataJSON"), data)
+		hw.pm.AssertExpectations(t)
+	})
+}
+
 func TestGetStarredByUser(t *testing.T) {
 	t.Run("get packages starred by user succeeded", func(t *testing.T) {
 		hw := newHandlersWrapper()
@@ -369,6 +654,9 @@ func TestSearch(t *testing.T) {
 			{"invalid kind", "kind=z"},
 			{"invalid kind (one of them)", "kind=0&kind=z"},
 			{"invalid deprecated", "deprecated=z"},
+			{"invalid signed", "signed=z"},
+			{"invalid lint_clean", "lint_clean=z"},
+			{"invalid include_duplicates", "include_duplicates=z"},
 		}
 		for _, tc := range testCases {
 			tc := tc
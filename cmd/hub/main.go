@@ -41,14 +41,21 @@ func main() {
 	if s := email.NewSender(cfg); s != nil {
 		es = s
 	}
+	credentialsKey := cfg.GetString("credentials.encryptionKey")
 	svc := &handlers.Services{
 		OrganizationManager:    org.NewManager(db, es),
 		UserManager:            user.NewManager(db, es),
 		PackageManager:         pkg.NewManager(db),
-		ChartRepositoryManager: chartrepo.NewManager(db),
+		ChartRepositoryManager: chartrepo.NewManager(db, chartrepo.WithCredentialsKey(credentialsKey)),
 		ImageStore:             pg.NewImageStore(db),
 	}
 
+	// Load the package kinds registered, used to setup the packages routes
+	kinds, err := svc.PackageManager.GetKinds(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("Package kinds loading failed")
+	}
+
 	// Setup and launch server
 	addr := cfg.GetString("server.addr")
 	srv := &http.Server{
@@ -56,7 +63,7 @@ func main() {
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  1 * time.Minute,
-		Handler:      handlers.Setup(cfg, svc).Router,
+		Handler:      handlers.Setup(cfg, svc, kinds).Router,
 	}
 	go func() {
 		if err := srv.ListenAndServe(); err != http.ErrServerClosed {
@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"image"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -22,6 +23,7 @@ import (
 
 var basePath = flag.String("base-path", ".", "Path containing SecurityHub yaml files to process")
 var orgID = flag.String("org-id", "", "ID of the organization that will own the packages added")
+var dryRun = flag.Bool("dry-run", false, "Print the changes planned without applying them")
 
 func main() {
 	flag.Parse()
@@ -52,6 +54,7 @@ func main() {
 	}
 
 	// Walk the path provided looking for SecurityHub yaml files to process
+	var available []*hub.Package
 	err = filepath.Walk(*basePath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -62,11 +65,21 @@ func main() {
 		if !strings.HasSuffix(info.Name(), "yaml") {
 			return nil
 		}
-		return r.registerPackage(*orgID, *basePath, path)
+		p, err := loadPackage(*orgID, *basePath, path)
+		if err != nil {
+			return err
+		}
+		available = append(available, p)
+		return nil
 	})
 	if err != nil {
 		log.Fatal().Err(err).Msg("Error processing SecurityHub yaml files")
 	}
+
+	// Register the packages available and unregister the stale ones
+	if err := r.sync(*orgID, available, *dryRun, os.Stdout); err != nil {
+		log.Fatal().Err(err).Msg("Error syncing SecurityHub packages")
+	}
 }
 
 type SecurityHubEntry struct {
@@ -95,68 +108,139 @@ type SecurityHubRegistrar struct {
 	imageStore img.Store
 }
 
-func (r *SecurityHubRegistrar) registerPackage(orgID, basePath, pkgPath string) error {
-	// Parse SecurityHub entry in yaml file
-	data, err := ioutil.ReadFile(pkgPath)
+// sync registers the packages available and unregisters the versions of the
+// packages owned by the organization that are no longer available. When dry
+// run is enabled, the changes planned are printed to the writer provided and
+// nothing is applied.
+func (r *SecurityHubRegistrar) sync(orgID string, available []*hub.Package, dryRun bool, out io.Writer) error {
+	registered, err := r.pkgManager.GetOrgPackagesVersions(r.ctx, orgID)
 	if err != nil {
 		return err
 	}
-	var e *SecurityHubEntry
-	if err = yaml.Unmarshal(data, &e); err != nil {
-		return err
+	stale := stalePackages(registered, available)
+	if dryRun {
+		printPlannedChanges(out, available, stale)
+		return nil
+	}
+	for _, p := range available {
+		if err := r.registerPackage(p); err != nil {
+			return err
+		}
+	}
+	for _, p := range stale {
+		log.Info().Str("name", p.Name).Str("version", p.Version).Msg("unregistering stale package version")
+		if err := r.pkgManager.Unregister(r.ctx, p); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
+func (r *SecurityHubRegistrar) registerPackage(p *hub.Package) error {
 	// Register logo image if needed
-	var logoURL, logoImageID string
-	if e.Icon != "" {
-		logoURL = e.Icon
-		data, err := downloadImage(logoURL)
+	if p.LogoURL != "" {
+		data, err := downloadImage(p.LogoURL)
 		if err != nil {
 			return err
 		}
-		logoImageID, err = r.imageStore.SaveImage(r.ctx, data)
+		p.LogoImageID, err = r.imageStore.SaveImage(r.ctx, data)
 		if err != nil && !errors.Is(err, image.ErrFormat) {
 			return err
 		}
 	}
 
+	return r.pkgManager.Register(r.ctx, p)
+}
+
+// loadPackage builds the package described by the SecurityHub yaml file
+// located at the path provided.
+func loadPackage(orgID, basePath, pkgPath string) (*hub.Package, error) {
+	// Parse SecurityHub entry in yaml file
+	data, err := ioutil.ReadFile(pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	var e *SecurityHubEntry
+	if err = yaml.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e == nil {
+		return nil, fmt.Errorf("empty SecurityHub entry: %s", pkgPath)
+	}
+
 	// Build package to register
 	p := &hub.Package{
 		Name:           e.Name,
-		LogoURL:        logoURL,
-		LogoImageID:    logoImageID,
+		LogoURL:        e.Icon,
 		Description:    e.ShortDescription,
 		Keywords:       e.Keywords,
 		Version:        e.Version,
 		Readme:         e.Description,
 		OrganizationID: orgID,
 	}
+	yamlFile := strings.TrimPrefix(pkgPath, basePath)
+	sourceURL := fmt.Sprintf("https://github.com/falcosecurity/cloud-native-security-hub/blob/master/resources%s", yamlFile)
 	switch e.Kind {
 	case "FalcoRules":
-		yamlFile := strings.TrimPrefix(pkgPath, basePath)
-		sourceURL := fmt.Sprintf("https://github.com/falcosecurity/cloud-native-security-hub/blob/master/resources%s", yamlFile)
 		p.Kind = hub.Falco
-		p.Links = []*hub.Link{
-			{
-				Name: "source",
-				URL:  sourceURL,
-			},
-		}
 		p.Data = map[string]interface{}{"rules": e.Rules}
 	case "OpenPolicyAgentPolicies":
-		yamlFile := strings.TrimPrefix(pkgPath, basePath)
-		sourceURL := fmt.Sprintf("https://github.com/falcosecurity/cloud-native-security-hub/blob/master/resources%s", yamlFile)
 		p.Kind = hub.OPA
-		p.Links = []*hub.Link{
-			{
-				Name: "source",
-				URL:  sourceURL,
-			},
-		}
 		p.Data = map[string]interface{}{"policies": e.Policies}
+	default:
+		return nil, fmt.Errorf("invalid SecurityHub entry kind %q: %s", e.Kind, pkgPath)
 	}
+	p.Links = []*hub.Link{
+		{
+			Name: "source",
+			URL:  sourceURL,
+		},
+	}
+	return p, nil
+}
 
-	return r.pkgManager.Register(r.ctx, p)
+// stalePackages returns the registered packages versions that are not
+// available anymore.
+func stalePackages(registered, available []*hub.Package) []*hub.Package {
+	availableKeys := make(map[string]struct{}, len(available))
+	for _, p := range available {
+		availableKeys[packageKey(p)] = struct{}{}
+	}
+	var stale []*hub.Package
+	for _, p := range registered {
+		if _, ok := availableKeys[packageKey(p)]; !ok {
+			stale = append(stale, p)
+		}
+	}
+	return stale
+}
+
+// packageKey returns the key that identifies the package version provided.
+func packageKey(p *hub.Package) string {
+	return fmt.Sprintf("%d/%s@%s", p.Kind, p.Name, p.Version)
+}
+
+// printPlannedChanges prints the packages versions that would be registered
+// and unregistered, one per line.
+func printPlannedChanges(out io.Writer, register, unregister []*hub.Package) {
+	for _, p := range register {
+		fmt.Fprintf(out, "register\t%s\t%s\t%s\n", kindSlug(p.Kind), p.Name, p.Version)
+	}
+	for _, p := range unregister {
+		fmt.Fprintf(out, "unregister\t%s\t%s\t%s\n", kindSlug(p.Kind), p.Name, p.Version)
+	}
+}
+
+// kindSlug returns the slug of the SecurityHub package kind provided.
+func kindSlug(kind hub.PackageKind) string {
+	switch kind {
+	case hub.Falco:
+		return "falco"
+	case hub.OPA:
+		return "opa"
+	default:
+		return fmt.Sprintf("%d", kind)
+	}
 }
 
 func downloadImage(u string) ([]byte, error) {
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/img"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testOrgID = "00000000-0000-0000-0000-000000000001"
+
+func TestLoadPackage(t *testing.T) {
+	t.Run("falco rules loaded", func(t *testing.T) {
+		p, err := loadPackage(testOrgID, "testdata", "testdata/falco/nginx.yaml")
+		require.NoError(t, err)
+		assert.Equal(t, hub.Falco, p.Kind)
+		assert.Equal(t, "Nginx", p.Name)
+		assert.Equal(t, "1.0.0", p.Version)
+		assert.Equal(t, testOrgID, p.OrganizationID)
+		assert.Equal(t, "https://github.com/falcosecurity/cloud-native-security-hub/blob/master/resources/falco/nginx.yaml", p.Links[0].URL)
+	})
+
+	t.Run("invalid kind", func(t *testing.T) {
+		_, err := loadPackage(testOrgID, "testdata", "testdata/invalid-kind.yaml")
+		assert.Error(t, err)
+	})
+}
+
+func TestSync(t *testing.T) {
+	available := []*hub.Package{
+		{Kind: hub.Falco, Name: "pkg1", Version: "1.0.0", OrganizationID: testOrgID},
+	}
+	registered := []*hub.Package{
+		{Kind: hub.Falco, Name: "pkg1", Version: "1.0.0", OrganizationID: testOrgID},
+		{Kind: hub.Falco, Name: "pkg1", Version: "0.9.0", OrganizationID: testOrgID},
+		{Kind: hub.OPA, Name: "pkg1", Version: "1.0.0", OrganizationID: testOrgID},
+	}
+	newRegistrar := func() (*SecurityHubRegistrar, *pkg.ManagerMock) {
+		pm := &pkg.ManagerMock{}
+		return &SecurityHubRegistrar{
+			ctx:        context.Background(),
+			pkgManager: pm,
+			imageStore: &img.StoreMock{},
+		}, pm
+	}
+
+	t.Run("error getting registered packages", func(t *testing.T) {
+		r, pm := newRegistrar()
+		pm.On("GetOrgPackagesVersions", mock.Anything, testOrgID).Return(nil, assert.AnError)
+
+		err := r.sync(testOrgID, available, false, &bytes.Buffer{})
+		assert.Equal(t, assert.AnError, err)
+		pm.AssertExpectations(t)
+	})
+
+	t.Run("available packages registered and stale ones unregistered", func(t *testing.T) {
+		r, pm := newRegistrar()
+		pm.On("GetOrgPackagesVersions", mock.Anything, testOrgID).Return(registered, nil)
+		pm.On("Register", mock.Anything, available[0]).Return(nil)
+		pm.On("Unregister", mock.Anything, registered[1]).Return(nil)
+		pm.On("Unregister", mock.Anything, registered[2]).Return(nil)
+
+		err := r.sync(testOrgID, available, false, &bytes.Buffer{})
+		assert.NoError(t, err)
+		pm.AssertExpectations(t)
+	})
+
+	t.Run("dry run prints planned changes without applying them", func(t *testing.T) {
+		r, pm := newRegistrar()
+		pm.On("GetOrgPackagesVersions", mock.Anything, testOrgID).Return(registered, nil)
+
+		var out bytes.Buffer
+		err := r.sync(testOrgID, available, true, &out)
+		assert.NoError(t, err)
+		assert.Equal(t, "register\tfalco\tpkg1\t1.0.0\n"+
+			"unregister\tfalco\tpkg1\t0.9.0\n"+
+			"unregister\topa\tpkg1\t1.0.0\n", out.String())
+		pm.AssertExpectations(t)
+	})
+}
@@ -16,14 +16,22 @@
 {{ template "users/verify_email.sql" }}
 
 {{ template "packages/generate_package_tsdoc.sql" }}
+{{ template "packages/get_org_packages_versions.sql" }}
 {{ template "packages/get_package.sql" }}
+{{ template "packages/get_package_changelog.sql" }}
+{{ template "packages/get_package_dependencies.sql" }}
+{{ template "packages/get_package_dependents.sql" }}
+{{ template "packages/get_package_kinds.sql" }}
 {{ template "packages/get_packages_starred_by_user.sql" }}
 {{ template "packages/get_package_stars.sql" }}
+{{ template "packages/get_package_values.sql" }}
+{{ template "packages/get_package_values_schema.sql" }}
 {{ template "packages/get_packages_stats.sql" }}
 {{ template "packages/get_packages_updates.sql" }}
 {{ template "packages/register_package.sql" }}
 {{ template "packages/search_packages.sql" }}
 {{ template "packages/semver_gte.sql" }}
+{{ template "packages/semver_satisfies.sql" }}
 {{ template "packages/toggle_star.sql" }}
 {{ template "packages/unregister_package.sql" }}
 
@@ -31,9 +39,14 @@
 {{ template "chart_repositories/delete_chart_repository.sql" }}
 {{ template "chart_repositories/get_chart_repositories.sql" }}
 {{ template "chart_repositories/get_chart_repository_by_name.sql" }}
+{{ template "chart_repositories/get_chart_repository_lint_summary.sql" }}
 {{ template "chart_repositories/get_chart_repository_packages_digest.sql" }}
+{{ template "chart_repositories/get_chart_repository_sync_request.sql" }}
+{{ template "chart_repositories/get_chart_repository_tracking_runs.sql" }}
 {{ template "chart_repositories/get_org_chart_repositories.sql" }}
 {{ template "chart_repositories/get_user_chart_repositories.sql" }}
+{{ template "chart_repositories/register_tracking_run.sql" }}
+{{ template "chart_repositories/request_chart_repository_sync.sql" }}
 {{ template "chart_repositories/update_chart_repository.sql" }}
 
 {{ template "images/get_image.sql" }}
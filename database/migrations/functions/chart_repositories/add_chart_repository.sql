@@ -18,15 +18,25 @@ begin
     end if;
 
     insert into chart_repository (
+        package_kind_id,
         name,
         display_name,
         url,
+        credentials,
+        tls_config,
+        keyring,
+        tracking_interval,
         user_id,
         organization_id
     ) values (
+        coalesce((p_chart_repository->>'kind')::int, 0),
         p_chart_repository->>'name',
         nullif(p_chart_repository->>'display_name', ''),
         p_chart_repository->>'url',
+        nullif(p_chart_repository->>'credentials', ''),
+        nullif(p_chart_repository->'tls_config', '{}'),
+        nullif(p_chart_repository->>'keyring', ''),
+        nullif((p_chart_repository->>'tracking_interval')::int, 0) * interval '1 second',
         v_owner_user_id,
         v_owner_organization_id
     );
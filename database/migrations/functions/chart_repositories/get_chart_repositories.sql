@@ -1,12 +1,24 @@
 -- get_chart_repositories returns all available chart repositories as a json
--- array.
+-- array. The keyring returned includes the public keys registered in the
+-- repository and in the organization owning it, if any.
 create or replace function get_chart_repositories()
 returns setof json as $$
     select coalesce(json_agg(json_build_object(
-        'chart_repository_id', chart_repository_id,
-        'name', name,
-        'display_name', display_name,
-        'url', url
+        'chart_repository_id', cr.chart_repository_id,
+        'kind', cr.package_kind_id,
+        'name', cr.name,
+        'display_name', cr.display_name,
+        'url', cr.url,
+        'last_index_info', json_build_object(
+            'digest', cr.last_index_digest,
+            'etag', cr.last_index_etag,
+            'last_modified', cr.last_index_last_modified
+        ),
+        'tracking_interval', extract(epoch from cr.tracking_interval)::int,
+        'credentials', cr.credentials,
+        'tls_config', cr.tls_config,
+        'keyring', nullif(concat_ws(E'\n', cr.keyring, o.keyring), '')
     )), '[]')
-    from chart_repository;
+    from chart_repository cr
+    left join organization o using (organization_id);
 $$ language sql;
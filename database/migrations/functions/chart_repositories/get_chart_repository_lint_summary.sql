@@ -0,0 +1,44 @@
+-- get_chart_repository_lint_summary returns a summary of the lint results of
+-- the latest version of the packages in the provided chart repository as a
+-- json object. Packages with errors or warnings are listed, most problematic
+-- first.
+create or replace function get_chart_repository_lint_summary(p_chart_repository_name text)
+returns setof json as $$
+    with packages_lint as (
+        select
+            p.name,
+            p.normalized_name,
+            s.version,
+            s.lint,
+            coalesce(jsonb_array_length(s.lint->'errors'), 0) as errors,
+            coalesce(jsonb_array_length(s.lint->'warnings'), 0) as warnings
+        from package p
+        join snapshot s using (package_id)
+        join chart_repository r using (chart_repository_id)
+        where r.name = p_chart_repository_name
+        and s.version = p.latest_version
+    )
+    select json_build_object(
+        'packages', (select count(*) from packages_lint),
+        'linted', (select count(*) from packages_lint where lint is not null),
+        'clean', (
+            select count(*) from packages_lint
+            where lint is not null and errors = 0 and warnings = 0
+        ),
+        'with_errors', (select count(*) from packages_lint where errors > 0),
+        'with_warnings', (select count(*) from packages_lint where errors = 0 and warnings > 0),
+        'packages_with_issues', (
+            select coalesce(json_agg(json_build_object(
+                'name', name,
+                'normalized_name', normalized_name,
+                'version', version,
+                'errors', errors,
+                'warnings', warnings
+            ) order by errors desc, warnings desc, name asc), '[]')
+            from packages_lint
+            where errors > 0 or warnings > 0
+        )
+    )
+    from chart_repository
+    where name = p_chart_repository_name;
+$$ language sql;
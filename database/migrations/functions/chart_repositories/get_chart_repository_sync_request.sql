@@ -0,0 +1,43 @@
+-- get_chart_repository_sync_request returns the status of the provided sync
+-- request of a chart repository as a json object.
+create or replace function get_chart_repository_sync_request(
+    p_user_id uuid,
+    p_chart_repository_name text,
+    p_sync_request_id uuid
+)
+returns setof json as $$
+declare
+    v_owner_user_id uuid;
+    v_owner_organization_name text;
+begin
+    -- Get user or organization owning the chart repository
+    select cr.user_id, o.name into v_owner_user_id, v_owner_organization_name
+    from chart_repository cr
+    left join organization o using (organization_id)
+    where cr.name = p_chart_repository_name;
+
+    -- Check if the user doing the request is the owner or belongs to the
+    -- organization which owns it
+    if v_owner_organization_name is not null then
+        if not user_belongs_to_organization(p_user_id, v_owner_organization_name) then
+            raise insufficient_privilege;
+        end if;
+    elsif v_owner_user_id is null or v_owner_user_id <> p_user_id then
+        raise insufficient_privilege;
+    end if;
+
+    return query
+    select json_build_object(
+        'sync_request_id', sr.chart_repository_sync_request_id,
+        'status', sr.status,
+        'errors', sr.errors,
+        'created_at', floor(extract(epoch from sr.created_at)),
+        'started_at', floor(extract(epoch from sr.started_at)),
+        'finished_at', floor(extract(epoch from sr.finished_at))
+    )
+    from chart_repository_sync_request sr
+    join chart_repository cr using (chart_repository_id)
+    where cr.name = p_chart_repository_name
+    and sr.chart_repository_sync_request_id = p_sync_request_id;
+end
+$$ language plpgsql;
@@ -0,0 +1,49 @@
+-- get_chart_repository_tracking_runs returns the most recent tracking runs of
+-- the provided chart repository as a json array.
+create or replace function get_chart_repository_tracking_runs(
+    p_user_id uuid,
+    p_chart_repository_name text,
+    p_limit int
+)
+returns setof json as $$
+declare
+    v_chart_repository_id uuid;
+    v_owner_user_id uuid;
+    v_owner_organization_name text;
+begin
+    -- Get user or organization owning the chart repository
+    select cr.chart_repository_id, cr.user_id, o.name
+    into v_chart_repository_id, v_owner_user_id, v_owner_organization_name
+    from chart_repository cr
+    left join organization o using (organization_id)
+    where cr.name = p_chart_repository_name;
+
+    -- Check if the user doing the request is the owner or belongs to the
+    -- organization which owns it
+    if v_owner_organization_name is not null then
+        if not user_belongs_to_organization(p_user_id, v_owner_organization_name) then
+            raise insufficient_privilege;
+        end if;
+    elsif v_owner_user_id is null or v_owner_user_id <> p_user_id then
+        raise insufficient_privilege;
+    end if;
+
+    return query
+    select coalesce(json_agg(json_build_object(
+        'tracking_run_id', tracking_run_id,
+        'started_at', floor(extract(epoch from started_at)),
+        'finished_at', floor(extract(epoch from finished_at)),
+        'versions_registered', versions_registered,
+        'versions_unregistered', versions_unregistered,
+        'versions_skipped', versions_skipped,
+        'errors', errors
+    ) order by started_at desc), '[]')
+    from (
+        select *
+        from tracking_run
+        where chart_repository_id = v_chart_repository_id
+        order by started_at desc
+        limit p_limit
+    ) tr;
+end
+$$ language plpgsql;
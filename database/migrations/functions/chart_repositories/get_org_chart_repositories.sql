@@ -5,9 +5,12 @@ create or replace function get_org_chart_repositories(p_user_id uuid, p_org_name
 returns setof json as $$
     select coalesce(json_agg(json_build_object(
         'chart_repository_id', cr.chart_repository_id,
+        'kind', cr.package_kind_id,
         'name', cr.name,
         'display_name', cr.display_name,
         'url', cr.url,
+        'keyring', cr.keyring,
+        'tracking_interval', extract(epoch from cr.tracking_interval)::int,
         'last_tracking_ts', floor(extract(epoch from cr.last_tracking_ts)),
         'last_tracking_errors', cr.last_tracking_errors
     )), '[]')
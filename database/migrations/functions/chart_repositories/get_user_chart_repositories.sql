@@ -4,9 +4,12 @@ create or replace function get_user_chart_repositories(p_user_id uuid)
 returns setof json as $$
     select coalesce(json_agg(json_build_object(
         'chart_repository_id', chart_repository_id,
+        'kind', package_kind_id,
         'name', name,
         'display_name', display_name,
         'url', url,
+        'keyring', keyring,
+        'tracking_interval', extract(epoch from tracking_interval)::int,
         'last_tracking_ts', floor(extract(epoch from last_tracking_ts)),
         'last_tracking_errors', last_tracking_errors
     )), '[]')
@@ -0,0 +1,37 @@
+-- register_tracking_run registers the provided tracking run of a chart
+-- repository. Only the most recent 100 runs of each chart repository are
+-- kept, older ones are deleted.
+create or replace function register_tracking_run(p_tracking_run jsonb)
+returns void as $$
+declare
+    v_chart_repository_id uuid := p_tracking_run->>'chart_repository_id';
+begin
+    insert into tracking_run (
+        chart_repository_id,
+        started_at,
+        finished_at,
+        versions_registered,
+        versions_unregistered,
+        versions_skipped,
+        errors
+    ) values (
+        v_chart_repository_id,
+        to_timestamp((p_tracking_run->>'started_at')::bigint),
+        to_timestamp((p_tracking_run->>'finished_at')::bigint),
+        coalesce((p_tracking_run->>'versions_registered')::integer, 0),
+        coalesce((p_tracking_run->>'versions_unregistered')::integer, 0),
+        coalesce((p_tracking_run->>'versions_skipped')::integer, 0),
+        nullif(p_tracking_run->'errors', 'null')
+    );
+
+    delete from tracking_run
+    where chart_repository_id = v_chart_repository_id
+    and tracking_run_id not in (
+        select tracking_run_id
+        from tracking_run
+        where chart_repository_id = v_chart_repository_id
+        order by started_at desc
+        limit 100
+    );
+end
+$$ language plpgsql;
@@ -0,0 +1,43 @@
+-- request_chart_repository_sync registers a request to track the provided
+-- chart repository as soon as possible, returning the id of the sync request.
+-- If there is already a pending request for the chart repository, its id is
+-- returned instead of registering a new one.
+create or replace function request_chart_repository_sync(p_user_id uuid, p_chart_repository_name text)
+returns uuid as $$
+declare
+    v_chart_repository_id uuid;
+    v_owner_user_id uuid;
+    v_owner_organization_name text;
+    v_sync_request_id uuid;
+begin
+    -- Get user or organization owning the chart repository
+    select cr.chart_repository_id, cr.user_id, o.name
+    into v_chart_repository_id, v_owner_user_id, v_owner_organization_name
+    from chart_repository cr
+    left join organization o using (organization_id)
+    where cr.name = p_chart_repository_name;
+
+    -- Check if the user doing the request is the owner or belongs to the
+    -- organization which owns it
+    if v_owner_organization_name is not null then
+        if not user_belongs_to_organization(p_user_id, v_owner_organization_name) then
+            raise insufficient_privilege;
+        end if;
+    elsif v_owner_user_id is null or v_owner_user_id <> p_user_id then
+        raise insufficient_privilege;
+    end if;
+
+    -- Reuse pending sync request if available, otherwise register a new one
+    select chart_repository_sync_request_id into v_sync_request_id
+    from chart_repository_sync_request
+    where chart_repository_id = v_chart_repository_id
+    and status = 'pending';
+    if v_sync_request_id is null then
+        insert into chart_repository_sync_request (chart_repository_id, user_id)
+        values (v_chart_repository_id, p_user_id)
+        returning chart_repository_sync_request_id into v_sync_request_id;
+    end if;
+
+    return v_sync_request_id;
+end
+$$ language plpgsql;
@@ -22,9 +22,26 @@ begin
         raise insufficient_privilege;
     end if;
 
+    -- Update chart repository, resetting the last index information when the
+    -- url changes so that it is processed from scratch in the next tracking.
+    -- Credentials and TLS settings are kept when not provided, unless the url
+    -- changes.
     update chart_repository set
         display_name = nullif(p_chart_repository->>'display_name', ''),
-        url = p_chart_repository->>'url'
+        url = p_chart_repository->>'url',
+        keyring = nullif(p_chart_repository->>'keyring', ''),
+        tracking_interval = nullif((p_chart_repository->>'tracking_interval')::int, 0) * interval '1 second',
+        credentials = case
+            when p_chart_repository ? 'credentials' then nullif(p_chart_repository->>'credentials', '')
+            when url = p_chart_repository->>'url' then credentials
+        end,
+        tls_config = case
+            when p_chart_repository ? 'tls_config' then nullif(p_chart_repository->'tls_config', '{}')
+            when url = p_chart_repository->>'url' then tls_config
+        end,
+        last_index_digest = case when url = p_chart_repository->>'url' then last_index_digest end,
+        last_index_etag = case when url = p_chart_repository->>'url' then last_index_etag end,
+        last_index_last_modified = case when url = p_chart_repository->>'url' then last_index_last_modified end
     where name = p_chart_repository->>'name';
 end
 $$ language plpgsql;
@@ -6,7 +6,8 @@ returns setof json as $$
         'display_name', o.display_name,
         'description', o.description,
         'home_url', o.home_url,
-        'logo_image_id', o.logo_image_id
+        'logo_image_id', o.logo_image_id,
+        'keyring', o.keyring
     )
     from organization o
     where o.name = p_org_name;
@@ -11,7 +11,8 @@ begin
         display_name = nullif(p_org->>'display_name', ''),
         description = nullif(p_org->>'description', ''),
         home_url = nullif(p_org->>'home_url', ''),
-        logo_image_id = nullif(p_org->>'logo_image_id', '')::uuid
+        logo_image_id = nullif(p_org->>'logo_image_id', '')::uuid,
+        keyring = nullif(p_org->>'keyring', '')
     where name = p_org->>'name';
 end
 $$ language plpgsql;
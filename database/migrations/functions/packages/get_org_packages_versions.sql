@@ -0,0 +1,15 @@
+-- get_org_packages_versions returns the versions of the packages owned by the
+-- organization provided that don't belong to any chart repository.
+create or replace function get_org_packages_versions(p_organization_id uuid)
+returns setof json as $$
+    select coalesce(json_agg(json_build_object(
+        'kind', p.package_kind_id,
+        'name', p.name,
+        'version', s.version,
+        'organization_id', p.organization_id
+    ) order by p.package_kind_id, p.name, s.version), '[]')
+    from package p
+    join snapshot s using (package_id)
+    where p.organization_id = p_organization_id
+    and p.chart_repository_id is null;
+$$ language sql;
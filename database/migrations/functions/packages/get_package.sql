@@ -6,6 +6,7 @@ declare
     v_package_id uuid;
     v_package_name text := p_input->>'package_name';
     v_chart_repository_name text := p_input->>'chart_repository_name';
+    v_package_kind text := p_input->>'package_kind';
 begin
     if v_chart_repository_name <> '' then
         select p.package_id into v_package_id
@@ -14,10 +15,12 @@ begin
         where r.name = v_chart_repository_name
         and p.normalized_name = v_package_name;
     else
-        select package_id into v_package_id
-        from package
-        where normalized_name = v_package_name
-        and chart_repository_id is null;
+        select p.package_id into v_package_id
+        from package p
+        join package_kind pk using (package_kind_id)
+        where p.normalized_name = v_package_name
+        and p.chart_repository_id is null
+        and (v_package_kind is null or pk.slug = v_package_kind);
     end if;
 
     return query
@@ -43,7 +46,22 @@ begin
         'app_version', s.app_version,
         'digest', s.digest,
         'deprecated', s.deprecated,
-        'maintainers', (
+        'signed', coalesce(s.signature_status in ('signed', 'verified'), false),
+        'signature_status', s.signature_status,
+        'signature_key_fingerprint', s.signature_key_fingerprint,
+        'templates', s.templates,
+        'images', s.images,
+        'lint', s.lint,
+        'annotations', s.annotations,
+        'kube_version', s.kube_version,
+        'chart_type', s.chart_type,
+        'sources', s.sources,
+        'api_version', s.api_version,
+        'license', s.license,
+        'changes', s.changes,
+        'prerelease', s.prerelease,
+        'contains_security_updates', s.contains_security_updates,
+        'maintainers', coalesce(s.maintainers::json, (
             select json_agg(json_build_object(
                 'name', m.name,
                 'email', m.email
@@ -51,6 +69,21 @@ begin
             from maintainer m
             join package__maintainer pm using (maintainer_id)
             where pm.package_id = v_package_id
+        )),
+        'duplicates', (
+            select json_agg(json_build_object(
+                'package_id', dp.package_id,
+                'name', dp.name,
+                'normalized_name', dp.normalized_name,
+                'chart_repository', json_build_object(
+                    'name', dr.name,
+                    'display_name', dr.display_name
+                )
+            ) order by dp.stars desc, dp.created_at asc)
+            from package dp
+            join chart_repository dr using (chart_repository_id)
+            where dp.duplicate_group_id = coalesce(p.duplicate_group_id, p.package_id)
+            and dp.package_id <> p.package_id
         ),
         'user_alias', u.alias,
         'organization_name', o.name,
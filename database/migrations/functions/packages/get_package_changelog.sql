@@ -0,0 +1,43 @@
+-- get_package_changelog returns the changes introduced in each of the versions
+-- of the package identified by the input provided as a json array. Versions
+-- without changes are not included. Entries are not sorted, as semantic
+-- versions precedence is applied by the package manager.
+create or replace function get_package_changelog(p_input jsonb)
+returns setof json as $$
+declare
+    v_package_id uuid;
+    v_package_name text := p_input->>'package_name';
+    v_chart_repository_name text := p_input->>'chart_repository_name';
+    v_package_kind text := p_input->>'package_kind';
+begin
+    if v_chart_repository_name <> '' then
+        select p.package_id into v_package_id
+        from package p
+        join chart_repository r using (chart_repository_id)
+        where r.name = v_chart_repository_name
+        and p.normalized_name = v_package_name;
+    else
+        select p.package_id into v_package_id
+        from package p
+        join package_kind pk using (package_kind_id)
+        where p.normalized_name = v_package_name
+        and p.chart_repository_id is null
+        and (v_package_kind is null or pk.slug = v_package_kind);
+    end if;
+
+    if v_package_id is null then
+        return;
+    end if;
+
+    return query
+    select coalesce(json_agg(json_build_object(
+        'version', version,
+        'prerelease', prerelease,
+        'contains_security_updates', contains_security_updates,
+        'changes', changes
+    )), '[]')
+    from snapshot
+    where package_id = v_package_id
+    and jsonb_array_length(changes) > 0;
+end
+$$ language plpgsql;
@@ -0,0 +1,37 @@
+-- get_package_dependencies returns the dependencies of the chart package
+-- version identified by the input provided as a json array. Dependencies are
+-- resolved to the packages registered in the hub when possible.
+create or replace function get_package_dependencies(p_input jsonb)
+returns setof json as $$
+    select (
+        select coalesce(json_agg(json_build_object(
+            'name', d->>'name',
+            'version', d->>'version',
+            'repository', d->>'repository',
+            'package', (
+                select json_build_object(
+                    'package_id', dp.package_id,
+                    'name', dp.name,
+                    'normalized_name', dp.normalized_name,
+                    'version', dp.latest_version,
+                    'chart_repository', json_build_object(
+                        'name', dr.name,
+                        'display_name', dr.display_name
+                    )
+                )
+                from package dp
+                join chart_repository dr using (chart_repository_id)
+                where dp.name = d->>'name'
+                and trim(trailing '/' from dr.url) = trim(trailing '/' from d->>'repository')
+                limit 1
+            )
+        )), '[]')
+        from jsonb_array_elements(coalesce(s.dependencies, '[]')) d
+    )
+    from snapshot s
+    join package p using (package_id)
+    join chart_repository r using (chart_repository_id)
+    where r.name = p_input->>'chart_repository_name'
+    and p.normalized_name = p_input->>'package_name'
+    and s.version = p_input->>'version';
+$$ language sql;
@@ -0,0 +1,28 @@
+-- get_package_dependents returns the packages whose latest version depends on
+-- the chart package identified by the input provided as a json array.
+create or replace function get_package_dependents(p_input jsonb)
+returns setof json as $$
+    select (
+        select coalesce(json_agg(json_build_object(
+            'package_id', dp.package_id,
+            'name', dp.name,
+            'normalized_name', dp.normalized_name,
+            'version', ds.version,
+            'dependency_version', d->>'version',
+            'chart_repository', json_build_object(
+                'name', dr.name,
+                'display_name', dr.display_name
+            )
+        ) order by dp.name, dr.name), '[]')
+        from package dp
+        join snapshot ds on ds.package_id = dp.package_id and ds.version = dp.latest_version
+        join chart_repository dr on dr.chart_repository_id = dp.chart_repository_id
+        cross join jsonb_array_elements(ds.dependencies) d
+        where d->>'name' = p.name
+        and trim(trailing '/' from d->>'repository') = trim(trailing '/' from r.url)
+    )
+    from package p
+    join chart_repository r using (chart_repository_id)
+    where r.name = p_input->>'chart_repository_name'
+    and p.normalized_name = p_input->>'package_name';
+$$ language sql;
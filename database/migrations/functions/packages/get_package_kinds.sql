@@ -0,0 +1,11 @@
+-- get_package_kinds returns the package kinds registered in the database as a
+-- json array.
+create or replace function get_package_kinds()
+returns setof json as $$
+    select coalesce(json_agg(json_build_object(
+        'kind', package_kind_id,
+        'name', name,
+        'slug', slug
+    ) order by package_kind_id), '[]')
+    from package_kind;
+$$ language sql;
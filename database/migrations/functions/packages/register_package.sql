@@ -2,7 +2,10 @@
 -- involves registering or updating the package entity when needed, registering
 -- a snapshot for the package version and creating/updating/deleting the
 -- package maintainers as needed depending on the ones present in the latest
--- package version.
+-- package version (maintainers without an email are only kept in the
+-- snapshot). Packages sharing their contents fingerprint or upstream
+-- source with packages of other repositories are linked to them, so that
+-- duplicates can be collapsed when searching.
 create or replace function register_package(p_pkg jsonb)
 returns void as $$
 declare
@@ -14,6 +17,8 @@ declare
         select (array(select jsonb_array_elements_text(nullif(p_pkg->'keywords', 'null'::jsonb))))::text[]
     );
     v_chart_repository_id text := (p_pkg->'chart_repository')->>'chart_repository_id';
+    v_content_fingerprint text := nullif(p_pkg->>'content_fingerprint', '');
+    v_upstream_source text := nullif(p_pkg->>'upstream_source', '');
     v_package_latest_version_needs_update boolean := false;
     v_maintainer jsonb;
     v_maintainer_id uuid;
@@ -27,7 +32,9 @@ begin
         tsdoc,
         package_kind_id,
         organization_id,
-        chart_repository_id
+        chart_repository_id,
+        content_fingerprint,
+        upstream_source
     ) values (
         v_name,
         nullif(p_pkg->>'logo_url', ''),
@@ -36,7 +43,9 @@ begin
         generate_package_tsdoc(v_name, v_display_name, v_description, v_keywords),
         (p_pkg->>'kind')::int,
         nullif(p_pkg->>'organization_id', '')::uuid,
-        nullif(v_chart_repository_id, '')::uuid
+        nullif(v_chart_repository_id, '')::uuid,
+        v_content_fingerprint,
+        v_upstream_source
     )
     on conflict (package_kind_id, chart_repository_id, name) do update
     set
@@ -45,13 +54,34 @@ begin
         logo_image_id = excluded.logo_image_id,
         latest_version = excluded.latest_version,
         tsdoc = generate_package_tsdoc(v_name, v_display_name, v_description, v_keywords),
+        content_fingerprint = excluded.content_fingerprint,
+        upstream_source = excluded.upstream_source,
         updated_at = current_timestamp
     where semver_gte(p_pkg->>'version', package.latest_version) = true
     returning package_id into v_package_id;
 
     if found then
+        -- Link package to the oldest package of other repositories sharing its
+        -- contents fingerprint or upstream source, if any
+        update package set duplicate_group_id = coalesce((
+            select coalesce(dp.duplicate_group_id, dp.package_id)
+            from package dp
+            where dp.package_id <> v_package_id
+            and dp.package_kind_id = (p_pkg->>'kind')::int
+            and dp.chart_repository_id is distinct from nullif(v_chart_repository_id, '')::uuid
+            and (
+                dp.content_fingerprint = v_content_fingerprint
+                or dp.upstream_source = v_upstream_source
+            )
+            order by dp.created_at asc
+            limit 1
+        ), v_package_id)
+        where package_id = v_package_id;
+
         -- Maintainers
-        for v_maintainer in select * from jsonb_array_elements(nullif(p_pkg->'maintainers', 'null'::jsonb))
+        for v_maintainer in
+            select * from jsonb_array_elements(nullif(p_pkg->'maintainers', 'null'::jsonb)) m
+            where nullif(m->>'email', '') is not null
         loop
             -- Register maintainer if needed
             insert into maintainer (name, email)
@@ -89,7 +119,8 @@ begin
     else
         select package_id into v_package_id
         from package
-        where chart_repository_id = v_chart_repository_id::uuid
+        where package_kind_id = (p_pkg->>'kind')::int
+        and chart_repository_id = v_chart_repository_id::uuid
         and name = v_name;
     end if;
 
@@ -106,7 +137,25 @@ begin
         readme,
         links,
         data,
-        deprecated
+        deprecated,
+        signature_status,
+        signature_key_fingerprint,
+        default_values,
+        values_schema,
+        templates,
+        dependencies,
+        images,
+        lint,
+        annotations,
+        kube_version,
+        chart_type,
+        sources,
+        api_version,
+        maintainers,
+        license,
+        changes,
+        prerelease,
+        contains_security_updates
     ) values (
         v_package_id,
         p_pkg->>'version',
@@ -119,7 +168,32 @@ begin
         nullif(p_pkg->>'readme', ''),
         p_pkg->'links',
         p_pkg->'data',
-        (p_pkg->>'deprecated')::boolean
+        (p_pkg->>'deprecated')::boolean,
+        nullif(p_pkg->>'signature_status', ''),
+        nullif(p_pkg->>'signature_key_fingerprint', ''),
+        nullif(p_pkg->>'default_values', ''),
+        p_pkg->'values_schema',
+        (array(select jsonb_array_elements_text(nullif(p_pkg->'templates', 'null'::jsonb))))::text[],
+        nullif(p_pkg->'dependencies', 'null'::jsonb),
+        (array(select jsonb_array_elements_text(nullif(p_pkg->'images', 'null'::jsonb))))::text[],
+        nullif(p_pkg->'lint', 'null'::jsonb),
+        nullif(p_pkg->'annotations', 'null'::jsonb),
+        nullif(p_pkg->>'kube_version', ''),
+        nullif(p_pkg->>'chart_type', ''),
+        (array(select jsonb_array_elements_text(nullif(p_pkg->'sources', 'null'::jsonb))))::text[],
+        nullif(p_pkg->>'api_version', ''),
+        (
+            select jsonb_agg(jsonb_strip_nulls(jsonb_build_object(
+                'name', nullif(m->>'name', ''),
+                'email', nullif(m->>'email', ''),
+                'url', nullif(m->>'url', '')
+            )))
+            from jsonb_array_elements(nullif(p_pkg->'maintainers', 'null'::jsonb)) m
+        ),
+        nullif(p_pkg->>'license', ''),
+        nullif(p_pkg->'changes', 'null'::jsonb),
+        coalesce((p_pkg->>'prerelease')::boolean, false),
+        coalesce((p_pkg->>'contains_security_updates')::boolean, false)
     )
     on conflict (package_id, version) do update
     set
@@ -131,6 +205,24 @@ begin
         digest = excluded.digest,
         readme = excluded.readme,
         links = excluded.links,
-        deprecated = excluded.deprecated;
+        deprecated = excluded.deprecated,
+        signature_status = excluded.signature_status,
+        signature_key_fingerprint = excluded.signature_key_fingerprint,
+        default_values = excluded.default_values,
+        values_schema = excluded.values_schema,
+        templates = excluded.templates,
+        dependencies = excluded.dependencies,
+        images = excluded.images,
+        lint = excluded.lint,
+        annotations = excluded.annotations,
+        kube_version = excluded.kube_version,
+        chart_type = excluded.chart_type,
+        sources = excluded.sources,
+        api_version = excluded.api_version,
+        maintainers = excluded.maintainers,
+        license = excluded.license,
+        changes = excluded.changes,
+        prerelease = excluded.prerelease,
+        contains_security_updates = excluded.contains_security_updates;
 end
 $$ language plpgsql;
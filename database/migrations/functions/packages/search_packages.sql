@@ -1,5 +1,8 @@
 -- search_packages searchs packages in the database that match the criteria in
--- the query provided.
+-- the query provided. Packages duplicated across repositories are collapsed,
+-- returning only the most starred one along with the number of duplicates,
+-- unless requested otherwise. Facets are computed from the collapsed packages
+-- as well, including the resource kinds and CRDs installed by the charts.
 create or replace function search_packages(p_input jsonb)
 returns setof json as $$
 declare
@@ -7,7 +10,13 @@ declare
     v_users text[];
     v_orgs text[];
     v_chart_repositories text[];
+    v_resource_kinds text[];
+    v_crds text[];
+    v_images text[];
+    v_chart_types text[];
+    v_kube_version text := nullif(p_input->>'kube_version', '');
     v_facets boolean := (p_input->>'facets')::boolean;
+    v_include_duplicates boolean := coalesce((p_input->>'include_duplicates')::boolean, false);
 begin
     -- Prepare filters for later use
     select array_agg(e::int) into v_package_kinds
@@ -18,6 +27,14 @@ begin
     from jsonb_array_elements_text(p_input->'orgs') e;
     select array_agg(e::text) into v_chart_repositories
     from jsonb_array_elements_text(p_input->'chart_repositories') e;
+    select array_agg(e::text) into v_resource_kinds
+    from jsonb_array_elements_text(p_input->'resource_kinds') e;
+    select array_agg(e::text) into v_crds
+    from jsonb_array_elements_text(p_input->'crds') e;
+    select array_agg(e::text) into v_images
+    from jsonb_array_elements_text(p_input->'images') e;
+    select array_agg(e::text) into v_chart_types
+    from jsonb_array_elements_text(p_input->'chart_types') e;
 
     return query
     with packages_applying_text_and_deprecated_filters as (
@@ -29,11 +46,19 @@ begin
             p.normalized_name,
             p.logo_image_id,
             p.stars,
+            p.created_at,
+            coalesce(p.duplicate_group_id, p.package_id) as duplicate_group_id,
             s.display_name,
             s.description,
             s.version,
             s.app_version,
             s.deprecated,
+            s.signature_status,
+            s.data,
+            s.images,
+            s.lint,
+            s.chart_type,
+            s.kube_version,
             u.alias as user_alias,
             o.name as organization_name,
             o.display_name as organization_display_name,
@@ -77,6 +102,59 @@ begin
             else
                 (deprecated is null or deprecated = false)
             end
+        and
+            case when p_input ? 'signed' and (p_input->>'signed')::boolean = true then
+                signature_status = 'verified'
+            else true end
+        and
+            case when p_input ? 'lint_clean' and (p_input->>'lint_clean')::boolean = true then
+                lint is not null
+                and coalesce(jsonb_array_length(lint->'errors'), 0) = 0
+                and coalesce(jsonb_array_length(lint->'warnings'), 0) = 0
+            else true end
+        and
+            case when cardinality(v_resource_kinds) > 0
+            then data->'kinds' ?| v_resource_kinds else true end
+        and
+            case when cardinality(v_crds) > 0 then exists (
+                select 1 from jsonb_array_elements(nullif(data->'crds', 'null'::jsonb)) crd
+                where crd->>'kind' = any(v_crds) or crd->>'name' = any(v_crds)
+            ) else true end
+        and
+            case when cardinality(v_images) > 0 then exists (
+                select 1 from unnest(images) i, unnest(v_images) q
+                where i = q or left(i, length(q) + 1) in (q || ':', q || '@')
+            ) else true end
+        and
+            case when cardinality(v_chart_types) > 0
+            then chart_type = any(v_chart_types) else true end
+        and
+            case when v_kube_version is not null then
+                kube_version is null or semver_satisfies(v_kube_version, kube_version)
+            else true end
+    ), packages_applying_duplicates_collapsing as (
+        select * from (
+            select
+                *,
+                count(*) over (partition by duplicate_group_id) - 1 as duplicates,
+                row_number() over (
+                    partition by duplicate_group_id
+                    order by stars desc, created_at asc
+                ) as duplicate_rank
+            from packages_applying_all_filters
+        ) pd
+        where v_include_duplicates or duplicate_rank = 1
+    ), packages_for_facets as (
+        select * from (
+            select
+                *,
+                row_number() over (
+                    partition by duplicate_group_id
+                    order by stars desc, created_at asc
+                ) as duplicate_rank
+            from packages_applying_text_and_deprecated_filters
+        ) pd
+        where v_include_duplicates or duplicate_rank = 1
     )
     select json_build_object(
         'data', (
@@ -94,6 +172,7 @@ begin
                         'version', version,
                         'app_version', app_version,
                         'deprecated', deprecated,
+                        'duplicates', duplicates,
                         'user_alias', user_alias,
                         'organization_name', organization_name,
                         'organization_display_name', organization_display_name,
@@ -106,7 +185,7 @@ begin
                         ))
                     )), '[]')
                     from (
-                        select * from packages_applying_all_filters
+                        select * from packages_applying_duplicates_collapsing
                         order by stars desc, name asc
                         limit (p_input->>'limit')::int
                         offset (p_input->>'offset')::int
@@ -129,7 +208,7 @@ begin
                                             organization_name,
                                             organization_display_name,
                                             count(*) as total
-                                        from packages_applying_text_and_deprecated_filters
+                                        from packages_for_facets
                                         where organization_name is not null
                                         group by organization_name, organization_display_name
                                         order by total desc
@@ -151,7 +230,7 @@ begin
                                         select
                                             user_alias,
                                             count(*) as total
-                                        from packages_applying_text_and_deprecated_filters
+                                        from packages_for_facets
                                         where user_alias is not null
                                         group by user_alias
                                         order by total desc
@@ -174,7 +253,7 @@ begin
                                             package_kind_id,
                                             package_kind_name,
                                             count(*) as total
-                                        from packages_applying_text_and_deprecated_filters
+                                        from packages_for_facets
                                         group by package_kind_id, package_kind_name
                                         order by total desc
                                     ) as breakdown
@@ -195,13 +274,59 @@ begin
                                         select
                                             chart_repository_name,
                                             count(*) as total
-                                        from packages_applying_text_and_deprecated_filters
+                                        from packages_for_facets
                                         where chart_repository_name is not null
                                         group by chart_repository_name
                                         order by total desc
                                     ) as breakdown
                                 )
                             )
+                        ),
+                        (
+                            select json_build_object(
+                                'title', 'Resource Kind',
+                                'filter_key', 'resource_kind',
+                                'options', (
+                                    select coalesce(json_agg(json_build_object(
+                                        'id', resource_kind,
+                                        'name', resource_kind,
+                                        'total', total
+                                    )), '[]')
+                                    from (
+                                        select
+                                            k.resource_kind,
+                                            count(*) as total
+                                        from packages_for_facets
+                                        cross join jsonb_array_elements_text(nullif(data->'kinds', 'null'::jsonb)) as k(resource_kind)
+                                        group by k.resource_kind
+                                        order by total desc, k.resource_kind asc
+                                    ) as breakdown
+                                )
+                            )
+                        ),
+                        (
+                            select json_build_object(
+                                'title', 'CRD',
+                                'filter_key', 'crd',
+                                'options', (
+                                    select coalesce(json_agg(json_build_object(
+                                        'id', crd_name,
+                                        'name', crd_kind,
+                                        'total', total
+                                    )), '[]')
+                                    from (
+                                        select
+                                            crd->>'name' as crd_name,
+                                            crd->>'kind' as crd_kind,
+                                            count(distinct package_id) as total
+                                        from packages_for_facets
+                                        cross join jsonb_array_elements(nullif(data->'crds', 'null'::jsonb)) as crd
+                                        where crd->>'name' is not null
+                                        group by crd->>'name', crd->>'kind'
+                                        order by total desc, crd_name asc
+                                    ) as breakdown
+                                )
+                            )
                         )
                     )
                 ) else null end
@@ -211,7 +336,7 @@ begin
             select json_build_object(
                 'limit', (p_input->>'limit')::int,
                 'offset', (p_input->>'offset')::int,
-                'total', (select count(*) from packages_applying_all_filters)
+                'total', (select count(*) from packages_applying_duplicates_collapsing)
             )
         )
     );
@@ -0,0 +1,107 @@
+-- semver_satisfies checks if the semver provided satisfies the constraint
+-- given, which uses the syntax supported by Helm for the chart's kubeVersion
+-- (i.e. >=1.16.0-0 <1.19.0, ^1.14, ~1.15, 1.17.x, 1.14 - 1.16, ... || ...).
+create or replace function semver_satisfies(p_version text, p_constraint text)
+returns boolean as $$
+declare
+    term_regexp text := '^(>=|<=|!=|=|>|<|~>|~|\^)?v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?$';
+    v_alternative text;
+    v_term text;
+    v_parts text[];
+    v_op text;
+    v_major int;
+    v_minor int;
+    v_patch int;
+    v_lower text;
+    v_upper text;
+    v_in_range boolean;
+    v_satisfied boolean;
+begin
+    foreach v_alternative in array string_to_array(p_constraint, '||')
+    loop
+        -- Turn hyphen ranges into comparisons and join operators to versions
+        v_alternative := regexp_replace(v_alternative, '(\S+)\s+-\s+(\S+)', '>=\1 <=\2', 'g');
+        v_alternative := regexp_replace(v_alternative, '(>=|<=|!=|=|>|<|~>|~|\^)\s+', '\1', 'g');
+
+        -- All the terms of the alternative must be satisfied
+        v_satisfied := true;
+        foreach v_term in array regexp_split_to_array(btrim(v_alternative), '[\s,]+')
+        loop
+            continue when v_term = '';
+            v_parts := regexp_match(v_term, term_regexp);
+            if v_parts is null then
+                v_satisfied := false;
+                exit;
+            end if;
+            v_op := coalesce(v_parts[1], '=');
+            v_major := case when v_parts[2] ~ '^\d+$' then v_parts[2]::int end;
+            v_minor := case when v_major is not null and v_parts[3] ~ '^\d+$' then v_parts[3]::int end;
+            v_patch := case when v_minor is not null and v_parts[4] ~ '^\d+$' then v_parts[4]::int end;
+            if v_major is null then
+                -- Wildcard: any version matches
+                v_satisfied := v_op not in ('!=', '>', '<');
+                exit when not v_satisfied;
+                continue;
+            end if;
+
+            -- Lower bound (inclusive) and, for partial versions, upper bound
+            -- (exclusive) of the versions matched by the term
+            v_lower := format('%s.%s.%s%s',
+                v_major, coalesce(v_minor, 0), coalesce(v_patch, 0), coalesce(v_parts[5], '')
+            );
+            v_upper := case
+                when v_minor is null then format('%s.0.0', v_major + 1)
+                when v_patch is null then format('%s.%s.0', v_major, v_minor + 1)
+            end;
+            v_in_range := semver_gte(p_version, v_lower) and
+                case when v_upper is null then
+                    semver_gte(v_lower, p_version)
+                else
+                    not semver_gte(p_version, v_upper)
+                end;
+
+            v_satisfied := case v_op
+                when '=' then v_in_range
+                when '!=' then not v_in_range
+                when '>=' then semver_gte(p_version, v_lower)
+                when '<' then not semver_gte(p_version, v_lower)
+                when '>' then
+                    case when v_upper is null then
+                        not semver_gte(v_lower, p_version)
+                    else
+                        semver_gte(p_version, v_upper)
+                    end
+                when '<=' then
+                    case when v_upper is null then
+                        semver_gte(v_lower, p_version)
+                    else
+                        not semver_gte(p_version, v_upper)
+                    end
+                when '^' then
+                    semver_gte(p_version, v_lower) and not semver_gte(p_version,
+                        case
+                            when v_major > 0 or v_minor is null then format('%s.0.0', v_major + 1)
+                            when v_minor > 0 or v_patch is null then format('0.%s.0', v_minor + 1)
+                            else format('0.0.%s', v_patch + 1)
+                        end
+                    )
+                else -- ~ and ~>
+                    semver_gte(p_version, v_lower) and not semver_gte(p_version,
+                        case when v_minor is null then
+                            format('%s.0.0', v_major + 1)
+                        else
+                            format('%s.%s.0', v_major, v_minor + 1)
+                        end
+                    )
+            end;
+            exit when not v_satisfied;
+        end loop;
+
+        if v_satisfied then
+            return true;
+        end if;
+    end loop;
+
+    return false;
+end
+$$ language plpgsql;
@@ -1,4 +1,6 @@
 -- unregister_package unregisters the provided package version from the database.
+-- Packages that don't belong to a chart repository are identified by the
+-- organization owning them.
 create or replace function unregister_package(p_pkg jsonb)
 returns void as $$
 declare
@@ -15,7 +17,14 @@ begin
     join snapshot s using (package_id)
     where p.package_kind_id = (p_pkg->>'kind')::int
     and p.name = p_pkg->>'name'
-    and chart_repository_id = nullif(v_chart_repository_id, '')::uuid
+    and (
+        p.chart_repository_id = nullif(v_chart_repository_id, '')::uuid
+        or (
+            nullif(v_chart_repository_id, '') is null
+            and p.chart_repository_id is null
+            and p.organization_id = nullif(p_pkg->>'organization_id', '')::uuid
+        )
+    )
     group by p.package_id, p.latest_version;
     if not found then
         return;
@@ -0,0 +1,9 @@
+alter table chart_repository add column last_index_digest text check (last_index_digest <> '');
+alter table chart_repository add column last_index_etag text check (last_index_etag <> '');
+alter table chart_repository add column last_index_last_modified text check (last_index_last_modified <> '');
+
+---- create above / drop below ----
+
+alter table chart_repository drop column last_index_last_modified;
+alter table chart_repository drop column last_index_etag;
+alter table chart_repository drop column last_index_digest;
@@ -0,0 +1,5 @@
+alter table chart_repository add column tracking_interval interval check (tracking_interval >= '1 minute');
+
+---- create above / drop below ----
+
+alter table chart_repository drop column tracking_interval;
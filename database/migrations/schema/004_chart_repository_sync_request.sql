@@ -0,0 +1,17 @@
+create table if not exists chart_repository_sync_request (
+    chart_repository_sync_request_id uuid primary key default gen_random_uuid(),
+    chart_repository_id uuid not null references chart_repository on delete cascade,
+    user_id uuid references "user" on delete set null,
+    status text not null default 'pending' check (status in ('pending', 'running', 'completed', 'failed')),
+    errors text,
+    created_at timestamptz default current_timestamp not null,
+    started_at timestamptz,
+    finished_at timestamptz
+);
+
+create index chart_repository_sync_request_chart_repository_id_idx on chart_repository_sync_request (chart_repository_id);
+create index chart_repository_sync_request_status_idx on chart_repository_sync_request (status) where status in ('pending', 'running');
+
+---- create above / drop below ----
+
+drop table if exists chart_repository_sync_request;
@@ -0,0 +1,16 @@
+create table if not exists tracking_run (
+    tracking_run_id uuid primary key default gen_random_uuid(),
+    chart_repository_id uuid not null references chart_repository on delete cascade,
+    started_at timestamptz not null,
+    finished_at timestamptz not null,
+    versions_registered integer not null default 0,
+    versions_unregistered integer not null default 0,
+    versions_skipped integer not null default 0,
+    errors jsonb
+);
+
+create index tracking_run_chart_repository_id_started_at_idx on tracking_run (chart_repository_id, started_at desc);
+
+---- create above / drop below ----
+
+drop table if exists tracking_run;
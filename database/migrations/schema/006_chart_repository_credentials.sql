@@ -0,0 +1,7 @@
+alter table chart_repository add column credentials text;
+alter table chart_repository add column tls_config jsonb;
+
+---- create above / drop below ----
+
+alter table chart_repository drop column tls_config;
+alter table chart_repository drop column credentials;
@@ -0,0 +1,11 @@
+alter table chart_repository add column keyring text;
+alter table organization add column keyring text;
+alter table snapshot add column signature_status text check (signature_status in ('signed', 'verified', 'invalid'));
+alter table snapshot add column signature_key_fingerprint text;
+
+---- create above / drop below ----
+
+alter table snapshot drop column signature_key_fingerprint;
+alter table snapshot drop column signature_status;
+alter table organization drop column keyring;
+alter table chart_repository drop column keyring;
@@ -0,0 +1,9 @@
+alter table snapshot add column default_values text;
+alter table snapshot add column values_schema jsonb;
+alter table snapshot add column templates text[];
+
+---- create above / drop below ----
+
+alter table snapshot drop column templates;
+alter table snapshot drop column values_schema;
+alter table snapshot drop column default_values;
@@ -0,0 +1,5 @@
+alter table snapshot add column images text[];
+
+---- create above / drop below ----
+
+alter table snapshot drop column images;
@@ -0,0 +1,5 @@
+alter table chart_repository add column package_kind_id integer not null default 0 references package_kind on delete restrict;
+
+---- create above / drop below ----
+
+alter table chart_repository drop column package_kind_id;
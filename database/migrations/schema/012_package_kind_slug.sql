@@ -0,0 +1,9 @@
+alter table package_kind add column slug text unique check (slug <> '');
+update package_kind set slug = 'chart' where package_kind_id = 0;
+update package_kind set slug = 'falco' where package_kind_id = 1;
+update package_kind set slug = 'opa' where package_kind_id = 2;
+alter table package_kind alter column slug set not null;
+
+---- create above / drop below ----
+
+alter table package_kind drop column slug;
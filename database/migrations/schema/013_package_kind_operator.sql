@@ -0,0 +1,5 @@
+insert into package_kind values (3, 'Operators', 'operator');
+
+---- create above / drop below ----
+
+delete from package_kind where package_kind_id = 3;
@@ -0,0 +1,45 @@
+update tracking_run set errors = (
+    select jsonb_agg(
+        (e - 'kind') || jsonb_build_object(
+            'code', case e->>'kind'
+                when 'index_load' then 'index_unreachable'
+                when 'invalid_chart_url' then 'invalid_chart_url'
+                when 'chart_load' then 'chart_download_failed'
+                when 'digest_mismatch' then 'digest_mismatch'
+                when 'logo_load' then 'logo_failed'
+                when 'register' then 'register_failed'
+                when 'unregister' then 'unregister_failed'
+                else 'unknown'
+            end,
+            'severity', case e->>'kind'
+                when 'logo_load' then 'warning'
+                else 'error'
+            end
+        )
+    )
+    from jsonb_array_elements(errors) e
+)
+where jsonb_typeof(errors) = 'array';
+
+---- create above / drop below ----
+
+update tracking_run set errors = (
+    select jsonb_agg(
+        (e - 'code' - 'severity') || jsonb_build_object(
+            'kind', case e->>'code'
+                when 'index_unreachable' then 'index_load'
+                when 'invalid_chart_url' then 'invalid_chart_url'
+                when 'chart_download_failed' then 'chart_load'
+                when 'invalid_chart' then 'chart_load'
+                when 'digest_mismatch' then 'digest_mismatch'
+                when 'logo_failed' then 'logo_load'
+                when 'register_failed' then 'register'
+                when 'invalid_semver' then 'register'
+                when 'unregister_failed' then 'unregister'
+                else 'other'
+            end
+        )
+    )
+    from jsonb_array_elements(errors) e
+)
+where jsonb_typeof(errors) = 'array';
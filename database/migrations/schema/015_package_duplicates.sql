@@ -0,0 +1,13 @@
+alter table package add column content_fingerprint text check (content_fingerprint <> '');
+alter table package add column upstream_source text check (upstream_source <> '');
+alter table package add column duplicate_group_id uuid;
+update package set duplicate_group_id = package_id;
+create index package_content_fingerprint_idx on package (content_fingerprint);
+create index package_upstream_source_idx on package (upstream_source);
+create index package_duplicate_group_id_idx on package (duplicate_group_id);
+
+---- create above / drop below ----
+
+alter table package drop column duplicate_group_id;
+alter table package drop column upstream_source;
+alter table package drop column content_fingerprint;
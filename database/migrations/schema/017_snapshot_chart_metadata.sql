@@ -0,0 +1,15 @@
+alter table snapshot add column annotations jsonb;
+alter table snapshot add column kube_version text check (kube_version <> '');
+alter table snapshot add column chart_type text check (chart_type <> '');
+alter table snapshot add column sources text[];
+alter table snapshot add column api_version text check (api_version <> '');
+alter table snapshot add column maintainers jsonb;
+
+---- create above / drop below ----
+
+alter table snapshot drop column maintainers;
+alter table snapshot drop column api_version;
+alter table snapshot drop column sources;
+alter table snapshot drop column chart_type;
+alter table snapshot drop column kube_version;
+alter table snapshot drop column annotations;
@@ -0,0 +1,11 @@
+alter table snapshot add column license text check (license <> '');
+alter table snapshot add column changes text[];
+alter table snapshot add column prerelease boolean not null default false;
+alter table snapshot add column contains_security_updates boolean not null default false;
+
+---- create above / drop below ----
+
+alter table snapshot drop column contains_security_updates;
+alter table snapshot drop column prerelease;
+alter table snapshot drop column changes;
+alter table snapshot drop column license;
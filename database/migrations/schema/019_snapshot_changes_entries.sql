@@ -0,0 +1,19 @@
+alter table snapshot add column changes_entries jsonb;
+update snapshot set changes_entries = (
+    select jsonb_agg(jsonb_build_object('kind', 'changed', 'description', c))
+    from unnest(changes) c
+)
+where changes is not null;
+alter table snapshot drop column changes;
+alter table snapshot rename column changes_entries to changes;
+
+---- create above / drop below ----
+
+alter table snapshot add column changes_descriptions text[];
+update snapshot set changes_descriptions = (
+    select array_agg(c->>'description')
+    from jsonb_array_elements(changes) c
+)
+where jsonb_typeof(changes) = 'array';
+alter table snapshot drop column changes;
+alter table snapshot rename column changes_descriptions to changes;
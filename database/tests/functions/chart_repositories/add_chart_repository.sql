@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(3);
+select plan(6);
 
 -- Declare some variables
 \set user1ID '00000000-0000-0000-0000-000000000001'
@@ -75,6 +75,78 @@ select results_eq(
     'Chart repository should exist and be owned by organization'
 );
 
+-- Add chart repository with credentials and TLS settings
+select add_chart_repository(:'user1ID', null, '
+{
+    "name": "repo3",
+    "display_name": "Repository 3",
+    "url": "repo3_url",
+    "credentials": "encrypted-credentials",
+    "tls_config": {
+        "ca_bundle": "ca-bundle",
+        "insecure_skip_verify": true
+    },
+    "keyring": "keyring"
+}
+'::jsonb);
+select results_eq(
+    $$
+        select credentials, tls_config, keyring
+        from chart_repository
+        where name = 'repo3'
+    $$,
+    $$
+        values (
+            'encrypted-credentials',
+            '{"ca_bundle": "ca-bundle", "insecure_skip_verify": true}'::jsonb,
+            'keyring'
+        )
+    $$,
+    'Chart repository credentials, TLS settings and keyring should have been stored'
+);
+
+-- Add chart repository with custom tracking interval
+select add_chart_repository(:'user1ID', null, '
+{
+    "name": "repo6",
+    "display_name": "Repository 6",
+    "url": "repo6_url",
+    "tracking_interval": 3600
+}
+'::jsonb);
+select results_eq(
+    $$
+        select tracking_interval
+        from chart_repository
+        where name = 'repo6'
+    $$,
+    $$
+        values ('1 hour'::interval)
+    $$,
+    'Chart repository tracking interval should have been stored'
+);
+
+-- Add falco rules repository
+select add_chart_repository(:'user1ID', null, '
+{
+    "kind": 1,
+    "name": "repo5",
+    "display_name": "Repository 5",
+    "url": "https://github.com/org/repo5.git#rules"
+}
+'::jsonb);
+select results_eq(
+    $$
+        select package_kind_id, url
+        from chart_repository
+        where name = 'repo5'
+    $$,
+    $$
+        values (1, 'https://github.com/org/repo5.git#rules')
+    $$,
+    'Falco rules repository should exist'
+);
+
 -- Add chart repository owned by organization, but user does not belong to it
 select throws_ok(
     $$
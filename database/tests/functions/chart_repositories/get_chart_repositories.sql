@@ -10,31 +10,88 @@ select is(
 );
 
 -- Seed some chart repositories
+insert into organization (organization_id, name, display_name, keyring)
+values ('00000000-0000-0000-0000-000000000001', 'org1', 'Organization 1', 'org1-keyring');
 insert into chart_repository (chart_repository_id, name, display_name, url)
 values ('00000000-0000-0000-0000-000000000001', 'repo1', 'Repo 1', 'https://repo1.com');
 insert into chart_repository (chart_repository_id, name, display_name, url)
 values ('00000000-0000-0000-0000-000000000002', 'repo2', 'Repo 2', 'https://repo2.com');
-insert into chart_repository (chart_repository_id, name, display_name, url)
-values ('00000000-0000-0000-0000-000000000003', 'repo3', 'Repo 3', 'https://repo3.com');
+insert into chart_repository (
+    chart_repository_id,
+    name,
+    display_name,
+    url,
+    last_index_digest,
+    last_index_etag,
+    last_index_last_modified,
+    tracking_interval,
+    credentials,
+    tls_config,
+    keyring,
+    organization_id
+) values (
+    '00000000-0000-0000-0000-000000000003',
+    'repo3',
+    'Repo 3',
+    'https://repo3.com',
+    'digest',
+    'etag',
+    'Wed, 21 Oct 2015 07:28:00 GMT',
+    '1 hour',
+    'encrypted-credentials',
+    '{"insecure_skip_verify": true}',
+    'repo3-keyring',
+    '00000000-0000-0000-0000-000000000001'
+);
 
 -- Run some tests
 select is(
     get_chart_repositories()::jsonb,
     '[{
         "chart_repository_id": "00000000-0000-0000-0000-000000000001",
+        "kind": 0,
         "name": "repo1",
         "display_name": "Repo 1",
-        "url": "https://repo1.com"
+        "url": "https://repo1.com",
+        "last_index_info": {
+            "digest": null,
+            "etag": null,
+            "last_modified": null
+        },
+        "tracking_interval": null,
+        "credentials": null,
+        "tls_config": null,
+        "keyring": null
     }, {
         "chart_repository_id": "00000000-0000-0000-0000-000000000002",
+        "kind": 0,
         "name": "repo2",
         "display_name": "Repo 2",
-        "url": "https://repo2.com"
+        "url": "https://repo2.com",
+        "last_index_info": {
+            "digest": null,
+            "etag": null,
+            "last_modified": null
+        },
+        "tracking_interval": null,
+        "credentials": null,
+        "tls_config": null,
+        "keyring": null
     }, {
         "chart_repository_id": "00000000-0000-0000-0000-000000000003",
+        "kind": 0,
         "name": "repo3",
         "display_name": "Repo 3",
-        "url": "https://repo3.com"
+        "url": "https://repo3.com",
+        "last_index_info": {
+            "digest": "digest",
+            "etag": "etag",
+            "last_modified": "Wed, 21 Oct 2015 07:28:00 GMT"
+        },
+        "tracking_interval": 3600,
+        "credentials": "encrypted-credentials",
+        "tls_config": {"insecure_skip_verify": true},
+        "keyring": "repo3-keyring\norg1-keyring"
     }]'::jsonb,
     'Repositories are returned as a json array of objects'
 );
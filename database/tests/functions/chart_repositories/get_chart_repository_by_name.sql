@@ -17,9 +17,19 @@ select is(
     get_chart_repository_by_name('repo1')::jsonb,
     '{
         "chart_repository_id": "00000000-0000-0000-0000-000000000001",
+        "kind": 0,
         "name": "repo1",
         "display_name": "Repo 1",
-        "url": "https://repo1.com"
+        "url": "https://repo1.com",
+        "last_index_info": {
+            "digest": null,
+            "etag": null,
+            "last_modified": null
+        },
+        "tracking_interval": null,
+        "credentials": null,
+        "tls_config": null,
+        "keyring": null
     }'::jsonb,
     'Repository just seeded is returned as a json object'
 );
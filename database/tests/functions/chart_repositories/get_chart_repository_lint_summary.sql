@@ -0,0 +1,85 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+\set package3ID '00000000-0000-0000-0000-000000000003'
+\set package4ID '00000000-0000-0000-0000-000000000004'
+
+-- Non existing repository
+select is_empty(
+    $$ select get_chart_repository_lint_summary('repo1') $$,
+    'If repository requested does not exist no rows are returned'
+);
+
+-- Seed chart repository without packages
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+select is(
+    get_chart_repository_lint_summary('repo1')::jsonb,
+    '{
+        "packages": 0,
+        "linted": 0,
+        "clean": 0,
+        "with_errors": 0,
+        "with_warnings": 0,
+        "packages_with_issues": []
+    }'::jsonb,
+    'Empty summary expected for repository without packages'
+);
+
+-- Seed some packages
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package1ID', 'package1', '1.0.0', 0, :'repo1ID');
+insert into snapshot (package_id, version, lint)
+values (:'package1ID', '1.0.0', '{"info": [{"path": "Chart.yaml", "message": "icon is recommended"}]}');
+insert into snapshot (package_id, version, lint)
+values (:'package1ID', '0.9.0', '{"errors": [{"path": "templates/", "message": "error"}]}');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package2ID', 'package2', '1.0.0', 0, :'repo1ID');
+insert into snapshot (package_id, version, lint)
+values (:'package2ID', '1.0.0', '{"warnings": [{"path": "templates/", "message": "directory not found"}]}');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package3ID', 'package3', '1.0.0', 0, :'repo1ID');
+insert into snapshot (package_id, version, lint)
+values (:'package3ID', '1.0.0', '{
+    "errors": [{"path": "Chart.yaml", "message": "error"}],
+    "warnings": [{"path": "templates/", "message": "warning"}]
+}');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package4ID', 'package4', '1.0.0', 0, :'repo1ID');
+insert into snapshot (package_id, version)
+values (:'package4ID', '1.0.0');
+
+-- Run some tests
+select is(
+    get_chart_repository_lint_summary('repo1')::jsonb,
+    '{
+        "packages": 4,
+        "linted": 3,
+        "clean": 1,
+        "with_errors": 1,
+        "with_warnings": 1,
+        "packages_with_issues": [{
+            "name": "package3",
+            "normalized_name": "package3",
+            "version": "1.0.0",
+            "errors": 1,
+            "warnings": 1
+        }, {
+            "name": "package2",
+            "normalized_name": "package2",
+            "version": "1.0.0",
+            "errors": 0,
+            "warnings": 1
+        }]
+    }'::jsonb,
+    'Summary of the lint results of the latest packages versions expected'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
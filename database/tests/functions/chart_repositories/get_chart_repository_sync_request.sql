@@ -0,0 +1,93 @@
+-- Start transaction and plan tests
+begin;
+select plan(4);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+\set syncRequest1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com', :'user1ID');
+insert into chart_repository_sync_request (
+    chart_repository_sync_request_id,
+    chart_repository_id,
+    user_id,
+    status,
+    errors,
+    created_at,
+    started_at,
+    finished_at
+) values (
+    :'syncRequest1ID',
+    :'repo1ID',
+    :'user1ID',
+    'failed',
+    'error1',
+    '1970-01-01 00:00:00 UTC',
+    '1970-01-01 00:00:01 UTC',
+    '1970-01-01 00:00:02 UTC'
+);
+
+-- Try to get a sync request of a chart repository owned by other user
+select throws_ok(
+    $$
+        select get_chart_repository_sync_request(
+            '00000000-0000-0000-0000-000000000002',
+            'repo1',
+            '00000000-0000-0000-0000-000000000001'
+        )
+    $$,
+    42501,
+    'insufficient_privilege',
+    'Getting sync request should fail because requesting user is not the owner'
+);
+
+-- Get sync request
+select is(
+    get_chart_repository_sync_request(:'user1ID', 'repo1', :'syncRequest1ID')::jsonb,
+    '{
+        "sync_request_id": "00000000-0000-0000-0000-000000000001",
+        "status": "failed",
+        "errors": "error1",
+        "created_at": 0,
+        "started_at": 1,
+        "finished_at": 2
+    }'::jsonb,
+    'Sync request should be returned as a json object'
+);
+
+-- Try to get sync request using a different chart repository
+select is_empty(
+    $$
+        select get_chart_repository_sync_request(
+            '00000000-0000-0000-0000-000000000001',
+            'repo2',
+            '00000000-0000-0000-0000-000000000001'
+        )
+    $$,
+    'Sync request should not be returned when it does not belong to the chart repository provided'
+);
+
+-- Try to get sync request that does not exist
+select is_empty(
+    $$
+        select get_chart_repository_sync_request(
+            '00000000-0000-0000-0000-000000000001',
+            'repo1',
+            '00000000-0000-0000-0000-000000000002'
+        )
+    $$,
+    'Nothing should be returned when the sync request does not exist'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
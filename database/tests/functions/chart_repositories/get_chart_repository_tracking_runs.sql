@@ -0,0 +1,105 @@
+-- Start transaction and plan tests
+begin;
+select plan(4);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+
+-- Try to get tracking runs of a chart repository owned by other user
+select throws_ok(
+    $$
+        select get_chart_repository_tracking_runs('00000000-0000-0000-0000-000000000002', 'repo1', 10)
+    $$,
+    42501,
+    'insufficient_privilege',
+    'Getting tracking runs should fail because requesting user is not the owner'
+);
+
+-- No tracking runs at this point
+select is(
+    get_chart_repository_tracking_runs(:'user1ID', 'repo1', 10)::jsonb,
+    '[]'::jsonb,
+    'With no tracking runs an empty json array is returned'
+);
+
+-- Seed some tracking runs
+insert into tracking_run (
+    tracking_run_id,
+    chart_repository_id,
+    started_at,
+    finished_at,
+    versions_registered,
+    versions_unregistered,
+    versions_skipped,
+    errors
+) values (
+    '00000000-0000-0000-0000-000000000001',
+    :'repo1ID',
+    '1970-01-01 00:00:00 UTC',
+    '1970-01-01 00:01:00 UTC',
+    1,
+    0,
+    0,
+    '[{"code": "register_failed", "severity": "error", "chart": "pkg1", "version": "1.0.0", "message": "error1"}]'
+);
+insert into tracking_run (
+    tracking_run_id,
+    chart_repository_id,
+    started_at,
+    finished_at,
+    versions_skipped
+) values (
+    '00000000-0000-0000-0000-000000000002',
+    :'repo1ID',
+    '1970-01-01 01:00:00 UTC',
+    '1970-01-01 01:01:00 UTC',
+    1
+);
+
+-- Run some tests
+select is(
+    get_chart_repository_tracking_runs(:'user1ID', 'repo1', 10)::jsonb,
+    '[{
+        "tracking_run_id": "00000000-0000-0000-0000-000000000002",
+        "started_at": 3600,
+        "finished_at": 3660,
+        "versions_registered": 0,
+        "versions_unregistered": 0,
+        "versions_skipped": 1,
+        "errors": null
+    }, {
+        "tracking_run_id": "00000000-0000-0000-0000-000000000001",
+        "started_at": 0,
+        "finished_at": 60,
+        "versions_registered": 1,
+        "versions_unregistered": 0,
+        "versions_skipped": 0,
+        "errors": [{"code": "register_failed", "severity": "error", "chart": "pkg1", "version": "1.0.0", "message": "error1"}]
+    }]'::jsonb,
+    'Tracking runs should be returned as a json array sorted by start time'
+);
+select is(
+    get_chart_repository_tracking_runs(:'user1ID', 'repo1', 1)::jsonb,
+    '[{
+        "tracking_run_id": "00000000-0000-0000-0000-000000000002",
+        "started_at": 3600,
+        "finished_at": 3660,
+        "versions_registered": 0,
+        "versions_unregistered": 0,
+        "versions_skipped": 1,
+        "errors": null
+    }]'::jsonb,
+    'Only the number of tracking runs requested should be returned'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -64,16 +64,22 @@ select is(
     get_user_chart_repositories(:'user1ID')::jsonb,
     '[{
         "chart_repository_id": "00000000-0000-0000-0000-000000000001",
+        "kind": 0,
         "name": "repo1",
         "display_name": "Repo 1",
         "url": "https://repo1.com",
+        "keyring": null,
+        "tracking_interval": null,
         "last_tracking_ts": 0,
         "last_tracking_errors": "error1\\nerror2\\nerror3"
     }, {
         "chart_repository_id": "00000000-0000-0000-0000-000000000002",
+        "kind": 0,
         "name": "repo2",
         "display_name": "Repo 2",
         "url": "https://repo2.com",
+        "keyring": null,
+        "tracking_interval": null,
         "last_tracking_ts": null,
         "last_tracking_errors": null
     }]'::jsonb,
@@ -0,0 +1,82 @@
+-- Start transaction and plan tests
+begin;
+select plan(2);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+
+-- Register tracking run
+select register_tracking_run('
+{
+    "chart_repository_id": "00000000-0000-0000-0000-000000000001",
+    "started_at": 0,
+    "finished_at": 60,
+    "versions_registered": 2,
+    "versions_unregistered": 1,
+    "versions_skipped": 10,
+    "errors": [{
+        "code": "chart_download_failed",
+        "severity": "error",
+        "chart": "pkg1",
+        "version": "1.0.0",
+        "url": "https://repo1.com/pkg1-1.0.0.tgz",
+        "message": "error1"
+    }]
+}
+');
+select results_eq(
+    $$
+        select
+            started_at,
+            finished_at,
+            versions_registered,
+            versions_unregistered,
+            versions_skipped,
+            errors
+        from tracking_run
+        where chart_repository_id = '00000000-0000-0000-0000-000000000001'
+    $$,
+    $$
+        values (
+            '1970-01-01 00:00:00 UTC'::timestamptz,
+            '1970-01-01 00:01:00 UTC'::timestamptz,
+            2,
+            1,
+            10,
+            '[{
+                "code": "chart_download_failed",
+        "severity": "error",
+                "chart": "pkg1",
+                "version": "1.0.0",
+                "url": "https://repo1.com/pkg1-1.0.0.tgz",
+                "message": "error1"
+            }]'::jsonb
+        )
+    $$,
+    'Tracking run should have been registered'
+);
+
+-- Register many tracking runs, only the most recent ones should be kept
+select register_tracking_run(jsonb_build_object(
+    'chart_repository_id', '00000000-0000-0000-0000-000000000001',
+    'started_at', i,
+    'finished_at', i
+))
+from generate_series(1, 100) i;
+select results_eq(
+    $$
+        select count(*), min(started_at)
+        from tracking_run
+        where chart_repository_id = '00000000-0000-0000-0000-000000000001'
+    $$,
+    $$ values (100::bigint, '1970-01-01 00:00:01 UTC'::timestamptz) $$,
+    'Only the most recent 100 tracking runs should be kept'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -0,0 +1,93 @@
+-- Start transaction and plan tests
+begin;
+select plan(6);
+
+-- Declare some variables
+\set user1ID '00000000-0000-0000-0000-000000000001'
+\set user2ID '00000000-0000-0000-0000-000000000002'
+\set org1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into "user" (user_id, alias, email) values (:'user1ID', 'user1', 'user1@email.com');
+insert into "user" (user_id, alias, email) values (:'user2ID', 'user2', 'user2@email.com');
+insert into organization (organization_id, name, display_name, description, home_url)
+values (:'org1ID', 'org1', 'Organization 1', 'Description 1', 'https://org1.com');
+insert into user__organization (user_id, organization_id, confirmed) values(:'user1ID', :'org1ID', true);
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
+insert into chart_repository (chart_repository_id, name, display_name, url, organization_id)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com', :'org1ID');
+
+-- Try to request the sync of a chart repository owned by other user
+select throws_ok(
+    $$
+        select request_chart_repository_sync('00000000-0000-0000-0000-000000000002', 'repo1')
+    $$,
+    42501,
+    'insufficient_privilege',
+    'Sync request should fail because requesting user is not the owner'
+);
+
+-- Try to request the sync of a chart repository owned by an organization by
+-- user not belonging to it
+select throws_ok(
+    $$
+        select request_chart_repository_sync('00000000-0000-0000-0000-000000000002', 'repo2')
+    $$,
+    42501,
+    'insufficient_privilege',
+    'Sync request should fail because requesting user does not belong to owning organization'
+);
+
+-- Request sync of chart repository owned by user
+select request_chart_repository_sync(:'user1ID', 'repo1') as sync_request1_id \gset
+select results_eq(
+    $$
+        select chart_repository_id, user_id, status
+        from chart_repository_sync_request
+        where chart_repository_id = '00000000-0000-0000-0000-000000000001'
+    $$,
+    $$
+        values (
+            '00000000-0000-0000-0000-000000000001'::uuid,
+            '00000000-0000-0000-0000-000000000001'::uuid,
+            'pending'
+        )
+    $$,
+    'Sync request should have been registered for chart repository owned by user'
+);
+
+-- Request sync again while previous request is still pending
+select is(
+    request_chart_repository_sync(:'user1ID', 'repo1'),
+    :'sync_request1_id'::uuid,
+    'Pending sync request should be reused'
+);
+
+-- Request sync again once the previous request has been picked up
+update chart_repository_sync_request set status = 'running' where chart_repository_sync_request_id = :'sync_request1_id';
+select isnt(
+    request_chart_repository_sync(:'user1ID', 'repo1'),
+    :'sync_request1_id'::uuid,
+    'New sync request should be registered when there are none pending'
+);
+
+-- Request sync of chart repository owned by organization (requesting user
+-- belongs to organization)
+select request_chart_repository_sync(:'user1ID', 'repo2');
+select results_eq(
+    $$
+        select count(*)
+        from chart_repository_sync_request
+        where chart_repository_id = '00000000-0000-0000-0000-000000000002'
+        and status = 'pending'
+    $$,
+    $$ values (1::bigint) $$,
+    'Sync request should have been registered for chart repository owned by organization'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
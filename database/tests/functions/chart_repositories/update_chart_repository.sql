@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(4);
+select plan(11);
 
 -- Declare some variables
 \set user1ID '00000000-0000-0000-0000-000000000001'
@@ -15,10 +15,48 @@ values (:'user1ID', 'user1', 'user1@email.com');
 insert into organization (organization_id, name, display_name, description, home_url)
 values (:'org1ID', 'org1', 'Organization 1', 'Description 1', 'https://org1.com');
 insert into user__organization (user_id, organization_id, confirmed) values(:'user1ID', :'org1ID', true);
-insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
-values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'user1ID');
-insert into chart_repository (chart_repository_id, name, display_name, url, organization_id)
-values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com', :'org1ID');
+insert into chart_repository (
+    chart_repository_id,
+    name,
+    display_name,
+    url,
+    last_index_digest,
+    last_index_etag,
+    last_index_last_modified,
+    credentials,
+    tls_config,
+    user_id
+) values (
+    :'repo1ID',
+    'repo1',
+    'Repo 1',
+    'https://repo1.com',
+    'digest',
+    'etag',
+    'Wed, 21 Oct 2015 07:28:00 GMT',
+    'encrypted-credentials',
+    '{"insecure_skip_verify": true}',
+    :'user1ID'
+);
+insert into chart_repository (
+    chart_repository_id,
+    name,
+    display_name,
+    url,
+    last_index_digest,
+    last_index_etag,
+    last_index_last_modified,
+    organization_id
+) values (
+    :'repo2ID',
+    'repo2',
+    'Repo 2',
+    'https://repo2.com',
+    'digest',
+    'etag',
+    'Wed, 21 Oct 2015 07:28:00 GMT',
+    :'org1ID'
+);
 
 -- Try to update repository owned by a user by other user
 select throws_ok(
@@ -71,6 +109,28 @@ select results_eq(
     $$,
     'Chart repository should have been updated by user who owns it'
 );
+select results_eq(
+    $$
+        select last_index_digest, last_index_etag, last_index_last_modified
+        from chart_repository
+        where name = 'repo1'
+    $$,
+    $$
+        values (null::text, null::text, null::text)
+    $$,
+    'Chart repository last index information should have been reset as the url changed'
+);
+select results_eq(
+    $$
+        select credentials, tls_config
+        from chart_repository
+        where name = 'repo1'
+    $$,
+    $$
+        values (null::text, null::jsonb)
+    $$,
+    'Chart repository credentials and TLS settings should have been reset as the url changed'
+);
 
 -- Update chart repository owned by organization (requesting user belongs to organization)
 select update_chart_repository(:'user1ID', '
@@ -92,6 +152,111 @@ select results_eq(
     'Chart repository should have been updated by user who belongs to owning organization'
 );
 
+-- Update chart repository display name only (url does not change)
+update chart_repository set last_index_digest = 'digest2' where name = 'repo2';
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo2",
+    "display_name": "Repo 2 updated again",
+    "url": "https://repo2.com/updated"
+}
+'::jsonb);
+select results_eq(
+    $$
+        select display_name, last_index_digest
+        from chart_repository
+        where name = 'repo2'
+    $$,
+    $$
+        values ('Repo 2 updated again', 'digest2')
+    $$,
+    'Chart repository last index information should be kept as the url did not change'
+);
+
+-- Update chart repository credentials and TLS settings
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo2",
+    "display_name": "Repo 2 updated again",
+    "url": "https://repo2.com/updated",
+    "credentials": "encrypted-credentials",
+    "tls_config": {"insecure_skip_verify": true},
+    "keyring": "keyring"
+}
+'::jsonb);
+select results_eq(
+    $$
+        select credentials, tls_config, keyring
+        from chart_repository
+        where name = 'repo2'
+    $$,
+    $$
+        values ('encrypted-credentials', '{"insecure_skip_verify": true}'::jsonb, 'keyring')
+    $$,
+    'Chart repository credentials, TLS settings and keyring should have been updated'
+);
+
+-- Update chart repository without providing credentials nor TLS settings
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo2",
+    "display_name": "Repo 2 updated once more",
+    "url": "https://repo2.com/updated"
+}
+'::jsonb);
+select results_eq(
+    $$
+        select credentials, tls_config
+        from chart_repository
+        where name = 'repo2'
+    $$,
+    $$
+        values ('encrypted-credentials', '{"insecure_skip_verify": true}'::jsonb)
+    $$,
+    'Chart repository credentials and TLS settings should be kept when not provided'
+);
+
+-- Update chart repository tracking interval
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo2",
+    "display_name": "Repo 2 updated again",
+    "url": "https://repo2.com/updated",
+    "tracking_interval": 600
+}
+'::jsonb);
+select results_eq(
+    $$
+        select tracking_interval
+        from chart_repository
+        where name = 'repo2'
+    $$,
+    $$
+        values ('10 minutes'::interval)
+    $$,
+    'Chart repository tracking interval should have been updated'
+);
+
+-- Update chart repository without providing the tracking interval
+select update_chart_repository(:'user1ID', '
+{
+    "name": "repo2",
+    "display_name": "Repo 2 updated again",
+    "url": "https://repo2.com/updated"
+}
+'::jsonb);
+select results_eq(
+    $$
+        select tracking_interval
+        from chart_repository
+        where name = 'repo2'
+    $$,
+    $$
+        values (null::interval)
+    $$,
+    'Chart repository tracking interval should have been reset to the default one'
+);
+
 -- Finish tests and rollback transaction
 select * from finish();
 rollback;
@@ -16,7 +16,8 @@ select add_organization(:'user1ID', '
     "display_name": "Organization 1",
     "description": "Description 1",
     "home_url": "https://org1.com",
-    "logo_image_id": "00000000-0000-0000-0000-000000000001"
+    "logo_image_id": "00000000-0000-0000-0000-000000000001",
+    "keyring": "keyring"
 }
 '::jsonb);
 
@@ -28,7 +29,8 @@ select results_eq(
             display_name,
             description,
             home_url,
-            logo_image_id
+            logo_image_id,
+            keyring
         from organization
     $$,
     $$
@@ -37,7 +39,8 @@ select results_eq(
             'Organization 1',
             'Description 1',
             'https://org1.com',
-            '00000000-0000-0000-0000-000000000001'::uuid
+            '00000000-0000-0000-0000-000000000001'::uuid,
+            'keyring'
         )
     $$,
     'Organization should exist'
@@ -7,8 +7,8 @@ select plan(2);
 \set image1ID '00000000-0000-0000-0000-000000000001'
 
 -- Seed some users and organizations
-insert into organization (organization_id, name, display_name, description, home_url, logo_image_id)
-values (:'org1ID', 'org1', 'Organization 1', 'Description 1', 'https://org1.com', :'image1ID');
+insert into organization (organization_id, name, display_name, description, home_url, logo_image_id, keyring)
+values (:'org1ID', 'org1', 'Organization 1', 'Description 1', 'https://org1.com', :'image1ID', 'keyring');
 
 -- Run some tests
 select is(
@@ -18,7 +18,8 @@ select is(
         "display_name": "Organization 1",
         "description": "Description 1",
         "home_url": "https://org1.com",
-        "logo_image_id": "00000000-0000-0000-0000-000000000001"
+        "logo_image_id": "00000000-0000-0000-0000-000000000001",
+        "keyring": "keyring"
     }
     '::jsonb,
     'Organization1 should exist'
@@ -19,7 +19,8 @@ select update_organization(:'user1ID', '
     "display_name": "Organization 1 updated",
     "description": "Description 1 updated",
     "home_url": "https://org1.com/updated",
-    "logo_image_id": "00000000-0000-0000-0000-000000000001"
+    "logo_image_id": "00000000-0000-0000-0000-000000000001",
+    "keyring": "keyring updated"
 }
 '::jsonb);
 
@@ -30,7 +31,8 @@ select results_eq(
             display_name,
             description,
             home_url,
-            logo_image_id
+            logo_image_id,
+            keyring
         from organization
     $$,
     $$
@@ -38,7 +40,8 @@ select results_eq(
             'Organization 1 updated',
             'Description 1 updated',
             'https://org1.com/updated',
-            '00000000-0000-0000-0000-000000000001'::uuid
+            '00000000-0000-0000-0000-000000000001'::uuid,
+            'keyring updated'
         )
     $$,
     'Organization should have been updated'
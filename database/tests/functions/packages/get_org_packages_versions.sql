@@ -0,0 +1,63 @@
+-- Start transaction and plan tests
+begin;
+select plan(2);
+
+-- Declare some variables
+\set org1ID '00000000-0000-0000-0000-000000000001'
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+\set package3ID '00000000-0000-0000-0000-000000000003'
+
+-- No packages at this point
+select is(
+    get_org_packages_versions(:'org1ID')::jsonb,
+    '[]'::jsonb,
+    'With no packages an empty json array is returned'
+);
+
+-- Seed some data
+insert into organization (organization_id, name, display_name)
+values (:'org1ID', 'org1', 'Organization 1');
+insert into chart_repository (chart_repository_id, name, display_name, url, organization_id)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com', :'org1ID');
+insert into package (package_id, name, latest_version, package_kind_id, organization_id)
+values (:'package1ID', 'package1', '1.0.0', 1, :'org1ID');
+insert into snapshot (package_id, version) values (:'package1ID', '1.0.0');
+insert into snapshot (package_id, version) values (:'package1ID', '0.9.0');
+insert into package (package_id, name, latest_version, package_kind_id, organization_id)
+values (:'package2ID', 'package2', '1.0.0', 2, :'org1ID');
+insert into snapshot (package_id, version) values (:'package2ID', '1.0.0');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package3ID', 'package3', '1.0.0', 0, :'repo1ID');
+insert into snapshot (package_id, version) values (:'package3ID', '1.0.0');
+
+-- Run some tests
+select is(
+    get_org_packages_versions(:'org1ID')::jsonb,
+    '[
+        {
+            "kind": 1,
+            "name": "package1",
+            "version": "0.9.0",
+            "organization_id": "00000000-0000-0000-0000-000000000001"
+        },
+        {
+            "kind": 1,
+            "name": "package1",
+            "version": "1.0.0",
+            "organization_id": "00000000-0000-0000-0000-000000000001"
+        },
+        {
+            "kind": 2,
+            "name": "package2",
+            "version": "1.0.0",
+            "organization_id": "00000000-0000-0000-0000-000000000001"
+        }
+    ]'::jsonb,
+    'Versions of packages owned by the organization outside chart repositories are returned'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
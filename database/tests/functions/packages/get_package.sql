@@ -1,13 +1,15 @@
 -- Start transaction and plan tests
 begin;
-select plan(4);
+select plan(7);
 
 -- Declare some variables
 \set org1ID '00000000-0000-0000-0000-000000000001'
 \set user1ID '00000000-0000-0000-0000-000000000001'
 \set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
 \set package1ID '00000000-0000-0000-0000-000000000001'
 \set package2ID '00000000-0000-0000-0000-000000000002'
+\set package3ID '00000000-0000-0000-0000-000000000003'
 \set maintainer1ID '00000000-0000-0000-0000-000000000001'
 \set maintainer2ID '00000000-0000-0000-0000-000000000002'
 \set image1ID '00000000-0000-0000-0000-000000000001'
@@ -65,7 +67,22 @@ insert into snapshot (
     readme,
     links,
     data,
-    deprecated
+    deprecated,
+    signature_status,
+    signature_key_fingerprint,
+    templates,
+    images,
+    lint,
+    annotations,
+    kube_version,
+    chart_type,
+    sources,
+    api_version,
+    maintainers,
+    license,
+    changes,
+    prerelease,
+    contains_security_updates
 ) values (
     :'package1ID',
     '1.0.0',
@@ -78,6 +95,28 @@ insert into snapshot (
     'readme-version-1.0.0',
     '{"link1": "https://link1", "link2": "https://link2"}',
     '{"key": "value"}',
+    true,
+    'verified',
+    'fingerprint',
+    '{"templates/deployment.yaml"}',
+    '{"docker.io/library/nginx:1.17"}',
+    '{"info": [{"path": "Chart.yaml", "message": "icon is recommended"}]}',
+    '{"category": "database"}',
+    '>=1.16.0-0',
+    'application',
+    '{"https://github.com/org1/package1"}',
+    'v2',
+    '[
+        {"name": "name1", "email": "email1", "url": "https://name1.com"},
+        {"name": "name2", "email": "email2"},
+        {"name": "name3"}
+    ]',
+    'Apache-2.0',
+    '[
+        {"kind": "added", "description": "Added feature"},
+        {"kind": "fixed", "description": "Fixed bug"}
+    ]',
+    false,
     true
 );
 insert into snapshot (
@@ -167,16 +206,43 @@ select is(
         "app_version": "12.1.0",
         "digest": "digest-package1-1.0.0",
         "deprecated": true,
+        "signed": true,
+        "signature_status": "verified",
+        "signature_key_fingerprint": "fingerprint",
+        "templates": ["templates/deployment.yaml"],
+        "images": ["docker.io/library/nginx:1.17"],
+        "lint": {
+            "info": [{"path": "Chart.yaml", "message": "icon is recommended"}]
+        },
+        "annotations": {
+            "category": "database"
+        },
+        "kube_version": ">=1.16.0-0",
+        "chart_type": "application",
+        "sources": ["https://github.com/org1/package1"],
+        "api_version": "v2",
+        "license": "Apache-2.0",
+        "changes": [
+            {"kind": "added", "description": "Added feature"},
+            {"kind": "fixed", "description": "Fixed bug"}
+        ],
+        "prerelease": false,
+        "contains_security_updates": true,
         "maintainers": [
             {
                 "name": "name1",
-                "email": "email1"
+                "email": "email1",
+                "url": "https://name1.com"
             },
             {
                 "name": "name2",
                 "email": "email2"
+            },
+            {
+                "name": "name3"
             }
         ],
+        "duplicates": null,
         "user_alias": "user1",
         "organization_name": null,
         "organization_display_name": null,
@@ -218,6 +284,21 @@ select is(
         "app_version": "12.0.0",
         "digest": "digest-package1-0.0.9",
         "deprecated": null,
+        "signed": false,
+        "signature_status": null,
+        "signature_key_fingerprint": null,
+        "templates": null,
+        "images": null,
+        "lint": null,
+        "annotations": null,
+        "kube_version": null,
+        "chart_type": null,
+        "sources": null,
+        "api_version": null,
+        "license": null,
+        "changes": null,
+        "prerelease": false,
+        "contains_security_updates": false,
         "maintainers": [
             {
                 "name": "name1",
@@ -228,6 +309,7 @@ select is(
                 "email": "email2"
             }
         ],
+        "duplicates": null,
         "user_alias": "user1",
         "organization_name": null,
         "organization_display_name": null,
@@ -261,10 +343,26 @@ select is(
             "key": "value"
         },
         "deprecated": null,
+        "signed": false,
+        "signature_status": null,
+        "signature_key_fingerprint": null,
+        "templates": null,
+        "images": null,
+        "lint": null,
+        "annotations": null,
+        "kube_version": null,
+        "chart_type": null,
+        "sources": null,
+        "api_version": null,
+        "license": null,
+        "changes": null,
+        "prerelease": false,
+        "contains_security_updates": false,
         "version": "1.0.0",
         "app_version": null,
         "available_versions": ["1.0.0"],
         "maintainers": null,
+        "duplicates": null,
         "user_alias": null,
         "organization_name": "org1",
         "organization_display_name": "Organization 1",
@@ -272,6 +370,60 @@ select is(
     }'::jsonb,
     'Last package2 version is returned as a json object'
 );
+select isnt_empty(
+    $$
+        select get_package('{
+            "package_kind": "falco",
+            "package_name": "package2"
+        }')
+    $$,
+    'Package2 is returned when the kind provided matches'
+);
+select is_empty(
+    $$
+        select get_package('{
+            "package_kind": "opa",
+            "package_name": "package2"
+        }')
+    $$,
+    'No package is returned when the kind provided does not match'
+);
+
+-- Seed a duplicate of package1 in other repository
+insert into chart_repository (chart_repository_id, name, display_name, url, user_id)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com', :'user1ID');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    chart_repository_id,
+    duplicate_group_id
+) values (
+    :'package3ID',
+    'Package 1',
+    '1.0.0',
+    0,
+    :'repo2ID',
+    :'package1ID'
+);
+insert into snapshot (package_id, version) values (:'package3ID', '1.0.0');
+select is(
+    (get_package('{
+        "package_name": "package-1",
+        "chart_repository_name": "repo1"
+    }')::jsonb)->'duplicates',
+    '[{
+        "package_id": "00000000-0000-0000-0000-000000000003",
+        "name": "Package 1",
+        "normalized_name": "package-1",
+        "chart_repository": {
+            "name": "repo2",
+            "display_name": "Repo 2"
+        }
+    }]'::jsonb,
+    'Duplicates of package1 in other repositories are returned'
+);
 
 -- Finish tests and rollback transaction
 select * from finish();
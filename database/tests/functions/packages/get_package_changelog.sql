@@ -0,0 +1,104 @@
+-- Start transaction and plan tests
+begin;
+select plan(4);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+
+-- No packages at this point
+select is_empty(
+    $$
+        select get_package_changelog('{
+            "chart_repository_name": "repo1",
+            "package_name": "package1"
+        }')
+    $$,
+    'If package requested does not exist no rows are returned'
+);
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package1ID', 'package1', '1.10.0', 0, :'repo1ID');
+insert into snapshot (package_id, version)
+values (:'package1ID', '0.9.0');
+insert into snapshot (package_id, version, changes)
+values (:'package1ID', '1.0.0', '[{"kind": "added", "description": "Initial chart"}]');
+insert into snapshot (package_id, version, prerelease, changes)
+values (:'package1ID', '1.1.0-rc.1', true, '[{"kind": "added", "description": "Ingress support"}]');
+insert into snapshot (package_id, version, contains_security_updates, changes)
+values (:'package1ID', '1.1.0', true, '[
+    {"kind": "added", "description": "Ingress support"},
+    {"kind": "security", "description": "Bump nginx image"}
+]');
+insert into snapshot (package_id, version, changes)
+values (:'package1ID', '1.10.0', '[{"kind": "fixed", "description": "Service port name"}]');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package2ID', 'package2', '1.0.0', 0, :'repo1ID');
+insert into snapshot (package_id, version)
+values (:'package2ID', '1.0.0');
+
+-- Run some tests
+select set_eq(
+    $$
+        select jsonb_array_elements(get_package_changelog('{
+            "chart_repository_name": "repo1",
+            "package_name": "package1"
+        }')::jsonb)
+    $$,
+    $$
+        values
+            ('{
+                "version": "1.10.0",
+                "prerelease": false,
+                "contains_security_updates": false,
+                "changes": [{"kind": "fixed", "description": "Service port name"}]
+            }'::jsonb),
+            ('{
+                "version": "1.1.0",
+                "prerelease": false,
+                "contains_security_updates": true,
+                "changes": [
+                    {"kind": "added", "description": "Ingress support"},
+                    {"kind": "security", "description": "Bump nginx image"}
+                ]
+            }'::jsonb),
+            ('{
+                "version": "1.1.0-rc.1",
+                "prerelease": true,
+                "contains_security_updates": false,
+                "changes": [{"kind": "added", "description": "Ingress support"}]
+            }'::jsonb),
+            ('{
+                "version": "1.0.0",
+                "prerelease": false,
+                "contains_security_updates": false,
+                "changes": [{"kind": "added", "description": "Initial chart"}]
+            }'::jsonb)
+    $$,
+    'Changes of package1 versions are returned'
+);
+select is(
+    get_package_changelog('{
+        "chart_repository_name": "repo1",
+        "package_name": "package2"
+    }')::jsonb,
+    '[]'::jsonb,
+    'Empty changelog is returned for packages without changes'
+);
+select is_empty(
+    $$
+        select get_package_changelog('{
+            "package_kind": "falco",
+            "package_name": "package1"
+        }')
+    $$,
+    'Chart packages are not returned when the repository is not provided'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
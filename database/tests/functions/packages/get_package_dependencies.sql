@@ -0,0 +1,79 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com/');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package1ID', 'package1', '1.0.0', 0, :'repo1ID');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package2ID', 'package2', '2.0.0', 0, :'repo2ID');
+insert into snapshot (package_id, version, dependencies)
+values (:'package1ID', '1.0.0', '[
+    {"name": "package2", "version": "^2.0.0", "repository": "https://repo2.com"},
+    {"name": "package3", "version": "1.0.0", "repository": "@local"}
+]');
+insert into snapshot (package_id, version)
+values (:'package2ID', '2.0.0');
+
+-- Run some tests
+select is(
+    get_package_dependencies('{
+        "chart_repository_name": "repo1",
+        "package_name": "package1",
+        "version": "1.0.0"
+    }')::jsonb,
+    '[{
+        "name": "package2",
+        "version": "^2.0.0",
+        "repository": "https://repo2.com",
+        "package": {
+            "package_id": "00000000-0000-0000-0000-000000000002",
+            "name": "package2",
+            "normalized_name": "package2",
+            "version": "2.0.0",
+            "chart_repository": {
+                "name": "repo2",
+                "display_name": "Repo 2"
+            }
+        }
+    }, {
+        "name": "package3",
+        "version": "1.0.0",
+        "repository": "@local",
+        "package": null
+    }]'::jsonb,
+    'Dependencies of package1 version 1.0.0 are returned, resolved when possible'
+);
+select is(
+    get_package_dependencies('{
+        "chart_repository_name": "repo2",
+        "package_name": "package2",
+        "version": "2.0.0"
+    }')::jsonb,
+    '[]'::jsonb,
+    'Empty json array is returned when the package version has no dependencies'
+);
+select is_empty(
+    $$
+        select get_package_dependencies('{
+            "chart_repository_name": "repo1",
+            "package_name": "package1",
+            "version": "2.0.0"
+        }')
+    $$,
+    'No rows are returned when the package version does not exist'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
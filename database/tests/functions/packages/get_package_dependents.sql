@@ -0,0 +1,75 @@
+-- Start transaction and plan tests
+begin;
+select plan(3);
+
+-- Declare some variables
+\set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+\set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
+\set package3ID '00000000-0000-0000-0000-000000000003'
+
+-- Seed some data
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com/');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package1ID', 'package1', '1.0.0', 0, :'repo1ID');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package2ID', 'package2', '2.0.0', 0, :'repo2ID');
+insert into package (package_id, name, latest_version, package_kind_id, chart_repository_id)
+values (:'package3ID', 'package3', '2.0.0', 0, :'repo1ID');
+insert into snapshot (package_id, version, dependencies)
+values (:'package1ID', '1.0.0', '[
+    {"name": "package2", "version": "^2.0.0", "repository": "https://repo2.com"}
+]');
+insert into snapshot (package_id, version)
+values (:'package2ID', '2.0.0');
+insert into snapshot (package_id, version, dependencies)
+values (:'package3ID', '1.0.0', '[
+    {"name": "package2", "version": "^1.0.0", "repository": "https://repo2.com"}
+]');
+insert into snapshot (package_id, version)
+values (:'package3ID', '2.0.0');
+
+-- Run some tests
+select is(
+    get_package_dependents('{
+        "chart_repository_name": "repo2",
+        "package_name": "package2"
+    }')::jsonb,
+    '[{
+        "package_id": "00000000-0000-0000-0000-000000000001",
+        "name": "package1",
+        "normalized_name": "package1",
+        "version": "1.0.0",
+        "dependency_version": "^2.0.0",
+        "chart_repository": {
+            "name": "repo1",
+            "display_name": "Repo 1"
+        }
+    }]'::jsonb,
+    'Only packages whose latest version depends on package2 are returned'
+);
+select is(
+    get_package_dependents('{
+        "chart_repository_name": "repo1",
+        "package_name": "package1"
+    }')::jsonb,
+    '[]'::jsonb,
+    'Empty json array is returned when no packages depend on package1'
+);
+select is_empty(
+    $$
+        select get_package_dependents('{
+            "chart_repository_name": "repo1",
+            "package_name": "package4"
+        }')
+    $$,
+    'No rows are returned when the package does not exist'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
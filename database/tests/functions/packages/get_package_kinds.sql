@@ -0,0 +1,27 @@
+-- Start transaction and plan tests
+begin;
+select plan(2);
+
+-- Check the package kinds registered by default are returned
+select is(
+    get_package_kinds()::jsonb,
+    '[
+        {"kind": 0, "name": "Helm charts", "slug": "chart"},
+        {"kind": 1, "name": "Falco rules", "slug": "falco"},
+        {"kind": 2, "name": "OPA policies", "slug": "opa"},
+        {"kind": 3, "name": "Operators", "slug": "operator"}
+    ]'::jsonb,
+    'Package kinds registered should be returned as a json array'
+);
+
+-- Register a new package kind and check it's returned as well
+insert into package_kind values (4, 'Kubectl plugins', 'krew');
+select is(
+    get_package_kinds()::jsonb->4,
+    '{"kind": 4, "name": "Kubectl plugins", "slug": "krew"}'::jsonb,
+    'New package kinds should be returned without any other change'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -1,16 +1,22 @@
 -- Start transaction and plan tests
 begin;
-select plan(11);
+select plan(16);
 
 -- Declare some variables
 \set org1ID '00000000-0000-0000-0000-000000000001'
 \set repo1ID '00000000-0000-0000-0000-000000000001'
+\set repo2ID '00000000-0000-0000-0000-000000000002'
+\set repo3ID '00000000-0000-0000-0000-000000000003'
 
 -- Seed some data
 insert into organization (organization_id, name, display_name, description, home_url)
 values (:'org1ID', 'org1', 'Organization 1', 'Description 1', 'https://org1.com');
 insert into chart_repository (chart_repository_id, name, display_name, url)
 values (:'repo1ID', 'repo1', 'Repo 1', 'https://repo1.com');
+insert into chart_repository (chart_repository_id, name, display_name, url)
+values (:'repo2ID', 'repo2', 'Repo 2', 'https://repo2.com');
+insert into chart_repository (chart_repository_id, name, display_name, url, package_kind_id)
+values (:'repo3ID', 'repo3', 'Repo 3', 'https://github.com/org3/repo3.git', 1);
 
 -- Register package
 select register_package('
@@ -35,14 +41,47 @@ select register_package('
     "app_version": "12.1.0",
     "digest": "digest-package1-1.0.0",
     "deprecated": false,
+    "signature_status": "verified",
+    "signature_key_fingerprint": "fingerprint",
+    "default_values": "key: value",
+    "values_schema": {"type": "object"},
+    "templates": ["templates/deployment.yaml", "templates/service.yaml"],
+    "dependencies": [
+        {
+            "name": "dependency1",
+            "version": "^1.0.0",
+            "repository": "https://repo1.com"
+        }
+    ],
+    "images": ["docker.io/library/nginx:1.17", "quay.io/coreos/etcd:v3.4.7"],
+    "lint": {
+        "warnings": [{"path": "templates/", "message": "directory not found"}]
+    },
+    "annotations": {"category": "database"},
+    "kube_version": ">=1.16.0-0",
+    "chart_type": "application",
+    "sources": ["https://github.com/org1/package1"],
+    "api_version": "v2",
+    "license": "Apache-2.0",
+    "changes": [
+        {"kind": "added", "description": "Added feature"},
+        {"kind": "fixed", "description": "Fixed bug"}
+    ],
+    "prerelease": true,
+    "contains_security_updates": true,
     "maintainers": [
         {
             "name": "name1",
-            "email": "email1"
+            "email": "email1",
+            "url": "https://name1.com"
         },
         {
             "name": "name2",
             "email": "email2"
+        },
+        {
+            "name": "name3",
+            "email": ""
         }
     ],
     "chart_repository": {
@@ -89,7 +128,25 @@ select results_eq(
             s.readme,
             s.links,
             s.data,
-            s.deprecated
+            s.deprecated,
+            s.signature_status,
+            s.signature_key_fingerprint,
+            s.default_values,
+            s.values_schema,
+            s.templates,
+            s.dependencies,
+            s.images,
+            s.lint,
+            s.annotations,
+            s.kube_version,
+            s.chart_type,
+            s.sources,
+            s.api_version,
+            s.maintainers,
+            s.license,
+            s.changes,
+            s.prerelease,
+            s.contains_security_updates
         from snapshot s
         join package p using (package_id)
         where name='package1'
@@ -107,7 +164,32 @@ select results_eq(
             'readme-version-1.0.0',
             '{"link1": "https://link1", "link2": "https://link2"}'::jsonb,
             '{"key": "value"}'::jsonb,
-            false
+            false,
+            'verified',
+            'fingerprint',
+            'key: value',
+            '{"type": "object"}'::jsonb,
+            '{templates/deployment.yaml,templates/service.yaml}'::text[],
+            '[{"name": "dependency1", "version": "^1.0.0", "repository": "https://repo1.com"}]'::jsonb,
+            '{docker.io/library/nginx:1.17,quay.io/coreos/etcd:v3.4.7}'::text[],
+            '{"warnings": [{"path": "templates/", "message": "directory not found"}]}'::jsonb,
+            '{"category": "database"}'::jsonb,
+            '>=1.16.0-0',
+            'application',
+            '{https://github.com/org1/package1}'::text[],
+            'v2',
+            '[
+                {"name": "name1", "email": "email1", "url": "https://name1.com"},
+                {"name": "name2", "email": "email2"},
+                {"name": "name3"}
+            ]'::jsonb,
+            'Apache-2.0',
+            '[
+                {"kind": "added", "description": "Added feature"},
+                {"kind": "fixed", "description": "Fixed bug"}
+            ]'::jsonb,
+            true,
+            true
         )
     $$,
     'Snapshot should exist'
@@ -353,6 +435,136 @@ select results_eq(
     'Package that belongs to organization should exist'
 );
 
+-- Register package that belongs to a Falco repository and check it succeeded
+select register_package('
+{
+    "kind": 1,
+    "name": "package6",
+    "version": "1.0.0",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000003"
+    }
+}
+');
+select register_package('
+{
+    "kind": 1,
+    "name": "package6",
+    "version": "0.9.0",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000003"
+    }
+}
+');
+select results_eq(
+    $$
+        select
+            name,
+            latest_version,
+            package_kind_id,
+            organization_id,
+            chart_repository_id
+        from package
+        where name='package6'
+    $$,
+    $$
+        values (
+            'package6',
+            '1.0.0',
+            1,
+            null::uuid,
+            '00000000-0000-0000-0000-000000000003'::uuid
+        )
+    $$,
+    'Package that belongs to Falco repository should exist'
+);
+select results_eq(
+    $$
+        select version
+        from snapshot
+        where package_id = (select package_id from package where name='package6')
+        order by version asc
+    $$,
+    $$ values ('0.9.0'), ('1.0.0') $$,
+    'Package that belongs to Falco repository should have both versions'
+);
+
+-- Register packages duplicated across repositories and check they are linked
+select register_package('
+{
+    "kind": 0,
+    "name": "package4",
+    "version": "1.0.0",
+    "content_fingerprint": "fingerprint1",
+    "upstream_source": "github.com/org1/package4#package4",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000001"
+    }
+}
+');
+select results_eq(
+    $$
+        select content_fingerprint, upstream_source, duplicate_group_id = package_id
+        from package
+        where name='package4'
+    $$,
+    $$ values ('fingerprint1', 'github.com/org1/package4#package4', true) $$,
+    'Package without duplicates should be in its own group'
+);
+select register_package('
+{
+    "kind": 0,
+    "name": "package4-fork",
+    "version": "1.0.0",
+    "content_fingerprint": "fingerprint2",
+    "upstream_source": "github.com/org1/package4#package4",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000002"
+    }
+}
+');
+select register_package('
+{
+    "kind": 0,
+    "name": "package4-copy",
+    "version": "1.0.0",
+    "content_fingerprint": "fingerprint1",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000002"
+    }
+}
+');
+select register_package('
+{
+    "kind": 0,
+    "name": "package5",
+    "version": "1.0.0",
+    "content_fingerprint": "fingerprint3",
+    "chart_repository": {
+        "chart_repository_id": "00000000-0000-0000-0000-000000000002"
+    }
+}
+');
+select results_eq(
+    $$
+        select name
+        from package
+        where duplicate_group_id = (select package_id from package where name = 'package4')
+        order by name asc
+    $$,
+    $$ values ('package4'), ('package4-copy'), ('package4-fork') $$,
+    'Packages sharing contents fingerprint or upstream source should be linked'
+);
+select results_eq(
+    $$
+        select duplicate_group_id = package_id
+        from package
+        where name='package5'
+    $$,
+    $$ values (true) $$,
+    'Package not sharing contents or upstream source should be in its own group'
+);
+
 -- Finish tests and rollback transaction
 select * from finish();
 rollback;
@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(20);
+select plan(46);
 
 -- Declare some variables
 \set user1ID '00000000-0000-0000-0000-000000000001'
@@ -70,7 +70,10 @@ insert into snapshot (
     app_version,
     digest,
     readme,
-    links
+    links,
+    data,
+    images,
+    signature_status
 ) values (
     :'package1ID',
     '1.0.0',
@@ -81,7 +84,18 @@ insert into snapshot (
     '12.1.0',
     'digest-package1-1.0.0',
     'readme',
-    '{"link1": "https://link1", "link2": "https://link2"}'
+    '{"link1": "https://link1", "link2": "https://link2"}',
+    '{
+        "kinds": ["CustomResourceDefinition", "Deployment"],
+        "crds": [{
+            "name": "crontabs.stable.example.com",
+            "group": "stable.example.com",
+            "version": "v1",
+            "kind": "CronTab"
+        }]
+    }',
+    '{"docker.io/library/nginx:1.17", "quay.io/coreos/etcd:v3.4.7"}',
+    'signed'
 );
 insert into snapshot (
     package_id,
@@ -136,7 +150,8 @@ insert into snapshot (
     digest,
     readme,
     links,
-    deprecated
+    deprecated,
+    signature_status
 ) values (
     :'package2ID',
     '1.0.0',
@@ -148,7 +163,8 @@ insert into snapshot (
     'digest-package2-1.0.0',
     'readme',
     '{"link1": "https://link1", "link2": "https://link2"}',
-    true
+    true,
+    'verified'
 );
 insert into snapshot (
     package_id,
@@ -228,6 +244,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": true,
+                "duplicates": 0,
                 "user_alias": null,
                 "organization_name": "org1",
                 "organization_display_name": "Organization 1",
@@ -248,6 +265,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": "user1",
                 "organization_name": null,
                 "organization_display_name": null,
@@ -267,6 +285,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": null,
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": null,
                 "organization_name": "org1",
                 "organization_display_name": "Organization 1",
@@ -312,6 +331,26 @@ select is(
                     "name": "Repo2",
                     "total": 1
                 }]
+            }, {
+                "title": "Resource Kind",
+                "filter_key": "resource_kind",
+                "options": [{
+                    "id": "CustomResourceDefinition",
+                    "name": "CustomResourceDefinition",
+                    "total": 1
+                }, {
+                    "id": "Deployment",
+                    "name": "Deployment",
+                    "total": 1
+                }]
+            }, {
+                "title": "CRD",
+                "filter_key": "crd",
+                "options": [{
+                    "id": "crontabs.stable.example.com",
+                    "name": "CronTab",
+                    "total": 1
+                }]
             }]
         },
         "metadata": {
@@ -342,6 +381,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": true,
+                "duplicates": 0,
                 "user_alias": null,
                 "organization_name": "org1",
                 "organization_display_name": "Organization 1",
@@ -362,6 +402,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": "user1",
                 "organization_name": null,
                 "organization_display_name": null,
@@ -406,6 +447,26 @@ select is(
                     "name": "Repo2",
                     "total": 1
                 }]
+            }, {
+                "title": "Resource Kind",
+                "filter_key": "resource_kind",
+                "options": [{
+                    "id": "CustomResourceDefinition",
+                    "name": "CustomResourceDefinition",
+                    "total": 1
+                }, {
+                    "id": "Deployment",
+                    "name": "Deployment",
+                    "total": 1
+                }]
+            }, {
+                "title": "CRD",
+                "filter_key": "crd",
+                "options": [{
+                    "id": "crontabs.stable.example.com",
+                    "name": "CronTab",
+                    "total": 1
+                }]
             }]
         },
         "metadata": {
@@ -435,6 +496,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": "user1",
                 "organization_name": null,
                 "organization_display_name": null,
@@ -471,6 +533,26 @@ select is(
                     "name": "Repo1",
                     "total": 1
                 }]
+            }, {
+                "title": "Resource Kind",
+                "filter_key": "resource_kind",
+                "options": [{
+                    "id": "CustomResourceDefinition",
+                    "name": "CustomResourceDefinition",
+                    "total": 1
+                }, {
+                    "id": "Deployment",
+                    "name": "Deployment",
+                    "total": 1
+                }]
+            }, {
+                "title": "CRD",
+                "filter_key": "crd",
+                "options": [{
+                    "id": "crontabs.stable.example.com",
+                    "name": "CronTab",
+                    "total": 1
+                }]
             }]
         },
         "metadata": {
@@ -520,6 +602,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": "user1",
                 "organization_name": null,
                 "organization_display_name": null,
@@ -558,6 +641,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": null,
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": null,
                 "organization_name": "org1",
                 "organization_display_name": "Organization 1",
@@ -593,6 +677,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": "user1",
                 "organization_name": null,
                 "organization_display_name": null,
@@ -632,6 +717,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": "user1",
                 "organization_name": null,
                 "organization_display_name": null,
@@ -674,6 +760,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": true,
+                "duplicates": 0,
                 "user_alias": null,
                 "organization_name": "org1",
                 "organization_display_name": "Organization 1",
@@ -718,6 +805,26 @@ select is(
                     "name": "Repo2",
                     "total": 1
                 }]
+            }, {
+                "title": "Resource Kind",
+                "filter_key": "resource_kind",
+                "options": [{
+                    "id": "CustomResourceDefinition",
+                    "name": "CustomResourceDefinition",
+                    "total": 1
+                }, {
+                    "id": "Deployment",
+                    "name": "Deployment",
+                    "total": 1
+                }]
+            }, {
+                "title": "CRD",
+                "filter_key": "crd",
+                "options": [{
+                    "id": "crontabs.stable.example.com",
+                    "name": "CronTab",
+                    "total": 1
+                }]
             }]
         },
         "metadata": {
@@ -769,6 +876,26 @@ select is(
                     "name": "Repo1",
                     "total": 1
                 }]
+            }, {
+                "title": "Resource Kind",
+                "filter_key": "resource_kind",
+                "options": [{
+                    "id": "CustomResourceDefinition",
+                    "name": "CustomResourceDefinition",
+                    "total": 1
+                }, {
+                    "id": "Deployment",
+                    "name": "Deployment",
+                    "total": 1
+                }]
+            }, {
+                "title": "CRD",
+                "filter_key": "crd",
+                "options": [{
+                    "id": "crontabs.stable.example.com",
+                    "name": "CronTab",
+                    "total": 1
+                }]
             }]
         },
         "metadata": {
@@ -819,6 +946,26 @@ select is(
                     "name": "Repo1",
                     "total": 1
                 }]
+            }, {
+                "title": "Resource Kind",
+                "filter_key": "resource_kind",
+                "options": [{
+                    "id": "CustomResourceDefinition",
+                    "name": "CustomResourceDefinition",
+                    "total": 1
+                }, {
+                    "id": "Deployment",
+                    "name": "Deployment",
+                    "total": 1
+                }]
+            }, {
+                "title": "CRD",
+                "filter_key": "crd",
+                "options": [{
+                    "id": "crontabs.stable.example.com",
+                    "name": "CronTab",
+                    "total": 1
+                }]
             }]
         },
         "metadata": {
@@ -868,6 +1015,26 @@ select is(
                     "name": "Repo1",
                     "total": 1
                 }]
+            }, {
+                "title": "Resource Kind",
+                "filter_key": "resource_kind",
+                "options": [{
+                    "id": "CustomResourceDefinition",
+                    "name": "CustomResourceDefinition",
+                    "total": 1
+                }, {
+                    "id": "Deployment",
+                    "name": "Deployment",
+                    "total": 1
+                }]
+            }, {
+                "title": "CRD",
+                "filter_key": "crd",
+                "options": [{
+                    "id": "crontabs.stable.example.com",
+                    "name": "CronTab",
+                    "total": 1
+                }]
             }]
         },
         "metadata": {
@@ -920,6 +1087,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": true,
+                "duplicates": 0,
                 "user_alias": null,
                 "organization_name": "org1",
                 "organization_display_name": "Organization 1",
@@ -940,6 +1108,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": "user1",
                 "organization_name": null,
                 "organization_display_name": null,
@@ -979,6 +1148,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": true,
+                "duplicates": 0,
                 "user_alias": null,
                 "organization_name": "org1",
                 "organization_display_name": "Organization 1",
@@ -1038,6 +1208,7 @@ select is(
                 "version": "1.0.0",
                 "app_version": "12.1.0",
                 "deprecated": null,
+                "duplicates": 0,
                 "user_alias": "user1",
                 "organization_name": null,
                 "organization_display_name": null,
@@ -1123,6 +1294,26 @@ select is(
                     "name": "Repo2",
                     "total": 1
                 }]
+            }, {
+                "title": "Resource Kind",
+                "filter_key": "resource_kind",
+                "options": [{
+                    "id": "CustomResourceDefinition",
+                    "name": "CustomResourceDefinition",
+                    "total": 1
+                }, {
+                    "id": "Deployment",
+                    "name": "Deployment",
+                    "total": 1
+                }]
+            }, {
+                "title": "CRD",
+                "filter_key": "crd",
+                "options": [{
+                    "id": "crontabs.stable.example.com",
+                    "name": "CronTab",
+                    "total": 1
+                }]
             }]
         },
         "metadata": {
@@ -1134,6 +1325,542 @@ select is(
     'Limit: 1 Offset: 2 Text: kw1 | No packages expected - Facets expected'
 );
 
+-- Tests with signed filter
+select is(
+    search_packages('{
+        "text": "kw1",
+        "deprecated": true,
+        "signed": true
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [{
+                "package_id": "00000000-0000-0000-0000-000000000002",
+                "kind": 0,
+                "name": "package2",
+                "normalized_name": "package2",
+                "logo_image_id": "00000000-0000-0000-0000-000000000002",
+                "stars": 11,
+                "display_name": "Package 2",
+                "description": "description",
+                "version": "1.0.0",
+                "app_version": "12.1.0",
+                "deprecated": true,
+                "duplicates": 0,
+                "user_alias": null,
+                "organization_name": "org1",
+                "organization_display_name": "Organization 1",
+                "chart_repository": {
+                    "name": "repo2",
+                    "display_name": "Repo 2"
+                }
+            }],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 1
+        }
+    }'::jsonb,
+    'Text: kw1 Signed: true | Package 2 expected (package 1 provenance file not verified)'
+);
+
+-- Tests with resource kinds and crds filters
+select is(
+    search_packages('{
+        "resource_kinds": ["Deployment", "StatefulSet"]
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [{
+                "package_id": "00000000-0000-0000-0000-000000000001",
+                "kind": 0,
+                "name": "package1",
+                "normalized_name": "package1",
+                "logo_image_id": "00000000-0000-0000-0000-000000000001",
+                "stars": 10,
+                "display_name": "Package 1",
+                "description": "description",
+                "version": "1.0.0",
+                "app_version": "12.1.0",
+                "deprecated": null,
+                "duplicates": 0,
+                "user_alias": "user1",
+                "organization_name": null,
+                "organization_display_name": null,
+                "chart_repository": {
+                    "name": "repo1",
+                    "display_name": "Repo 1"
+                }
+            }],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 1
+        }
+    }'::jsonb,
+    'Resource kinds: Deployment, StatefulSet | Package 1 expected'
+);
+select is(
+    search_packages('{
+        "resource_kinds": ["StatefulSet"]
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 0
+        }
+    }'::jsonb,
+    'Resource kinds: StatefulSet | No packages expected'
+);
+select is(
+    search_packages('{
+        "crds": ["CronTab"]
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [{
+                "package_id": "00000000-0000-0000-0000-000000000001",
+                "kind": 0,
+                "name": "package1",
+                "normalized_name": "package1",
+                "logo_image_id": "00000000-0000-0000-0000-000000000001",
+                "stars": 10,
+                "display_name": "Package 1",
+                "description": "description",
+                "version": "1.0.0",
+                "app_version": "12.1.0",
+                "deprecated": null,
+                "duplicates": 0,
+                "user_alias": "user1",
+                "organization_name": null,
+                "organization_display_name": null,
+                "chart_repository": {
+                    "name": "repo1",
+                    "display_name": "Repo 1"
+                }
+            }],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 1
+        }
+    }'::jsonb,
+    'CRDs: CronTab | Package 1 expected'
+);
+select is(
+    search_packages('{
+        "crds": ["certificates.cert-manager.io"]
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 0
+        }
+    }'::jsonb,
+    'CRDs: certificates.cert-manager.io | No packages expected'
+);
+
+-- Tests with images filter
+select is(
+    search_packages('{
+        "images": ["docker.io/library/nginx:1.17"]
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [{
+                "package_id": "00000000-0000-0000-0000-000000000001",
+                "kind": 0,
+                "name": "package1",
+                "normalized_name": "package1",
+                "logo_image_id": "00000000-0000-0000-0000-000000000001",
+                "stars": 10,
+                "display_name": "Package 1",
+                "description": "description",
+                "version": "1.0.0",
+                "app_version": "12.1.0",
+                "deprecated": null,
+                "duplicates": 0,
+                "user_alias": "user1",
+                "organization_name": null,
+                "organization_display_name": null,
+                "chart_repository": {
+                    "name": "repo1",
+                    "display_name": "Repo 1"
+                }
+            }],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 1
+        }
+    }'::jsonb,
+    'Images: docker.io/library/nginx:1.17 | Package 1 expected'
+);
+select is(
+    search_packages('{
+        "images": ["quay.io/coreos/etcd"]
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [{
+                "package_id": "00000000-0000-0000-0000-000000000001",
+                "kind": 0,
+                "name": "package1",
+                "normalized_name": "package1",
+                "logo_image_id": "00000000-0000-0000-0000-000000000001",
+                "stars": 10,
+                "display_name": "Package 1",
+                "description": "description",
+                "version": "1.0.0",
+                "app_version": "12.1.0",
+                "deprecated": null,
+                "duplicates": 0,
+                "user_alias": "user1",
+                "organization_name": null,
+                "organization_display_name": null,
+                "chart_repository": {
+                    "name": "repo1",
+                    "display_name": "Repo 1"
+                }
+            }],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 1
+        }
+    }'::jsonb,
+    'Images: quay.io/coreos/etcd | Package 1 expected'
+);
+select is(
+    search_packages('{
+        "images": ["docker.io/library/nginx:1.16"]
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 0
+        }
+    }'::jsonb,
+    'Images: docker.io/library/nginx:1.16 | No packages expected'
+);
+select is(
+    search_packages('{
+        "images": ["docker.io/library/ngin_"]
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 0
+        }
+    }'::jsonb,
+    'Images: docker.io/library/ngin_ | No packages expected (wildcards are matched literally)'
+);
+select is(
+    search_packages('{
+        "images": ["quay.io/%"]
+    }')::jsonb,
+    '{
+        "data": {
+            "packages": [],
+            "facets": null
+        },
+        "metadata": {
+            "limit": null,
+            "offset": null,
+            "total": 0
+        }
+    }'::jsonb,
+    'Images: quay.io/% | No packages expected (wildcards are matched literally)'
+);
+
+-- Tests with lint clean filter
+update snapshot set lint = '{"info": [{"path": "Chart.yaml", "message": "icon is recommended"}]}'
+where package_id = :'package1ID' and version = '1.0.0';
+update snapshot set lint = '{"warnings": [{"path": "templates/", "message": "directory not found"}]}'
+where package_id = :'package2ID' and version = '1.0.0';
+select results_eq(
+    $$
+        select p->>'package_id'
+        from jsonb_array_elements(search_packages('{
+            "deprecated": true,
+            "lint_clean": true
+        }')::jsonb->'data'->'packages') p
+    $$,
+    $$
+        values ('00000000-0000-0000-0000-000000000001')
+    $$,
+    'Lint clean: true | Package 1 expected'
+);
+
+-- Tests with chart types and kubernetes version filters
+update snapshot set chart_type = 'application', kube_version = '>=1.16.0-0'
+where package_id = :'package1ID' and version = '1.0.0';
+update snapshot set chart_type = 'library', kube_version = '<1.16.0'
+where package_id = :'package2ID' and version = '1.0.0';
+select results_eq(
+    $$
+        select p->>'package_id'
+        from jsonb_array_elements(search_packages('{
+            "deprecated": true,
+            "chart_types": ["library"]
+        }')::jsonb->'data'->'packages') p
+    $$,
+    $$
+        values ('00000000-0000-0000-0000-000000000002')
+    $$,
+    'Chart types: library | Package 2 expected'
+);
+select results_eq(
+    $$
+        select p->>'package_id'
+        from jsonb_array_elements(search_packages('{
+            "package_kinds": [0],
+            "deprecated": true,
+            "kube_version": "1.18.0"
+        }')::jsonb->'data'->'packages') p
+    $$,
+    $$
+        values ('00000000-0000-0000-0000-000000000001')
+    $$,
+    'Kind: chart | Kubernetes version: 1.18.0 | Package 1 expected'
+);
+select results_eq(
+    $$
+        select p->>'package_id'
+        from jsonb_array_elements(search_packages('{
+            "package_kinds": [0],
+            "deprecated": true,
+            "kube_version": "1.15.0"
+        }')::jsonb->'data'->'packages') p
+    $$,
+    $$
+        values ('00000000-0000-0000-0000-000000000002')
+    $$,
+    'Kind: chart | Kubernetes version: 1.15.0 | Package 2 expected'
+);
+
+-- Link package1 and package2 as duplicates
+update package set duplicate_group_id = :'package1ID'
+where package_id in (:'package1ID', :'package2ID');
+select results_eq(
+    $$
+        select p->>'package_id', (p->>'duplicates')::int
+        from jsonb_array_elements(search_packages('{
+            "package_kinds": [0],
+            "deprecated": true
+        }')::jsonb->'data'->'packages') p
+    $$,
+    $$
+        values ('00000000-0000-0000-0000-000000000002', 1)
+    $$,
+    'Kind: chart | Duplicates collapsed | Package 2 expected (most starred) with 1 duplicate'
+);
+select is(
+    search_packages('{
+        "package_kinds": [0],
+        "deprecated": true
+    }')::jsonb->'metadata'->>'total',
+    '1',
+    'Kind: chart | Duplicates collapsed | Total should not include duplicates'
+);
+select results_eq(
+    $$
+        select p->>'package_id', (p->>'duplicates')::int
+        from jsonb_array_elements(search_packages('{
+            "package_kinds": [0],
+            "deprecated": true,
+            "include_duplicates": true
+        }')::jsonb->'data'->'packages') p
+    $$,
+    $$
+        values
+            ('00000000-0000-0000-0000-000000000002', 1),
+            ('00000000-0000-0000-0000-000000000001', 1)
+    $$,
+    'Kind: chart | Duplicates included | Packages 2 and 1 expected'
+);
+select results_eq(
+    $$
+        select o->>'id', (o->>'total')::int
+        from
+            jsonb_array_elements(search_packages('{
+                "facets": true,
+                "deprecated": true
+            }')::jsonb->'data'->'facets') f,
+            jsonb_array_elements(f->'options') o
+        where f->>'filter_key' = 'kind'
+        order by o->>'id'
+    $$,
+    $$
+        values ('0', 1), ('1', 1)
+    $$,
+    'Facets: true | Duplicates collapsed | Kind facet should count linked packages 1 and 2 once'
+);
+select results_eq(
+    $$
+        select o->>'id', (o->>'total')::int
+        from
+            jsonb_array_elements(search_packages('{
+                "facets": true,
+                "deprecated": true
+            }')::jsonb->'data'->'facets') f,
+            jsonb_array_elements(f->'options') o
+        where f->>'filter_key' = 'repo'
+        order by o->>'id'
+    $$,
+    $$
+        values ('repo2', 1)
+    $$,
+    'Facets: true | Duplicates collapsed | Only repo2 (most starred package) expected in repository facet'
+);
+select results_eq(
+    $$
+        select o->>'id', (o->>'total')::int
+        from
+            jsonb_array_elements(search_packages('{
+                "facets": true,
+                "deprecated": true,
+                "include_duplicates": true
+            }')::jsonb->'data'->'facets') f,
+            jsonb_array_elements(f->'options') o
+        where f->>'filter_key' = 'kind'
+        order by o->>'id'
+    $$,
+    $$
+        values ('0', 2), ('1', 1)
+    $$,
+    'Facets: true | Duplicates included | Kind facet should count packages 1 and 2'
+);
+select results_eq(
+    $$
+        select o->>'id', (o->>'total')::int
+        from
+            jsonb_array_elements(search_packages('{
+                "facets": true,
+                "deprecated": true,
+                "include_duplicates": true
+            }')::jsonb->'data'->'facets') f,
+            jsonb_array_elements(f->'options') o
+        where f->>'filter_key' = 'repo'
+        order by o->>'id'
+    $$,
+    $$
+        values ('repo1', 1), ('repo2', 1)
+    $$,
+    'Facets: true | Duplicates included | Both repositories expected in repository facet'
+);
+
+-- Tests with resource kinds and crds facets
+update snapshot set data = '{"kinds": ["Deployment", "Service"], "crds": null}'
+where package_id = :'package2ID';
+select results_eq(
+    $$
+        select o->>'id', (o->>'total')::int
+        from
+            jsonb_array_elements(search_packages('{
+                "facets": true,
+                "deprecated": true,
+                "include_duplicates": true
+            }')::jsonb->'data'->'facets') f,
+            jsonb_array_elements(f->'options') o
+        where f->>'filter_key' = 'resource_kind'
+    $$,
+    $$
+        values ('Deployment', 2), ('CustomResourceDefinition', 1), ('Service', 1)
+    $$,
+    'Facets: true | Duplicates included | Resource kinds of packages 1 and 2 expected in resource kind facet'
+);
+select results_eq(
+    $$
+        select o->>'id', o->>'name', (o->>'total')::int
+        from
+            jsonb_array_elements(search_packages('{
+                "facets": true,
+                "deprecated": true,
+                "include_duplicates": true
+            }')::jsonb->'data'->'facets') f,
+            jsonb_array_elements(f->'options') o
+        where f->>'filter_key' = 'crd'
+    $$,
+    $$
+        values ('crontabs.stable.example.com', 'CronTab', 1)
+    $$,
+    'Facets: true | Duplicates included | Package 1 CRDs expected in crd facet'
+);
+select results_eq(
+    $$
+        select o->>'id', (o->>'total')::int
+        from
+            jsonb_array_elements(search_packages('{
+                "facets": true,
+                "deprecated": true
+            }')::jsonb->'data'->'facets') f,
+            jsonb_array_elements(f->'options') o
+        where f->>'filter_key' = 'resource_kind'
+    $$,
+    $$
+        values ('Deployment', 1), ('Service', 1)
+    $$,
+    'Facets: true | Duplicates collapsed | Only package 2 resource kinds expected in resource kind facet'
+);
+select is_empty(
+    $$
+        select o
+        from
+            jsonb_array_elements(search_packages('{
+                "facets": true,
+                "deprecated": true,
+                "text": "package3"
+            }')::jsonb->'data'->'facets') f,
+            jsonb_array_elements(f->'options') o
+        where f->>'filter_key' in ('resource_kind', 'crd')
+    $$,
+    'Facets: true | Text: package3 | No resource kinds or crds expected'
+);
+select results_eq(
+    $$
+        select p->>'name'
+        from jsonb_array_elements(search_packages('{
+            "crds": ["CronTab"],
+            "include_duplicates": true
+        }')::jsonb->'data'->'packages') p
+    $$,
+    $$
+        values ('package1')
+    $$,
+    'CRDs: CronTab | Packages without crds are skipped | Package 1 expected'
+);
+
 -- Finish tests and rollback transaction
 select * from finish();
 rollback;
@@ -0,0 +1,59 @@
+-- Start transaction and plan tests
+begin;
+select plan(10);
+
+-- Test function
+select is(
+    semver_satisfies('1.18.0', '>=1.16.0-0'),
+    true,
+    '1.18.0 satisfies >=1.16.0-0'
+);
+select is(
+    semver_satisfies('1.15.3', '>= 1.16.0'),
+    false,
+    '1.15.3 does not satisfy >= 1.16.0'
+);
+select is(
+    semver_satisfies('1.18.0', '>=1.14.0 <1.18.0'),
+    false,
+    '1.18.0 does not satisfy >=1.14.0 <1.18.0'
+);
+select is(
+    semver_satisfies('1.17.5', '>=1.14.0, <1.18.0'),
+    true,
+    '1.17.5 satisfies >=1.14.0, <1.18.0'
+);
+select is(
+    semver_satisfies('1.20.0', '^1.14'),
+    true,
+    '1.20.0 satisfies ^1.14'
+);
+select is(
+    semver_satisfies('1.16.0', '~1.15'),
+    false,
+    '1.16.0 does not satisfy ~1.15'
+);
+select is(
+    semver_satisfies('1.17.2', '1.15.x || 1.17.x'),
+    true,
+    '1.17.2 satisfies 1.15.x || 1.17.x'
+);
+select is(
+    semver_satisfies('1.16.9', '1.14 - 1.16'),
+    true,
+    '1.16.9 satisfies 1.14 - 1.16'
+);
+select is(
+    semver_satisfies('1.18.0', '<=1.17'),
+    false,
+    '1.18.0 does not satisfy <=1.17'
+);
+select is(
+    semver_satisfies('1.18.0', 'invalid'),
+    false,
+    '1.18.0 does not satisfy invalid constraint'
+);
+
+-- Finish tests and rollback transaction
+select * from finish();
+rollback;
@@ -1,11 +1,13 @@
 -- Start transaction and plan tests
 begin;
-select plan(10);
+select plan(12);
 
 -- Declare some variables
 \set user1ID '00000000-0000-0000-0000-000000000001'
+\set org1ID '00000000-0000-0000-0000-000000000001'
 \set repo1ID '00000000-0000-0000-0000-000000000001'
 \set package1ID '00000000-0000-0000-0000-000000000001'
+\set package2ID '00000000-0000-0000-0000-000000000002'
 \set image1ID '00000000-0000-0000-0000-000000000001'
 \set maintainer1ID '00000000-0000-0000-0000-000000000001'
 
@@ -30,6 +32,23 @@ insert into snapshot (package_id, version) values (:'package1ID', '1.0.0');
 insert into snapshot (package_id, version) values (:'package1ID', '0.0.9');
 insert into snapshot (package_id, version) values (:'package1ID', '0.0.9-rc2');
 insert into snapshot (package_id, version) values (:'package1ID', '0.0.9-rc1');
+insert into organization (organization_id, name, display_name)
+values (:'org1ID', 'org1', 'Organization 1');
+insert into package (
+    package_id,
+    name,
+    latest_version,
+    package_kind_id,
+    organization_id
+) values (
+    :'package2ID',
+    'package2',
+    '1.0.0',
+    1,
+    :'org1ID'
+);
+insert into snapshot (package_id, version) values (:'package2ID', '1.0.0');
+insert into snapshot (package_id, version) values (:'package2ID', '0.9.0');
 insert into maintainer (maintainer_id, name, email)
 values (:'maintainer1ID', 'name1', 'email1');
 insert into package__maintainer (package_id, maintainer_id)
@@ -104,11 +123,11 @@ select unregister_package('
 }
 ');
 select is_empty(
-    $$ select * from package $$,
+    $$ select * from package where name='package1' $$,
     'Package should have been deleted'
 );
 select is_empty(
-    $$ select * from package $$,
+    $$ select * from snapshot where package_id = '00000000-0000-0000-0000-000000000001' $$,
     'All package snapshots should have been deleted'
 );
 select is_empty(
@@ -119,6 +138,31 @@ select is_empty(
     $$ select * from maintainer $$,
     'Orphan maintainer should have been deleted'
 );
+select unregister_package('
+{
+    "kind": 1,
+    "name": "package2",
+    "version": "1.0.0"
+}
+');
+select results_eq(
+    $$ select version from snapshot where package_id = '00000000-0000-0000-0000-000000000002' order by version $$,
+    $$ values ('0.9.0'), ('1.0.0') $$,
+    'Organization package version should not be deleted when the organization is not provided'
+);
+select unregister_package('
+{
+    "kind": 1,
+    "name": "package2",
+    "version": "1.0.0",
+    "organization_id": "00000000-0000-0000-0000-000000000001"
+}
+');
+select results_eq(
+    $$ select latest_version from package where name='package2' $$,
+    $$ values ('0.9.0') $$,
+    'Organization package version should have been deleted and its last version updated'
+);
 
 -- Finish tests and rollback transaction
 select * from finish();
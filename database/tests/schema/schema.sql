@@ -1,6 +1,6 @@
 -- Start transaction and plan tests
 begin;
-select plan(62);
+select plan(79);
 
 -- Check default_text_search_config is correct
 select results_eq(
@@ -15,6 +15,7 @@ select has_extension('pgcrypto');
 -- Check expected tables exist
 select tables_are(array[
     'chart_repository',
+    'chart_repository_sync_request',
     'email_verification_code',
     'image',
     'image_version',
@@ -25,6 +26,7 @@ select tables_are(array[
     'package_kind',
     'session',
     'snapshot',
+    'tracking_run',
     'user',
     'user_starred_package',
     'user__organization',
@@ -40,8 +42,26 @@ select columns_are('chart_repository', array[
     'url',
     'last_tracking_ts',
     'last_tracking_errors',
+    'last_index_digest',
+    'last_index_etag',
+    'last_index_last_modified',
+    'tracking_interval',
+    'credentials',
+    'tls_config',
+    'keyring',
     'user_id',
-    'organization_id'
+    'organization_id',
+    'package_kind_id'
+]);
+select columns_are('chart_repository_sync_request', array[
+    'chart_repository_sync_request_id',
+    'chart_repository_id',
+    'user_id',
+    'status',
+    'errors',
+    'created_at',
+    'started_at',
+    'finished_at'
 ]);
 select columns_are('email_verification_code', array[
     'email_verification_code_id',
@@ -69,7 +89,8 @@ select columns_are('organization', array[
     'description',
     'home_url',
     'logo_image_id',
-    'created_at'
+    'created_at',
+    'keyring'
 ]);
 select columns_are('package', array[
     'package_id',
@@ -85,7 +106,10 @@ select columns_are('package', array[
     'package_kind_id',
     'user_id',
     'organization_id',
-    'chart_repository_id'
+    'chart_repository_id',
+    'content_fingerprint',
+    'upstream_source',
+    'duplicate_group_id'
 ]);
 select columns_are('package__maintainer', array[
     'package_id',
@@ -93,7 +117,8 @@ select columns_are('package__maintainer', array[
 ]);
 select columns_are('package_kind', array[
     'package_kind_id',
-    'name'
+    'name',
+    'slug'
 ]);
 select columns_are('session', array[
     'session_id',
@@ -114,7 +139,35 @@ select columns_are('snapshot', array[
     'readme',
     'links',
     'data',
-    'deprecated'
+    'deprecated',
+    'signature_status',
+    'signature_key_fingerprint',
+    'default_values',
+    'values_schema',
+    'templates',
+    'dependencies',
+    'images',
+    'lint',
+    'annotations',
+    'kube_version',
+    'chart_type',
+    'sources',
+    'api_version',
+    'maintainers',
+    'license',
+    'changes',
+    'prerelease',
+    'contains_security_updates'
+]);
+select columns_are('tracking_run', array[
+    'tracking_run_id',
+    'chart_repository_id',
+    'started_at',
+    'finished_at',
+    'versions_registered',
+    'versions_unregistered',
+    'versions_skipped',
+    'errors'
 ]);
 select columns_are('user', array[
     'user_id',
@@ -148,6 +201,11 @@ select indexes_are('chart_repository', array[
     'chart_repository_name_key',
     'chart_repository_url_key'
 ]);
+select indexes_are('chart_repository_sync_request', array[
+    'chart_repository_sync_request_pkey',
+    'chart_repository_sync_request_chart_repository_id_idx',
+    'chart_repository_sync_request_status_idx'
+]);
 select indexes_are('maintainer', array[
     'maintainer_pkey',
     'maintainer_email_key'
@@ -162,18 +220,26 @@ select indexes_are('package', array[
     'package_updated_at_idx',
     'package_stars_idx',
     'package_user_id_idx',
-    'package_organization_id_idx'
+    'package_organization_id_idx',
+    'package_content_fingerprint_idx',
+    'package_upstream_source_idx',
+    'package_duplicate_group_id_idx'
 ]);
 select indexes_are('package__maintainer', array[
     'package__maintainer_pkey'
 ]);
 select indexes_are('package_kind', array[
-    'package_kind_pkey'
+    'package_kind_pkey',
+    'package_kind_slug_key'
 ]);
 select indexes_are('snapshot', array[
     'snapshot_pkey',
     'snapshot_digest_key'
 ]);
+select indexes_are('tracking_run', array[
+    'tracking_run_pkey',
+    'tracking_run_chart_repository_id_started_at_idx'
+]);
 
 -- Check expected functions exist
 select has_function('add_organization');
@@ -194,14 +260,22 @@ select has_function('update_user_profile');
 select has_function('verify_email');
 
 select has_function('generate_package_tsdoc');
+select has_function('get_org_packages_versions');
 select has_function('get_package');
+select has_function('get_package_changelog');
+select has_function('get_package_dependencies');
+select has_function('get_package_dependents');
+select has_function('get_package_kinds');
 select has_function('get_packages_starred_by_user');
 select has_function('get_package_stars');
+select has_function('get_package_values');
+select has_function('get_package_values_schema');
 select has_function('get_packages_stats');
 select has_function('get_packages_updates');
 select has_function('register_package');
 select has_function('search_packages');
 select has_function('semver_gte');
+select has_function('semver_satisfies');
 select has_function('toggle_star');
 select has_function('unregister_package');
 
@@ -209,9 +283,14 @@ select has_function('add_chart_repository');
 select has_function('delete_chart_repository');
 select has_function('get_chart_repositories');
 select has_function('get_chart_repository_by_name');
+select has_function('get_chart_repository_lint_summary');
 select has_function('get_chart_repository_packages_digest');
+select has_function('get_chart_repository_sync_request');
+select has_function('get_chart_repository_tracking_runs');
 select has_function('get_org_chart_repositories');
 select has_function('get_user_chart_repositories');
+select has_function('register_tracking_run');
+select has_function('request_chart_repository_sync');
 select has_function('update_chart_repository');
 
 select has_function('get_image');
@@ -221,9 +300,10 @@ select has_function('register_image');
 select results_eq(
     'select * from package_kind',
     $$ values
-        (0, 'Helm charts'),
-        (1, 'Falco rules'),
-        (2, 'OPA policies')
+        (0, 'Helm charts', 'chart'),
+        (1, 'Falco rules', 'falco'),
+        (2, 'OPA policies', 'opa'),
+        (3, 'Operators', 'operator')
     $$,
     'Package kinds should exist'
 );
@@ -0,0 +1,187 @@
+package chartrepo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+)
+
+var (
+	// chartHTTPClient is the http client used as a base to build the
+	// transports of the chart repositories that use custom TLS settings.
+	chartHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+	// errNoCredentialsKey indicates that the key needed to encrypt or decrypt
+	// chart repositories credentials has not been configured.
+	errNoCredentialsKey = errors.New("credentials encryption key not configured")
+
+	// tlsHTTPClients is a cache of the http clients created for the chart
+	// repositories that use custom TLS settings.
+	tlsHTTPClients sync.Map // K: tlsHTTPClientKey, V: *http.Client
+)
+
+// tlsHTTPClientKey represents the key used to cache the http clients created
+// for a given base client and TLS settings.
+type tlsHTTPClientKey struct {
+	base      *http.Client
+	tlsConfig hub.ChartRepositoryTLSConfig
+}
+
+// encryptCredentials encrypts the provided credentials using AES-GCM with a
+// 256 bits key derived from the key provided. The result is base64 encoded.
+func encryptCredentials(key string, c *hub.ChartRepositoryCredentials) (string, error) {
+	if key == "" {
+		return "", errNoCredentialsKey
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, data, nil)), nil
+}
+
+// decryptCredentials decrypts the credentials provided, which must have been
+// encrypted using encryptCredentials with the same key.
+func decryptCredentials(key, encrypted string) (*hub.ChartRepositoryCredentials, error) {
+	if key == "" {
+		return nil, errNoCredentialsKey
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted credentials")
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	data, err = aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, err
+	}
+	c := &hub.ChartRepositoryCredentials{}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// newAEAD returns an AES-GCM cipher using a key derived from the one provided.
+func newAEAD(key string) (cipher.AEAD, error) {
+	k := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(k[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// httpClient returns the http client that should be used to reach the chart
+// repository provided. Repositories without custom TLS settings use the
+// default client provided.
+func httpClient(r *hub.ChartRepository, defaultClient *http.Client) (*http.Client, error) {
+	if r.TLSConfig == nil {
+		return defaultClient, nil
+	}
+	key := tlsHTTPClientKey{base: defaultClient, tlsConfig: *r.TLSConfig}
+	if hc, ok := tlsHTTPClients.Load(key); ok {
+		return hc.(*http.Client), nil
+	}
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: r.TLSConfig.InsecureSkipVerify, // #nosec
+	}
+	if r.TLSConfig.CABundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(r.TLSConfig.CABundle)) {
+			return nil, errors.New("invalid ca bundle")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	hc := &http.Client{Timeout: defaultClient.Timeout, Transport: transport}
+	tlsHTTPClients.Store(key, hc)
+	return hc, nil
+}
+
+// setAuthHeader sets the authorization header of the provided request using
+// the credentials of the chart repository given. Credentials are only sent to
+// the host the chart repository is located at.
+func setAuthHeader(req *http.Request, r *hub.ChartRepository) {
+	c := r.Credentials
+	if c == nil {
+		return
+	}
+	repoURL, err := url.Parse(r.URL)
+	if err != nil || repoURL.Host != req.URL.Host {
+		return
+	}
+	switch {
+	case c.Username != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+}
+
+// Transport returns an http.RoundTripper that reaches the chart repository
+// provided using its TLS settings, adding its credentials to the requests sent
+// to the host it is located at. The base transport provided (or the default
+// one when nil) is used for repositories without custom TLS settings.
+func Transport(r *hub.ChartRepository, base http.RoundTripper) (http.RoundTripper, error) {
+	hc, err := httpClient(r, chartHTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	if hc.Transport != nil {
+		base = hc.Transport
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if r.Credentials == nil {
+		return base, nil
+	}
+	return &authTransport{r: r, base: base}, nil
+}
+
+// authTransport is an http.RoundTripper that sets the authorization header of
+// the requests using the credentials of a given chart repository.
+type authTransport struct {
+	r    *hub.ChartRepository
+	base http.RoundTripper
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	setAuthHeader(req, t.r)
+	return t.base.RoundTrip(req)
+}
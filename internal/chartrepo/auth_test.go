@@ -0,0 +1,107 @@
+package chartrepo
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialsEncryption(t *testing.T) {
+	creds := &hub.ChartRepositoryCredentials{Username: "user", Password: "pass"}
+
+	t.Run("credentials encrypted and decrypted successfully", func(t *testing.T) {
+		encrypted, err := encryptCredentials("key", creds)
+		require.NoError(t, err)
+		assert.NotContains(t, encrypted, "pass")
+		decrypted, err := decryptCredentials("key", encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, creds, decrypted)
+	})
+
+	t.Run("key not provided", func(t *testing.T) {
+		_, err := encryptCredentials("", creds)
+		assert.Equal(t, errNoCredentialsKey, err)
+		_, err = decryptCredentials("", "encrypted")
+		assert.Equal(t, errNoCredentialsKey, err)
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		encrypted, _ := encryptCredentials("key", creds)
+		_, err := decryptCredentials("other", encrypted)
+		assert.Error(t, err)
+	})
+}
+
+func TestSetAuthHeader(t *testing.T) {
+	r := &hub.ChartRepository{
+		URL:         "https://repo1.com/charts",
+		Credentials: &hub.ChartRepositoryCredentials{Token: "token"},
+	}
+
+	req, _ := http.NewRequest("GET", "https://repo1.com/charts/pkg1-1.0.0.tgz", nil)
+	setAuthHeader(req, r)
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+
+	req, _ = http.NewRequest("GET", "https://other.com/pkg1-1.0.0.tgz", nil)
+	setAuthHeader(req, r)
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
+
+func TestLoadIndexPrivateRepository(t *testing.T) {
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(testIndex))
+	}))
+	defer s.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: s.Certificate().Raw})
+	l := &IndexLoader{}
+
+	t.Run("unknown certificate authority", func(t *testing.T) {
+		_, err := l.LoadIndex(&hub.ChartRepository{URL: s.URL})
+		assert.Error(t, err)
+	})
+
+	t.Run("credentials not provided", func(t *testing.T) {
+		_, err := l.LoadIndex(&hub.ChartRepository{
+			URL:       s.URL,
+			TLSConfig: &hub.ChartRepositoryTLSConfig{CABundle: string(caBundle)},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid ca bundle", func(t *testing.T) {
+		_, err := l.LoadIndex(&hub.ChartRepository{
+			URL:       s.URL,
+			TLSConfig: &hub.ChartRepositoryTLSConfig{CABundle: "invalid"},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("index file loaded using credentials and ca bundle", func(t *testing.T) {
+		indexFile, err := l.LoadIndex(&hub.ChartRepository{
+			URL:         s.URL,
+			Credentials: &hub.ChartRepositoryCredentials{Username: "user", Password: "pass"},
+			TLSConfig:   &hub.ChartRepositoryTLSConfig{CABundle: string(caBundle)},
+		})
+		require.NoError(t, err)
+		assert.Len(t, indexFile.Entries["pkg1"], 1)
+	})
+
+	t.Run("index file loaded skipping tls verification", func(t *testing.T) {
+		indexFile, err := l.LoadIndex(&hub.ChartRepository{
+			URL:         s.URL,
+			Credentials: &hub.ChartRepositoryCredentials{Username: "user", Password: "pass"},
+			TLSConfig:   &hub.ChartRepositoryTLSConfig{InsecureSkipVerify: true},
+		})
+		require.NoError(t, err)
+		assert.Len(t, indexFile.Entries["pkg1"], 1)
+	})
+}
@@ -0,0 +1,37 @@
+package chartrepo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DigestMismatchError represents an error caused by the content downloaded
+// for a chart not matching the digest it was expected to have, which could
+// happen if it was altered after the digest was published.
+type DigestMismatchError struct {
+	Expected string
+	Actual   string
+}
+
+// Error implements the error interface.
+func (e *DigestMismatchError) Error() string {
+	return fmt.Sprintf("digest mismatch: expected %s, got %s", e.Expected, e.Actual)
+}
+
+// VerifyDigest checks that the sha256 digest of the data provided matches the
+// expected one, which can optionally be prefixed with the algorithm (i.e.
+// sha256:...). Digests using other algorithms or not available cannot be
+// verified, so they are ignored.
+func VerifyDigest(data []byte, expected string) error {
+	d := strings.ToLower(strings.TrimPrefix(expected, "sha256:"))
+	if _, err := hex.DecodeString(d); err != nil || len(d) != sha256.Size*2 {
+		return nil
+	}
+	actual := fmt.Sprintf("%x", sha256.Sum256(data))
+	if actual != d {
+		return &DigestMismatchError{Expected: d, Actual: actual}
+	}
+	return nil
+}
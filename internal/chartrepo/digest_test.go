@@ -0,0 +1,44 @@
+package chartrepo
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyDigest(t *testing.T) {
+	data := []byte("data")
+	digest := fmt.Sprintf("%x", sha256.Sum256(data))
+	otherDigest := fmt.Sprintf("%x", sha256.Sum256([]byte("other")))
+
+	testCases := []struct {
+		desc     string
+		expected string
+		mismatch bool
+	}{
+		{"digest not available", "", false},
+		{"digest using other algorithm", "sha512:abcd", false},
+		{"digest matches", digest, false},
+		{"prefixed digest matches", "sha256:" + digest, false},
+		{"uppercase digest matches", fmt.Sprintf("%X", sha256.Sum256(data)), false},
+		{"digest does not match", otherDigest, true},
+		{"prefixed digest does not match", "sha256:" + otherDigest, true},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.desc, func(t *testing.T) {
+			err := VerifyDigest(data, tc.expected)
+			if tc.mismatch {
+				var e *DigestMismatchError
+				assert.True(t, errors.As(err, &e))
+				assert.Equal(t, otherDigest, e.Expected)
+				assert.Equal(t, digest, e.Actual)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
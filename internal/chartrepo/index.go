@@ -1,34 +1,126 @@
 package chartrepo
 
 import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
 	"github.com/artifacthub/hub/internal/hub"
-	"helm.sh/helm/v3/pkg/cli"
-	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
 )
 
+// indexHTTPClient is the http client used to download chart repositories
+// index files.
+var indexHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
 // IndexLoader provides a mechanism to load a chart repository index file,
-// verifying it is valid.
-type IndexLoader struct{}
+// verifying it is valid. Chart repositories located in OCI registries are
+// supported as well, in which case the index file is built from the tags
+// available in the registry.
+type IndexLoader struct {
+	oci OCIClient
+}
 
 // LoadIndex downloads and parses the index file of the provided repository.
 func (l *IndexLoader) LoadIndex(r *hub.ChartRepository) (*repo.IndexFile, error) {
-	repoConfig := &repo.Entry{
-		Name: r.Name,
-		URL:  r.URL,
+	indexFile, _, err := l.loadIndex(r, nil)
+	return indexFile, err
+}
+
+// LoadIndexIfChanged downloads and parses the index file of the provided
+// repository only if it has changed since the last time it was processed. To
+// detect changes, the ETag and Last-Modified values returned by the server are
+// sent in a conditional request, and the digest of the index file downloaded
+// is compared with the one previously processed. When the index file has not
+// changed, a nil index file is returned. The information about the index file
+// loaded is returned as well, so that it can be stored for the next run.
+func (l *IndexLoader) LoadIndexIfChanged(r *hub.ChartRepository) (
+	*repo.IndexFile,
+	*hub.ChartRepositoryIndexInfo,
+	error,
+) {
+	lastInfo := r.LastIndexInfo
+	if lastInfo == nil {
+		lastInfo = &hub.ChartRepositoryIndexInfo{}
+	}
+	return l.loadIndex(r, lastInfo)
+}
+
+// loadIndex is a helper that downloads and parses the index file of the
+// provided repository. When the last index information is provided, the
+// request will be a conditional one and the index file will only be parsed if
+// it has changed.
+func (l *IndexLoader) loadIndex(r *hub.ChartRepository, lastInfo *hub.ChartRepositoryIndexInfo) (
+	*repo.IndexFile,
+	*hub.ChartRepositoryIndexInfo,
+	error,
+) {
+	if IsOCIReference(r.URL) {
+		return loadOCIIndex(&l.oci, r, lastInfo)
+	}
+
+	// Prepare request
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, nil, err
 	}
-	getters := getter.All(&cli.EnvSettings{})
-	chartRepository, err := repo.NewChartRepository(repoConfig, getters)
+	u.Path = path.Join(u.Path, "index.yaml")
+	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	path, err := chartRepository.DownloadIndexFile()
+	setAuthHeader(req, r)
+	if lastInfo != nil {
+		if lastInfo.ETag != "" {
+			req.Header.Set("If-None-Match", lastInfo.ETag)
+		}
+		if lastInfo.LastModified != "" {
+			req.Header.Set("If-Modified-Since", lastInfo.LastModified)
+		}
+	}
+
+	// Download index file
+	hc, err := httpClient(r, indexHTTPClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	resp, err := hc.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	if lastInfo != nil && resp.StatusCode == http.StatusNotModified {
+		return nil, lastInfo, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
 	}
-	indexFile, err := repo.LoadIndexFile(path)
+	data, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	info := &hub.ChartRepositoryIndexInfo{
+		Digest:       fmt.Sprintf("%x", sha256.Sum256(data)),
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+	if lastInfo != nil && info.Digest == lastInfo.Digest {
+		return nil, info, nil
+	}
+
+	// Parse index file
+	indexFile := &repo.IndexFile{}
+	if err := yaml.Unmarshal(data, indexFile); err != nil {
+		return nil, nil, err
+	}
+	if indexFile.APIVersion == "" {
+		return nil, nil, repo.ErrNoAPIVersion
 	}
-	return indexFile, nil
+	indexFile.SortEntries()
+	return indexFile, info, nil
 }
@@ -0,0 +1,147 @@
+package chartrepo
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIndex = `apiVersion: v1
+entries:
+  pkg1:
+  - name: pkg1
+    version: 1.0.0
+    digest: pkg1-1.0.0
+    urls:
+    - pkg1-1.0.0.tgz
+`
+
+func TestLoadIndex(t *testing.T) {
+	t.Run("index file loaded successfully", func(t *testing.T) {
+		s := newIndexServer(t, testIndex, "etag1", "")
+		defer s.Close()
+		l := &IndexLoader{}
+
+		indexFile, err := l.LoadIndex(&hub.ChartRepository{URL: s.URL})
+		require.NoError(t, err)
+		assert.Len(t, indexFile.Entries["pkg1"], 1)
+	})
+
+	t.Run("unexpected status code", func(t *testing.T) {
+		s := httptest.NewServer(http.NotFoundHandler())
+		defer s.Close()
+		l := &IndexLoader{}
+
+		_, err := l.LoadIndex(&hub.ChartRepository{URL: s.URL})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid index file", func(t *testing.T) {
+		s := newIndexServer(t, "entries: {}", "", "")
+		defer s.Close()
+		l := &IndexLoader{}
+
+		_, err := l.LoadIndex(&hub.ChartRepository{URL: s.URL})
+		assert.Error(t, err)
+	})
+}
+
+func TestLoadIndexIfChanged(t *testing.T) {
+	digest := fmt.Sprintf("%x", sha256.Sum256([]byte(testIndex)))
+	lastModified := "Wed, 21 Oct 2015 07:28:00 GMT"
+
+	t.Run("index file never processed before", func(t *testing.T) {
+		s := newIndexServer(t, testIndex, "etag1", lastModified)
+		defer s.Close()
+		l := &IndexLoader{}
+
+		indexFile, info, err := l.LoadIndexIfChanged(&hub.ChartRepository{URL: s.URL})
+		require.NoError(t, err)
+		assert.Len(t, indexFile.Entries["pkg1"], 1)
+		assert.Equal(t, &hub.ChartRepositoryIndexInfo{
+			Digest:       digest,
+			ETag:         "etag1",
+			LastModified: lastModified,
+		}, info)
+	})
+
+	t.Run("server reports index file not modified", func(t *testing.T) {
+		s := newIndexServer(t, testIndex, "etag1", lastModified)
+		defer s.Close()
+		l := &IndexLoader{}
+		lastInfo := &hub.ChartRepositoryIndexInfo{
+			Digest:       digest,
+			ETag:         "etag1",
+			LastModified: lastModified,
+		}
+
+		indexFile, info, err := l.LoadIndexIfChanged(&hub.ChartRepository{
+			URL:           s.URL,
+			LastIndexInfo: lastInfo,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, indexFile)
+		assert.Equal(t, lastInfo, info)
+	})
+
+	t.Run("index file digest has not changed", func(t *testing.T) {
+		s := newIndexServer(t, testIndex, "", "")
+		defer s.Close()
+		l := &IndexLoader{}
+
+		indexFile, info, err := l.LoadIndexIfChanged(&hub.ChartRepository{
+			URL:           s.URL,
+			LastIndexInfo: &hub.ChartRepositoryIndexInfo{Digest: digest},
+		})
+		require.NoError(t, err)
+		assert.Nil(t, indexFile)
+		assert.Equal(t, digest, info.Digest)
+	})
+
+	t.Run("index file has changed", func(t *testing.T) {
+		s := newIndexServer(t, testIndex, "etag2", "")
+		defer s.Close()
+		l := &IndexLoader{}
+
+		indexFile, info, err := l.LoadIndexIfChanged(&hub.ChartRepository{
+			URL: s.URL,
+			LastIndexInfo: &hub.ChartRepositoryIndexInfo{
+				Digest: "old-digest",
+				ETag:   "etag1",
+			},
+		})
+		require.NoError(t, err)
+		assert.Len(t, indexFile.Entries["pkg1"], 1)
+		assert.Equal(t, digest, info.Digest)
+		assert.Equal(t, "etag2", info.ETag)
+	})
+}
+
+// newIndexServer returns a test server that serves the index file provided,
+// supporting conditional requests based on the etag and last modified values.
+func newIndexServer(t *testing.T, index, etag, lastModified string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/index.yaml", r.URL.Path)
+		if etag != "" && r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if lastModified != "" && r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		if etag != "" {
+			w.Header().Set("ETag", etag)
+		}
+		if lastModified != "" {
+			w.Header().Set("Last-Modified", lastModified)
+		}
+		_, _ = w.Write([]byte(index))
+	}))
+}
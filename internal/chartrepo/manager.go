@@ -5,24 +5,40 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"regexp"
 
 	"github.com/artifacthub/hub/internal/hub"
+	"github.com/jackc/pgx/v4"
 	"github.com/satori/uuid"
 )
 
+const (
+	// maxTrackingRunsLimit represents the maximum number of tracking runs
+	// that can be requested at once.
+	maxTrackingRunsLimit = 100
+
+	// minTrackingInterval represents the minimum interval, in seconds, that
+	// can be set to track a chart repository.
+	minTrackingInterval = 60
+)
+
 var (
 	// chartRepositoryNameRE is a regexp used to validate a repository name.
 	chartRepositoryNameRE = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
 
 	// ErrInvalidInput indicates that the input provided is not valid.
 	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrNotFound indicates that the sync request requested was not found.
+	ErrNotFound = errors.New("sync request not found")
 )
 
 // Manager provides an API to manage chart repositories.
 type Manager struct {
-	db hub.DB
-	il hub.ChartRepositoryIndexLoader
+	db             hub.DB
+	il             hub.ChartRepositoryIndexLoader
+	credentialsKey string
 }
 
 // NewManager creates a new Manager instance.
@@ -45,6 +61,21 @@ func WithIndexLoader(l hub.ChartRepositoryIndexLoader) func(m *Manager) {
 	}
 }
 
+// WithCredentialsKey allows providing the key used to encrypt the chart
+// repositories credentials before storing them in the database.
+func WithCredentialsKey(key string) func(m *Manager) {
+	return func(m *Manager) {
+		m.credentialsKey = key
+	}
+}
+
+// chartRepositoryDB represents a chart repository as it is stored in the
+// database, where its credentials are kept encrypted.
+type chartRepositoryDB struct {
+	*hub.ChartRepository
+	Credentials *string `json:"credentials,omitempty"`
+}
+
 // Add adds the provided chart repository to the database.
 func (m *Manager) Add(ctx context.Context, orgName string, r *hub.ChartRepository) error {
 	userID := ctx.Value(hub.UserIDKey).(string)
@@ -59,14 +90,23 @@ func (m *Manager) Add(ctx context.Context, orgName string, r *hub.ChartRepositor
 	if !chartRepositoryNameRE.MatchString(r.Name) {
 		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid name")
 	}
-	if _, err := m.il.LoadIndex(r); err != nil {
-		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid url")
+	if r.TrackingInterval < 0 || (r.TrackingInterval > 0 && r.TrackingInterval < minTrackingInterval) {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid tracking interval")
+	}
+	if err := m.checkCredentialsKey(r); err != nil {
+		return err
+	}
+	if err := m.validateURL(r); err != nil {
+		return err
 	}
 
 	// Add chart repository to the database
+	rJSON, err := m.marshalForDB(r)
+	if err != nil {
+		return err
+	}
 	query := "select add_chart_repository($1::uuid, $2::text, $3::jsonb)"
-	rJSON, _ := json.Marshal(r)
-	_, err := m.db.Exec(ctx, query, userID, orgName, rJSON)
+	_, err = m.db.Exec(ctx, query, userID, orgName, rJSON)
 	return err
 }
 
@@ -108,6 +148,74 @@ func (m *Manager) CheckAvailability(ctx context.Context, resourceKind, value str
 	return available, err
 }
 
+// ClaimSyncRequests marks the pending sync requests of the chart repositories
+// provided as running, returning them so that they can be processed.
+func (m *Manager) ClaimSyncRequests(
+	ctx context.Context,
+	chartRepositoriesIDs []string,
+) ([]*hub.ChartRepositorySyncRequest, error) {
+	// Validate input
+	for _, chartRepositoryID := range chartRepositoriesIDs {
+		if _, err := uuid.FromString(chartRepositoryID); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid chart repository id")
+		}
+	}
+	if len(chartRepositoriesIDs) == 0 {
+		return nil, nil
+	}
+
+	// Claim pending sync requests in database
+	query := `
+	with claimed as (
+		update chart_repository_sync_request set
+			status = 'running',
+			started_at = current_timestamp
+		where chart_repository_sync_request_id in (
+			select chart_repository_sync_request_id
+			from chart_repository_sync_request
+			where status = 'pending'
+			and chart_repository_id = any($1::uuid[])
+			for update skip locked
+		)
+		returning chart_repository_sync_request_id, chart_repository_id
+	)
+	select coalesce(json_agg(json_build_object(
+		'sync_request_id', chart_repository_sync_request_id,
+		'chart_repository_id', chart_repository_id
+	)), '[]')
+	from claimed`
+	var requests []*hub.ChartRepositorySyncRequest
+	err := m.dbQueryUnmarshal(ctx, &requests, query, chartRepositoriesIDs)
+	return requests, err
+}
+
+// CompleteSyncRequests marks the provided sync requests as finished. Their
+// status is set from the results of the last tracking of the chart repository
+// they belong to, so they must be completed once those have been stored.
+func (m *Manager) CompleteSyncRequests(ctx context.Context, syncRequestsIDs []string) error {
+	// Validate input
+	for _, syncRequestID := range syncRequestsIDs {
+		if _, err := uuid.FromString(syncRequestID); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid sync request id")
+		}
+	}
+	if len(syncRequestsIDs) == 0 {
+		return nil
+	}
+
+	// Complete sync requests in database
+	query := `
+	update chart_repository_sync_request sr set
+		status = case when cr.last_tracking_errors is null then 'completed' else 'failed' end,
+		errors = cr.last_tracking_errors,
+		finished_at = current_timestamp
+	from chart_repository cr
+	where sr.chart_repository_id = cr.chart_repository_id
+	and sr.chart_repository_sync_request_id = any($1::uuid[])`
+	_, err := m.db.Exec(ctx, query, syncRequestsIDs)
+	return err
+}
+
 // Delete deletes the provided chart repository from the database.
 func (m *Manager) Delete(ctx context.Context, name string) error {
 	userID := ctx.Value(hub.UserIDKey).(string)
@@ -125,9 +233,18 @@ func (m *Manager) Delete(ctx context.Context, name string) error {
 
 // GetAll returns all available chart repositories.
 func (m *Manager) GetAll(ctx context.Context) ([]*hub.ChartRepository, error) {
-	var r []*hub.ChartRepository
-	err := m.dbQueryUnmarshal(ctx, &r, "select get_chart_repositories()")
-	return r, err
+	var rDB []*chartRepositoryDB
+	if err := m.dbQueryUnmarshal(ctx, &rDB, "select get_chart_repositories()"); err != nil {
+		return nil, err
+	}
+	r := make([]*hub.ChartRepository, 0, len(rDB))
+	for _, entry := range rDB {
+		if err := m.decryptCredentials(entry); err != nil {
+			return nil, err
+		}
+		r = append(r, entry.ChartRepository)
+	}
+	return r, nil
 }
 
 // GetByName returns the chart repository identified by the name provided.
@@ -138,9 +255,15 @@ func (m *Manager) GetByName(ctx context.Context, name string) (*hub.ChartReposit
 	}
 
 	// Get chart repository from database
-	var r *hub.ChartRepository
-	err := m.dbQueryUnmarshal(ctx, &r, "select get_chart_repository_by_name($1::text)", name)
-	return r, err
+	var rDB *chartRepositoryDB
+	err := m.dbQueryUnmarshal(ctx, &rDB, "select get_chart_repository_by_name($1::text)", name)
+	if err != nil || rDB == nil {
+		return nil, err
+	}
+	if err := m.decryptCredentials(rDB); err != nil {
+		return nil, err
+	}
+	return rDB.ChartRepository, nil
 }
 
 // GetPackagesDigest returns the digests for all packages in the repository
@@ -184,6 +307,132 @@ func (m *Manager) GetOwnedByUserJSON(ctx context.Context) ([]byte, error) {
 	return m.dbQueryJSON(ctx, query, userID)
 }
 
+// GetSyncRequestJSON returns the status of the provided sync request of the
+// chart repository as a json object. The user doing the request must be the
+// owner of the chart repository or belong to the organization owning it.
+func (m *Manager) GetSyncRequestJSON(ctx context.Context, name, syncRequestID string) ([]byte, error) {
+	userID := ctx.Value(hub.UserIDKey).(string)
+
+	// Validate input
+	if name == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "name not provided")
+	}
+	if _, err := uuid.FromString(syncRequestID); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid sync request id")
+	}
+
+	// Get sync request from database
+	query := "select get_chart_repository_sync_request($1::uuid, $2::text, $3::uuid)"
+	dataJSON, err := m.dbQueryJSON(ctx, query, userID, name, syncRequestID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return dataJSON, nil
+}
+
+// GetLintSummaryJSON returns a summary of the lint results of the latest
+// version of the packages in the provided chart repository as a json object.
+func (m *Manager) GetLintSummaryJSON(ctx context.Context, name string) ([]byte, error) {
+	// Validate input
+	if name == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "name not provided")
+	}
+
+	// Get lint summary from database
+	query := "select get_chart_repository_lint_summary($1::text)"
+	dataJSON, err := m.dbQueryJSON(ctx, query, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return dataJSON, nil
+}
+
+// GetTrackingRunsJSON returns the most recent tracking runs of the provided
+// chart repository as a json array. The user doing the request must be the
+// owner of the chart repository or belong to the organization owning it.
+func (m *Manager) GetTrackingRunsJSON(ctx context.Context, name string, limit int) ([]byte, error) {
+	userID := ctx.Value(hub.UserIDKey).(string)
+
+	// Validate input
+	if name == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "name not provided")
+	}
+	if limit <= 0 || limit > maxTrackingRunsLimit {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid limit")
+	}
+
+	// Get tracking runs from database
+	query := "select get_chart_repository_tracking_runs($1::uuid, $2::text, $3::int)"
+	return m.dbQueryJSON(ctx, query, userID, name, limit)
+}
+
+// RegisterTrackingRun registers the provided tracking run in the database.
+func (m *Manager) RegisterTrackingRun(ctx context.Context, run *hub.TrackingRun) error {
+	// Validate input
+	if run == nil {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "tracking run not provided")
+	}
+	if _, err := uuid.FromString(run.ChartRepositoryID); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid chart repository id")
+	}
+
+	// Register tracking run in database
+	runJSON, _ := json.Marshal(run)
+	_, err := m.db.Exec(ctx, "select register_tracking_run($1::jsonb)", runJSON)
+	return err
+}
+
+// RequestSync registers a request to track the provided chart repository as
+// soon as possible, returning the id of the sync request. The user doing the
+// request must be the owner of the chart repository or belong to the
+// organization owning it.
+func (m *Manager) RequestSync(ctx context.Context, name string) (string, error) {
+	userID := ctx.Value(hub.UserIDKey).(string)
+
+	// Validate input
+	if name == "" {
+		return "", fmt.Errorf("%w: %s", ErrInvalidInput, "name not provided")
+	}
+
+	// Register sync request in database
+	var syncRequestID string
+	query := "select request_chart_repository_sync($1::uuid, $2::text)"
+	err := m.db.QueryRow(ctx, query, userID, name).Scan(&syncRequestID)
+	return syncRequestID, err
+}
+
+// SetLastIndexInfo updates the information about the last index file
+// processed of the provided repository in the database.
+func (m *Manager) SetLastIndexInfo(
+	ctx context.Context,
+	chartRepositoryID string,
+	info *hub.ChartRepositoryIndexInfo,
+) error {
+	// Validate input
+	if _, err := uuid.FromString(chartRepositoryID); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid chart repository id")
+	}
+	if info == nil {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "index info not provided")
+	}
+
+	// Update last index info in database
+	query := `
+	update chart_repository set
+		last_index_digest = nullif($2, ''),
+		last_index_etag = nullif($3, ''),
+		last_index_last_modified = nullif($4, '')
+	where chart_repository_id = $1`
+	_, err := m.db.Exec(ctx, query, chartRepositoryID, info.Digest, info.ETag, info.LastModified)
+	return err
+}
+
 // SetLastTrackingResults updates the timestamp and errors of the last tracking
 // of the provided repository in the database.
 func (m *Manager) SetLastTrackingResults(ctx context.Context, chartRepositoryID, errs string) error {
@@ -213,17 +462,120 @@ func (m *Manager) Update(ctx context.Context, r *hub.ChartRepository) error {
 	if r.URL == "" {
 		return fmt.Errorf("%w: %s", ErrInvalidInput, "url not provided")
 	}
-	if _, err := m.il.LoadIndex(r); err != nil {
-		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid url")
+	if r.TrackingInterval < 0 || (r.TrackingInterval > 0 && r.TrackingInterval < minTrackingInterval) {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid tracking interval")
+	}
+	if err := m.checkCredentialsKey(r); err != nil {
+		return err
+	}
+	stored, err := m.GetByName(ctx, r.Name)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	rToValidate := r
+	if stored != nil {
+		// The kind of a chart repository cannot be changed. Credentials and
+		// TLS settings not provided are kept as long as the url does not
+		// change, so the stored ones are used to validate it
+		tmp := *r
+		tmp.Kind = stored.Kind
+		if stored.URL == r.URL {
+			if tmp.Credentials == nil {
+				tmp.Credentials = stored.Credentials
+			}
+			if tmp.TLSConfig == nil {
+				tmp.TLSConfig = stored.TLSConfig
+			}
+		}
+		rToValidate = &tmp
+	}
+	// Repositories of kinds other than Helm charts may point to a local
+	// directory when registered directly in the database, so their url is
+	// only validated when it changes
+	if rToValidate.Kind == hub.Chart || stored == nil || stored.URL != r.URL {
+		if err := m.validateURL(rToValidate); err != nil {
+			return err
+		}
 	}
 
 	// Update chart repository in database
+	rJSON, err := m.marshalForDB(r)
+	if err != nil {
+		return err
+	}
 	query := "select update_chart_repository($1::uuid, $2::jsonb)"
-	rJSON, _ := json.Marshal(r)
-	_, err := m.db.Exec(ctx, query, userID, rJSON)
+	_, err = m.db.Exec(ctx, query, userID, rJSON)
 	return err
 }
 
+// checkCredentialsKey checks that the key needed to encrypt the credentials of
+// the chart repository provided has been configured, so that credentials are
+// never stored when it is missing.
+func (m *Manager) checkCredentialsKey(r *hub.ChartRepository) error {
+	if r.Credentials == nil || *r.Credentials == (hub.ChartRepositoryCredentials{}) {
+		return nil
+	}
+	if m.credentialsKey == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, errNoCredentialsKey.Error())
+	}
+	return nil
+}
+
+// validateURL checks that the url of the chart repository provided is valid
+// for its kind. The index of Helm charts repositories must be available, and
+// repositories of other kinds must point to a git repository or a metadata
+// file served over http(s).
+func (m *Manager) validateURL(r *hub.ChartRepository) error {
+	switch r.Kind {
+	case hub.Chart:
+		if _, err := m.il.LoadIndex(r); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid url")
+		}
+	default:
+		if r.Kind < 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid kind")
+		}
+		u, err := url.Parse(r.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid url")
+		}
+	}
+	return nil
+}
+
+// marshalForDB returns the json representation of the chart repository
+// provided as it must be stored in the database, encrypting its credentials.
+// Empty credentials are sent as an empty string, so that they are removed.
+func (m *Manager) marshalForDB(r *hub.ChartRepository) ([]byte, error) {
+	rDB := &chartRepositoryDB{ChartRepository: r}
+	if r.Credentials != nil {
+		var encrypted string
+		if *r.Credentials != (hub.ChartRepositoryCredentials{}) {
+			var err error
+			encrypted, err = encryptCredentials(m.credentialsKey, r.Credentials)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rDB.Credentials = &encrypted
+	}
+	return json.Marshal(rDB)
+}
+
+// decryptCredentials decrypts the credentials of the chart repository
+// provided, as they were returned from the database.
+func (m *Manager) decryptCredentials(rDB *chartRepositoryDB) error {
+	if rDB.Credentials == nil || *rDB.Credentials == "" {
+		return nil
+	}
+	c, err := decryptCredentials(m.credentialsKey, *rDB.Credentials)
+	if err != nil {
+		return fmt.Errorf("error decrypting chart repository %s credentials: %w", rDB.Name, err)
+	}
+	rDB.ChartRepository.Credentials = c
+	return nil
+}
+
 // dbQueryJSON is a helper that executes the query provided and returns a bytes
 // slice containing the json data returned from the database.
 func (m *Manager) dbQueryJSON(ctx context.Context, query string, args ...interface{}) ([]byte, error) {
@@ -2,12 +2,15 @@ package chartrepo
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/tests"
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -63,6 +66,16 @@ func TestAdd(t *testing.T) {
 				},
 				nil,
 			},
+			{
+				"invalid tracking interval",
+				"org1",
+				&hub.ChartRepository{
+					Name:             "repo1",
+					URL:              "https://repo1.com",
+					TrackingInterval: 30,
+				},
+				nil,
+			},
 			{
 				"invalid name",
 				"org1",
@@ -90,6 +103,26 @@ func TestAdd(t *testing.T) {
 				},
 				errors.New("invalid url"),
 			},
+			{
+				"invalid url",
+				"org1",
+				&hub.ChartRepository{
+					Kind: hub.Falco,
+					Name: "repo1",
+					URL:  "/falco/rules",
+				},
+				nil,
+			},
+			{
+				"invalid kind",
+				"org1",
+				&hub.ChartRepository{
+					Kind: hub.PackageKind(-1),
+					Name: "repo1",
+					URL:  "https://repo1.com",
+				},
+				nil,
+			},
 		}
 		for _, tc := range testCases {
 			tc := tc
@@ -133,6 +166,115 @@ func TestAdd(t *testing.T) {
 		db.AssertExpectations(t)
 		l.AssertExpectations(t)
 	})
+
+	t.Run("add chart repository with tracking interval succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "userID", "orgName", mock.MatchedBy(func(rJSON []byte) bool {
+			var rDB map[string]interface{}
+			_ = json.Unmarshal(rJSON, &rDB)
+			return rDB["tracking_interval"] == float64(3600)
+		})).Return(nil)
+		l := &IndexLoaderMock{}
+		l.On("LoadIndex", mock.Anything).Return(nil, nil)
+		m := NewManager(db, WithIndexLoader(l))
+
+		err := m.Add(ctx, "orgName", &hub.ChartRepository{
+			Name:             "repo1",
+			URL:              "https://repo1.com",
+			TrackingInterval: 3600,
+		})
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+		l.AssertExpectations(t)
+	})
+
+	t.Run("add falco rules repository succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, "userID", "orgName", mock.Anything).Return(nil)
+		l := &IndexLoaderMock{}
+		m := NewManager(db, WithIndexLoader(l))
+
+		err := m.Add(ctx, "orgName", &hub.ChartRepository{
+			Kind: hub.Falco,
+			Name: "repo1",
+			URL:  "https://github.com/org/repo.git#rules",
+		})
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+		l.AssertExpectations(t)
+	})
+
+	t.Run("add chart repository with credentials", func(t *testing.T) {
+		r := &hub.ChartRepository{
+			Name: "repo1",
+			URL:  "https://repo1.com",
+			Credentials: &hub.ChartRepositoryCredentials{
+				Username: "user",
+				Password: "pass",
+			},
+		}
+
+		t.Run("credentials encryption key not configured", func(t *testing.T) {
+			l := &IndexLoaderMock{}
+			m := NewManager(nil, WithIndexLoader(l))
+
+			err := m.Add(ctx, "orgName", r)
+			assert.True(t, errors.Is(err, ErrInvalidInput))
+			l.AssertExpectations(t)
+		})
+
+		t.Run("credentials stored encrypted", func(t *testing.T) {
+			db := &tests.DBMock{}
+			db.On("Exec", dbQuery, "userID", "orgName", mock.MatchedBy(func(rJSON []byte) bool {
+				var rDB map[string]interface{}
+				_ = json.Unmarshal(rJSON, &rDB)
+				encrypted, ok := rDB["credentials"].(string)
+				if !ok {
+					return false
+				}
+				creds, err := decryptCredentials("key", encrypted)
+				return err == nil && assert.ObjectsAreEqual(r.Credentials, creds)
+			})).Return(nil)
+			l := &IndexLoaderMock{}
+			l.On("LoadIndex", mock.Anything).Return(nil, nil)
+			m := NewManager(db, WithIndexLoader(l), WithCredentialsKey("key"))
+
+			err := m.Add(ctx, "orgName", r)
+			assert.NoError(t, err)
+			db.AssertExpectations(t)
+			l.AssertExpectations(t)
+		})
+	})
+
+	t.Run("add oci chart repository", func(t *testing.T) {
+		registry := tests.NewOCIRegistry()
+		defer registry.Close()
+		registry.PushChart("charts/pkg1", "1.0.0", []byte("pkg1-1.0.0"))
+
+		t.Run("repository not found in registry", func(t *testing.T) {
+			m := NewManager(nil)
+
+			err := m.Add(ctx, "orgName", &hub.ChartRepository{
+				Name: "repo1",
+				URL:  "oci://" + registry.Host() + "/charts/pkg2",
+			})
+			assert.True(t, errors.Is(err, ErrInvalidInput))
+			assert.Contains(t, err.Error(), "invalid url")
+		})
+
+		t.Run("add chart repository succeeded", func(t *testing.T) {
+			db := &tests.DBMock{}
+			db.On("Exec", dbQuery, "userID", "orgName", mock.Anything).Return(nil)
+			m := NewManager(db)
+
+			err := m.Add(ctx, "orgName", &hub.ChartRepository{
+				Name: "repo1",
+				URL:  "oci://" + registry.Host() + "/charts/pkg1",
+			})
+			assert.NoError(t, err)
+			db.AssertExpectations(t)
+		})
+	})
 }
 
 func TestCheckAvailability(t *testing.T) {
@@ -210,6 +352,118 @@ func TestCheckAvailability(t *testing.T) {
 	})
 }
 
+func TestClaimSyncRequests(t *testing.T) {
+	dbQuery := `
+	with claimed as (
+		update chart_repository_sync_request set
+			status = 'running',
+			started_at = current_timestamp
+		where chart_repository_sync_request_id in (
+			select chart_repository_sync_request_id
+			from chart_repository_sync_request
+			where status = 'pending'
+			and chart_repository_id = any($1::uuid[])
+			for update skip locked
+		)
+		returning chart_repository_sync_request_id, chart_repository_id
+	)
+	select coalesce(json_agg(json_build_object(
+		'sync_request_id', chart_repository_sync_request_id,
+		'chart_repository_id', chart_repository_id
+	)), '[]')
+	from claimed`
+	reposIDs := []string{"00000000-0000-0000-0000-000000000001"}
+
+	t.Run("invalid input", func(t *testing.T) {
+		m := NewManager(nil)
+		_, err := m.ClaimSyncRequests(context.Background(), []string{"invalid"})
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+
+	t.Run("no chart repositories provided", func(t *testing.T) {
+		m := NewManager(nil)
+		requests, err := m.ClaimSyncRequests(context.Background(), nil)
+		assert.NoError(t, err)
+		assert.Nil(t, requests)
+	})
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, reposIDs).Return([]byte(`
+		[{
+			"sync_request_id": "00000000-0000-0000-0000-000000000001",
+			"chart_repository_id": "00000000-0000-0000-0000-000000000001"
+		}]
+		`), nil)
+		m := NewManager(db)
+
+		requests, err := m.ClaimSyncRequests(context.Background(), reposIDs)
+		require.NoError(t, err)
+		assert.Equal(t, []*hub.ChartRepositorySyncRequest{
+			{
+				SyncRequestID:     "00000000-0000-0000-0000-000000000001",
+				ChartRepositoryID: "00000000-0000-0000-0000-000000000001",
+			},
+		}, requests)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, reposIDs).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		requests, err := m.ClaimSyncRequests(context.Background(), reposIDs)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, requests)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestCompleteSyncRequests(t *testing.T) {
+	dbQuery := `
+	update chart_repository_sync_request sr set
+		status = case when cr.last_tracking_errors is null then 'completed' else 'failed' end,
+		errors = cr.last_tracking_errors,
+		finished_at = current_timestamp
+	from chart_repository cr
+	where sr.chart_repository_id = cr.chart_repository_id
+	and sr.chart_repository_sync_request_id = any($1::uuid[])`
+	syncRequestsIDs := []string{"00000000-0000-0000-0000-000000000001"}
+
+	t.Run("invalid input", func(t *testing.T) {
+		m := NewManager(nil)
+		err := m.CompleteSyncRequests(context.Background(), []string{"invalid"})
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+
+	t.Run("no sync requests provided", func(t *testing.T) {
+		m := NewManager(nil)
+		err := m.CompleteSyncRequests(context.Background(), nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("database update succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, syncRequestsIDs).Return(nil)
+		m := NewManager(db)
+
+		err := m.CompleteSyncRequests(context.Background(), syncRequestsIDs)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, syncRequestsIDs).Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		err := m.CompleteSyncRequests(context.Background(), syncRequestsIDs)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestDelete(t *testing.T) {
 	dbQuery := "select delete_chart_repository($1::uuid, $2::text)"
 	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
@@ -319,6 +573,30 @@ func TestGetByName(t *testing.T) {
 		db.AssertExpectations(t)
 	})
 
+	t.Run("get existing repository with credentials by name", func(t *testing.T) {
+		creds := &hub.ChartRepositoryCredentials{Token: "token"}
+		encrypted, _ := encryptCredentials("key", creds)
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repo1").Return([]byte(fmt.Sprintf(`
+		{
+			"chart_repository_id": "00000000-0000-0000-0000-000000000001",
+			"name": "repo1",
+			"url": "https://repo1.com",
+			"credentials": "%s",
+			"tls_config": {"ca_bundle": "ca"}
+		}
+		`, encrypted)), nil)
+
+		r, err := NewManager(db, WithCredentialsKey("key")).GetByName(context.Background(), "repo1")
+		require.NoError(t, err)
+		assert.Equal(t, creds, r.Credentials)
+		assert.Equal(t, &hub.ChartRepositoryTLSConfig{CABundle: "ca"}, r.TLSConfig)
+
+		_, err = NewManager(db, WithCredentialsKey("other")).GetByName(context.Background(), "repo1")
+		assert.Error(t, err)
+		db.AssertExpectations(t)
+	})
+
 	t.Run("database error calling get_chart_repository_by_name", func(t *testing.T) {
 		db := &tests.DBMock{}
 		db.On("QueryRow", dbQuery, "repo1").Return(nil, tests.ErrFakeDatabaseFailure)
@@ -447,6 +725,358 @@ func TestGetOwnedByUserJSON(t *testing.T) {
 	})
 }
 
+func TestGetLintSummaryJSON(t *testing.T) {
+	dbQuery := "select get_chart_repository_lint_summary($1::text)"
+
+	t.Run("invalid input", func(t *testing.T) {
+		m := NewManager(nil)
+		_, err := m.GetLintSummaryJSON(context.Background(), "")
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.Contains(t, err.Error(), "name not provided")
+	})
+
+	t.Run("chart repository not found", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repo1").Return(nil, pgx.ErrNoRows)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetLintSummaryJSON(context.Background(), "repo1")
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repo1").Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetLintSummaryJSON(context.Background(), "repo1")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("lint summary returned successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "repo1").Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetLintSummaryJSON(context.Background(), "repo1")
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetSyncRequestJSON(t *testing.T) {
+	dbQuery := "select get_chart_repository_sync_request($1::uuid, $2::text, $3::uuid)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+	syncRequestID := "00000000-0000-0000-0000-000000000001"
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil)
+		assert.Panics(t, func() {
+			_, _ = m.GetSyncRequestJSON(context.Background(), "repo1", syncRequestID)
+		})
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg        string
+			name          string
+			syncRequestID string
+		}{
+			{
+				"name not provided",
+				"",
+				syncRequestID,
+			},
+			{
+				"invalid sync request id",
+				"repo1",
+				"invalid",
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				m := NewManager(nil)
+				_, err := m.GetSyncRequestJSON(ctx, tc.name, tc.syncRequestID)
+				assert.True(t, errors.Is(err, ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+
+	t.Run("sync request not found", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", syncRequestID).Return(nil, pgx.ErrNoRows)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetSyncRequestJSON(ctx, "repo1", syncRequestID)
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", syncRequestID).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetSyncRequestJSON(ctx, "repo1", syncRequestID)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("sync request data returned successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", syncRequestID).Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetSyncRequestJSON(ctx, "repo1", syncRequestID)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetTrackingRunsJSON(t *testing.T) {
+	dbQuery := "select get_chart_repository_tracking_runs($1::uuid, $2::text, $3::int)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil)
+		assert.Panics(t, func() {
+			_, _ = m.GetTrackingRunsJSON(context.Background(), "repo1", 10)
+		})
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			name   string
+			limit  int
+		}{
+			{
+				"name not provided",
+				"",
+				10,
+			},
+			{
+				"invalid limit",
+				"repo1",
+				0,
+			},
+			{
+				"invalid limit",
+				"repo1",
+				101,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				m := NewManager(nil)
+				_, err := m.GetTrackingRunsJSON(ctx, tc.name, tc.limit)
+				assert.True(t, errors.Is(err, ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", 10).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetTrackingRunsJSON(ctx, "repo1", 10)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("tracking runs data returned successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1", 10).Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetTrackingRunsJSON(ctx, "repo1", 10)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestRegisterTrackingRun(t *testing.T) {
+	dbQuery := "select register_tracking_run($1::jsonb)"
+	run := &hub.TrackingRun{
+		ChartRepositoryID:  "00000000-0000-0000-0000-000000000001",
+		StartedAt:          1,
+		FinishedAt:         2,
+		VersionsRegistered: 1,
+		Errors: []*hub.TrackingError{
+			{
+				Code:     hub.RegisterFailed,
+				Severity: hub.SeverityError,
+				Chart:    "pkg1",
+				Version:  "1.0.0",
+				Message:  "error1",
+			},
+		},
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			run    *hub.TrackingRun
+		}{
+			{
+				"tracking run not provided",
+				nil,
+			},
+			{
+				"invalid chart repository id",
+				&hub.TrackingRun{ChartRepositoryID: "invalid"},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				m := NewManager(nil)
+				err := m.RegisterTrackingRun(context.Background(), tc.run)
+				assert.True(t, errors.Is(err, ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, mock.Anything).Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		err := m.RegisterTrackingRun(context.Background(), run)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("tracking run registered successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, mock.Anything).Return(nil)
+		m := NewManager(db)
+
+		err := m.RegisterTrackingRun(context.Background(), run)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestRequestSync(t *testing.T) {
+	dbQuery := "select request_chart_repository_sync($1::uuid, $2::text)"
+	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
+
+	t.Run("user id not found in ctx", func(t *testing.T) {
+		m := NewManager(nil)
+		assert.Panics(t, func() {
+			_, _ = m.RequestSync(context.Background(), "repo1")
+		})
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		m := NewManager(nil)
+		_, err := m.RequestSync(ctx, "")
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1").Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		_, err := m.RequestSync(ctx, "repo1")
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("sync requested successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, "userID", "repo1").Return("syncRequestID", nil)
+		m := NewManager(db)
+
+		syncRequestID, err := m.RequestSync(ctx, "repo1")
+		assert.NoError(t, err)
+		assert.Equal(t, "syncRequestID", syncRequestID)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestSetLastIndexInfo(t *testing.T) {
+	repoID := "00000000-0000-0000-0000-000000000001"
+	dbQuery := `
+	update chart_repository set
+		last_index_digest = nullif($2, ''),
+		last_index_etag = nullif($3, ''),
+		last_index_last_modified = nullif($4, '')
+	where chart_repository_id = $1`
+	info := &hub.ChartRepositoryIndexInfo{
+		Digest:       "digest",
+		ETag:         "etag",
+		LastModified: "Wed, 21 Oct 2015 07:28:00 GMT",
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			repoID string
+			info   *hub.ChartRepositoryIndexInfo
+		}{
+			{
+				"invalid chart repository id",
+				"invalid",
+				info,
+			},
+			{
+				"index info not provided",
+				repoID,
+				nil,
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				m := NewManager(nil)
+				err := m.SetLastIndexInfo(context.Background(), tc.repoID, tc.info)
+				assert.True(t, errors.Is(err, ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+
+	t.Run("database update succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, repoID, info.Digest, info.ETag, info.LastModified).Return(nil)
+		m := NewManager(db)
+
+		err := m.SetLastIndexInfo(context.Background(), repoID, info)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("Exec", dbQuery, repoID, info.Digest, info.ETag, info.LastModified).
+			Return(tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		err := m.SetLastIndexInfo(context.Background(), repoID, info)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestSetLastTrackingResults(t *testing.T) {
 	repoID := "00000000-0000-0000-0000-000000000001"
 	dbQuery := `
@@ -484,6 +1114,7 @@ func TestSetLastTrackingResults(t *testing.T) {
 
 func TestUpdate(t *testing.T) {
 	dbQuery := "select update_chart_repository($1::uuid, $2::jsonb)"
+	dbQueryGetByName := "select get_chart_repository_by_name($1::text)"
 	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
 
 	r := &hub.ChartRepository{
@@ -520,6 +1151,24 @@ func TestUpdate(t *testing.T) {
 				},
 				nil,
 			},
+			{
+				"invalid tracking interval",
+				&hub.ChartRepository{
+					Name:             "repo1",
+					URL:              "https://repo1.com",
+					TrackingInterval: -1,
+				},
+				nil,
+			},
+			{
+				"credentials encryption key not configured",
+				&hub.ChartRepository{
+					Name:        "repo1",
+					URL:         "https://repo1.com",
+					Credentials: &hub.ChartRepositoryCredentials{Token: "token"},
+				},
+				nil,
+			},
 			{
 				"invalid url",
 				&hub.ChartRepository{
@@ -532,21 +1181,35 @@ func TestUpdate(t *testing.T) {
 		for _, tc := range testCases {
 			tc := tc
 			t.Run(tc.errMsg, func(t *testing.T) {
+				db := &tests.DBMock{}
 				l := &IndexLoaderMock{}
 				if tc.lErr != nil {
+					db.On("QueryRow", dbQueryGetByName, tc.r.Name).Return(nil, pgx.ErrNoRows)
 					l.On("LoadIndex", mock.Anything).Return(nil, tc.lErr)
 				}
-				m := NewManager(nil, WithIndexLoader(l))
+				m := NewManager(db, WithIndexLoader(l))
 
 				err := m.Update(ctx, tc.r)
 				assert.True(t, errors.Is(err, ErrInvalidInput))
+				db.AssertExpectations(t)
 				l.AssertExpectations(t)
 			})
 		}
 	})
 
+	t.Run("database error getting stored chart repository", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryGetByName, "repo1").Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		err := m.Update(ctx, r)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
 	t.Run("database error", func(t *testing.T) {
 		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryGetByName, "repo1").Return(nil, pgx.ErrNoRows)
 		db.On("Exec", dbQuery, "userID", mock.Anything).Return(tests.ErrFakeDatabaseFailure)
 		l := &IndexLoaderMock{}
 		l.On("LoadIndex", mock.Anything).Return(nil, nil)
@@ -560,6 +1223,7 @@ func TestUpdate(t *testing.T) {
 
 	t.Run("update chart repository succeeded", func(t *testing.T) {
 		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryGetByName, "repo1").Return(nil, pgx.ErrNoRows)
 		db.On("Exec", dbQuery, "userID", mock.Anything).Return(nil)
 		l := &IndexLoaderMock{}
 		l.On("LoadIndex", mock.Anything).Return(nil, nil)
@@ -570,4 +1234,137 @@ func TestUpdate(t *testing.T) {
 		db.AssertExpectations(t)
 		l.AssertExpectations(t)
 	})
+
+	t.Run("update falco rules repository", func(t *testing.T) {
+		storedJSON := []byte(`
+		{
+			"name": "repo1",
+			"kind": 1,
+			"url": "https://github.com/org/repo.git"
+		}
+		`)
+
+		t.Run("url unchanged", func(t *testing.T) {
+			db := &tests.DBMock{}
+			db.On("QueryRow", dbQueryGetByName, "repo1").Return(storedJSON, nil)
+			db.On("Exec", dbQuery, "userID", mock.Anything).Return(nil)
+			l := &IndexLoaderMock{}
+			m := NewManager(db, WithIndexLoader(l))
+
+			err := m.Update(ctx, &hub.ChartRepository{
+				Name:        "repo1",
+				DisplayName: "Repository 1",
+				URL:         "https://github.com/org/repo.git",
+			})
+			assert.NoError(t, err)
+			db.AssertExpectations(t)
+			l.AssertExpectations(t)
+		})
+
+		t.Run("local directory url unchanged", func(t *testing.T) {
+			db := &tests.DBMock{}
+			db.On("QueryRow", dbQueryGetByName, "repo1").Return([]byte(`
+			{
+				"name": "repo1",
+				"kind": 1,
+				"url": "file:///packages/falco"
+			}
+			`), nil)
+			db.On("Exec", dbQuery, "userID", mock.Anything).Return(nil)
+			l := &IndexLoaderMock{}
+			m := NewManager(db, WithIndexLoader(l))
+
+			err := m.Update(ctx, &hub.ChartRepository{
+				Name:        "repo1",
+				DisplayName: "Repository 1",
+				URL:         "file:///packages/falco",
+			})
+			assert.NoError(t, err)
+			db.AssertExpectations(t)
+			l.AssertExpectations(t)
+		})
+
+		t.Run("url changed", func(t *testing.T) {
+			db := &tests.DBMock{}
+			db.On("QueryRow", dbQueryGetByName, "repo1").Return(storedJSON, nil)
+			db.On("Exec", dbQuery, "userID", mock.Anything).Return(nil)
+			l := &IndexLoaderMock{}
+			m := NewManager(db, WithIndexLoader(l))
+
+			err := m.Update(ctx, &hub.ChartRepository{
+				Name: "repo1",
+				URL:  "https://github.com/org/repo2.git#falco",
+			})
+			assert.NoError(t, err)
+			db.AssertExpectations(t)
+			l.AssertExpectations(t)
+		})
+
+		t.Run("invalid url", func(t *testing.T) {
+			db := &tests.DBMock{}
+			db.On("QueryRow", dbQueryGetByName, "repo1").Return(storedJSON, nil)
+			l := &IndexLoaderMock{}
+			m := NewManager(db, WithIndexLoader(l))
+
+			err := m.Update(ctx, &hub.ChartRepository{
+				Name: "repo1",
+				URL:  "/packages/falco",
+			})
+			assert.True(t, errors.Is(err, ErrInvalidInput))
+			assert.Contains(t, err.Error(), "invalid url")
+			db.AssertExpectations(t)
+			l.AssertExpectations(t)
+		})
+	})
+
+	t.Run("update operator repository", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryGetByName, "repo1").Return([]byte(`
+		{
+			"name": "repo1",
+			"kind": 3,
+			"url": "https://github.com/operator-framework/community-operators.git#community-operators"
+		}
+		`), nil)
+		db.On("Exec", dbQuery, "userID", mock.Anything).Return(nil)
+		l := &IndexLoaderMock{}
+		m := NewManager(db, WithIndexLoader(l))
+
+		err := m.Update(ctx, &hub.ChartRepository{
+			Name:        "repo1",
+			DisplayName: "Community operators",
+			URL:         "https://github.com/operator-framework/community-operators.git#upstream-community-operators",
+		})
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+		l.AssertExpectations(t)
+	})
+
+	t.Run("stored credentials used to validate url when not provided", func(t *testing.T) {
+		creds := &hub.ChartRepositoryCredentials{Username: "user", Password: "pass"}
+		encrypted, _ := encryptCredentials("key", creds)
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryGetByName, "repo1").Return([]byte(fmt.Sprintf(`
+		{
+			"name": "repo1",
+			"url": "https://repo1.com",
+			"credentials": "%s",
+			"tls_config": {"insecure_skip_verify": true}
+		}
+		`, encrypted)), nil)
+		db.On("Exec", dbQuery, "userID", mock.MatchedBy(func(rJSON []byte) bool {
+			return !strings.Contains(string(rJSON), "credentials")
+		})).Return(nil)
+		l := &IndexLoaderMock{}
+		l.On("LoadIndex", mock.MatchedBy(func(r *hub.ChartRepository) bool {
+			return assert.ObjectsAreEqual(creds, r.Credentials) && r.TLSConfig.InsecureSkipVerify
+		})).Return(nil, nil)
+		m := NewManager(db, WithIndexLoader(l), WithCredentialsKey("key"))
+
+		err := m.Update(ctx, r)
+		assert.NoError(t, err)
+		assert.Nil(t, r.Credentials)
+		db.AssertExpectations(t)
+		l.AssertExpectations(t)
+	})
 }
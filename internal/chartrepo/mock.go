@@ -25,6 +25,22 @@ func (m *ManagerMock) CheckAvailability(ctx context.Context, resourceKind, value
 	return args.Bool(0), args.Error(1)
 }
 
+// ClaimSyncRequests implements the ChartRepositoryManager interface.
+func (m *ManagerMock) ClaimSyncRequests(
+	ctx context.Context,
+	chartRepositoriesIDs []string,
+) ([]*hub.ChartRepositorySyncRequest, error) {
+	args := m.Called(ctx, chartRepositoriesIDs)
+	data, _ := args.Get(0).([]*hub.ChartRepositorySyncRequest)
+	return data, args.Error(1)
+}
+
+// CompleteSyncRequests implements the ChartRepositoryManager interface.
+func (m *ManagerMock) CompleteSyncRequests(ctx context.Context, syncRequestsIDs []string) error {
+	args := m.Called(ctx, syncRequestsIDs)
+	return args.Error(0)
+}
+
 // Delete implements the ChartRepositoryManager interface.
 func (m *ManagerMock) Delete(ctx context.Context, name string) error {
 	args := m.Called(ctx, name)
@@ -69,6 +85,49 @@ func (m *ManagerMock) GetOwnedByUserJSON(ctx context.Context) ([]byte, error) {
 	return data, args.Error(1)
 }
 
+// GetSyncRequestJSON implements the ChartRepositoryManager interface.
+func (m *ManagerMock) GetSyncRequestJSON(ctx context.Context, name, syncRequestID string) ([]byte, error) {
+	args := m.Called(ctx, name, syncRequestID)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+// GetLintSummaryJSON implements the ChartRepositoryManager interface.
+func (m *ManagerMock) GetLintSummaryJSON(ctx context.Context, name string) ([]byte, error) {
+	args := m.Called(ctx, name)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+// GetTrackingRunsJSON implements the ChartRepositoryManager interface.
+func (m *ManagerMock) GetTrackingRunsJSON(ctx context.Context, name string, limit int) ([]byte, error) {
+	args := m.Called(ctx, name, limit)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+// RegisterTrackingRun implements the ChartRepositoryManager interface.
+func (m *ManagerMock) RegisterTrackingRun(ctx context.Context, run *hub.TrackingRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+// RequestSync implements the ChartRepositoryManager interface.
+func (m *ManagerMock) RequestSync(ctx context.Context, name string) (string, error) {
+	args := m.Called(ctx, name)
+	return args.String(0), args.Error(1)
+}
+
+// SetLastIndexInfo implements the ChartRepositoryManager interface.
+func (m *ManagerMock) SetLastIndexInfo(
+	ctx context.Context,
+	chartRepositoryID string,
+	info *hub.ChartRepositoryIndexInfo,
+) error {
+	args := m.Called(ctx, chartRepositoryID, info)
+	return args.Error(0)
+}
+
 // SetLastTrackingResults implements the ChartRepositoryManager interface.
 func (m *ManagerMock) SetLastTrackingResults(ctx context.Context, chartRepositoryID, errs string) error {
 	args := m.Called(ctx, chartRepositoryID, errs)
@@ -92,3 +151,33 @@ func (m *IndexLoaderMock) LoadIndex(r *hub.ChartRepository) (*repo.IndexFile, er
 	indexFile, _ := args.Get(0).(*repo.IndexFile)
 	return indexFile, args.Error(1)
 }
+
+// LoadIndexIfChanged implements the IndexLoader interface.
+func (m *IndexLoaderMock) LoadIndexIfChanged(r *hub.ChartRepository) (
+	*repo.IndexFile,
+	*hub.ChartRepositoryIndexInfo,
+	error,
+) {
+	args := m.Called(r)
+	indexFile, _ := args.Get(0).(*repo.IndexFile)
+	info, _ := args.Get(1).(*hub.ChartRepositoryIndexInfo)
+	return indexFile, info, args.Error(2)
+}
+
+// PackagesLoaderMock is a mock implementation of the PackagesLoader
+// interface.
+type PackagesLoaderMock struct {
+	mock.Mock
+}
+
+// LoadPackagesIfChanged implements the PackagesLoader interface.
+func (m *PackagesLoaderMock) LoadPackagesIfChanged(r *hub.ChartRepository) (
+	[]*hub.Package,
+	*hub.ChartRepositoryIndexInfo,
+	error,
+) {
+	args := m.Called(r)
+	packages, _ := args.Get(0).([]*hub.Package)
+	info, _ := args.Get(1).(*hub.ChartRepositoryIndexInfo)
+	return packages, info, args.Error(2)
+}
@@ -0,0 +1,502 @@
+package chartrepo
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/artifacthub/hub/internal/hub"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+const (
+	// ociScheme represents the scheme used by chart repositories and charts
+	// references located in OCI registries.
+	ociScheme = "oci"
+
+	// ociManifestMediaType represents the media type of OCI image manifests.
+	ociManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+
+	// helmChartContentMediaType represents the media type of the layer that
+	// contains the chart archive in charts stored in OCI registries.
+	helmChartContentMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+
+	// helmChartContentLegacyMediaType represents the media type of the chart
+	// archive layer used by Helm versions prior to 3.7.
+	helmChartContentLegacyMediaType = "application/tar+gzip"
+
+	// ociTagsPageSize represents the number of tags requested in each page
+	// when listing the tags of a repository.
+	ociTagsPageSize = 1000
+)
+
+var (
+	// ociHTTPClient is the http client used to interact with OCI registries.
+	ociHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+	// bearerChallengeParamRE is a regexp used to extract the parameters of a
+	// bearer authentication challenge.
+	bearerChallengeParamRE = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+	// nextPageLinkRE is a regexp used to extract the url of the next page
+	// from the Link header of paginated responses.
+	nextPageLinkRE = regexp.MustCompile(`<([^>]+)>\s*;\s*rel="?next"?`)
+
+	// errNoChartLayer indicates that the manifest does not contain a layer
+	// with a chart archive.
+	errNoChartLayer = errors.New("chart layer not found in manifest")
+)
+
+// IsOCIReference checks if the provided url references a chart repository or
+// a chart located in an OCI registry.
+func IsOCIReference(u string) bool {
+	return strings.HasPrefix(u, ociScheme+"://")
+}
+
+// ociManifest represents the parts of an OCI image manifest needed to locate
+// the chart archive.
+type ociManifest struct {
+	Layers []struct {
+		MediaType string `json:"mediaType"`
+		Digest    string `json:"digest"`
+	} `json:"layers"`
+}
+
+// chartLayerDigest returns the digest of the layer containing the chart
+// archive.
+func (m *ociManifest) chartLayerDigest() (string, error) {
+	for _, layer := range m.Layers {
+		switch layer.MediaType {
+		case helmChartContentMediaType, helmChartContentLegacyMediaType:
+			return layer.Digest, nil
+		}
+	}
+	return "", errNoChartLayer
+}
+
+// OCIClient provides a mechanism to list and pull the charts stored in OCI
+// registries. Registries requiring basic auth or bearer tokens are supported,
+// using the credentials of the chart repository when available.
+type OCIClient struct {
+	mu             sync.Mutex
+	authorizations map[string]string // K: registry host and repository
+}
+
+// ListTags returns the tags available in the provided chart repository, whose
+// url references a repository in an OCI registry (i.e.
+// oci://registry/namespace/chart). When the registry returns the tags in
+// several pages, the links to the next page are followed until all of them
+// have been collected.
+func (c *OCIClient) ListTags(r *hub.ChartRepository) ([]string, error) {
+	host, repository, _, err := parseOCIReference(r.URL)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	u := endpointURL(host, repository, fmt.Sprintf("tags/list?n=%d", ociTagsPageSize))
+	for u != "" {
+		header, data, err := c.do(r, "GET", host, repository, u, "")
+		if err != nil {
+			return nil, err
+		}
+		var tagsList struct {
+			Tags []string `json:"tags"`
+		}
+		if err := json.Unmarshal(data, &tagsList); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tagsList.Tags...)
+		next, err := nextPageURL(u, header.Get("Link"))
+		if err != nil {
+			return nil, err
+		}
+		if next == u || len(tagsList.Tags) == 0 {
+			break
+		}
+		u = next
+	}
+	return tags, nil
+}
+
+// PullChart downloads the chart archive referenced by the url provided (i.e.
+// oci://registry/namespace/chart:version), which belongs to the chart
+// repository given. The archive is verified against the digest of the layer
+// containing it.
+func (c *OCIClient) PullChart(r *hub.ChartRepository, u string) ([]byte, error) {
+	host, repository, tag, err := parseOCIReference(u)
+	if err != nil {
+		return nil, err
+	}
+	if tag == "" {
+		return nil, errors.New("chart reference tag not provided")
+	}
+	manifest, _, err := c.getManifest(r, host, repository, tag)
+	if err != nil {
+		return nil, err
+	}
+	layerDigest, err := manifest.chartLayerDigest()
+	if err != nil {
+		return nil, err
+	}
+	data, err := c.get(r, host, repository, path.Join("blobs", layerDigest), "")
+	if err != nil {
+		return nil, err
+	}
+	if err := VerifyDigest(data, layerDigest); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// getManifest downloads the manifest of the provided tag, returning it along
+// with its digest.
+func (c *OCIClient) getManifest(r *hub.ChartRepository, host, repository, tag string) (
+	*ociManifest,
+	string,
+	error,
+) {
+	data, err := c.get(r, host, repository, path.Join("manifests", tag), ociManifestMediaType)
+	if err != nil {
+		return nil, "", err
+	}
+	manifest := &ociManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, "", err
+	}
+	return manifest, fmt.Sprintf("sha256:%x", sha256.Sum256(data)), nil
+}
+
+// getManifestDigest returns the digest of the manifest of the provided tag
+// without downloading it, using a HEAD request. An empty digest is returned
+// when the registry does not report it.
+func (c *OCIClient) getManifestDigest(r *hub.ChartRepository, host, repository, tag string) (string, error) {
+	u := endpointURL(host, repository, path.Join("manifests", tag))
+	header, _, err := c.do(r, "HEAD", host, repository, u, ociManifestMediaType)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("Docker-Content-Digest"), nil
+}
+
+// get performs a GET request to the registry endpoint provided, authorizing
+// the request as requested by the registry when needed.
+func (c *OCIClient) get(r *hub.ChartRepository, host, repository, endpoint, accept string) ([]byte, error) {
+	_, data, err := c.do(r, "GET", host, repository, endpointURL(host, repository, endpoint), accept)
+	return data, err
+}
+
+// do performs a request to the registry url provided, authorizing it as
+// requested by the registry when needed. The response headers and body are
+// returned.
+func (c *OCIClient) do(r *hub.ChartRepository, method, host, repository, u, accept string) (
+	http.Header,
+	[]byte,
+	error,
+) {
+	hc, err := httpClient(r, ociHTTPClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	authKey := host + "/" + repository
+	var resp *http.Response
+	for attempt := 0; attempt < 2; attempt++ {
+		req, err := http.NewRequest(method, u, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if authorization := c.getAuthorization(authKey); authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		resp, err = hc.Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			break
+		}
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		authorization, err := authorize(hc, r.Credentials, challenge)
+		if err != nil {
+			return nil, nil, err
+		}
+		c.setAuthorization(authKey, authorization)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp.Header, data, nil
+}
+
+// getAuthorization returns the authorization previously obtained for the key
+// provided.
+func (c *OCIClient) getAuthorization(key string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.authorizations[key]
+}
+
+// setAuthorization stores the authorization obtained for the key provided.
+func (c *OCIClient) setAuthorization(key, authorization string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.authorizations == nil {
+		c.authorizations = make(map[string]string)
+	}
+	c.authorizations[key] = authorization
+}
+
+// authorize returns the value of the authorization header that should be used
+// to satisfy the authentication challenge provided. Bearer tokens are
+// requested to the registry token service, using the credentials available.
+func authorize(hc *http.Client, creds *hub.ChartRepositoryCredentials, challenge string) (string, error) {
+	if creds == nil {
+		creds = &hub.ChartRepositoryCredentials{}
+	}
+	switch {
+	case strings.HasPrefix(challenge, "Basic "):
+		if creds.Username == "" {
+			return "", errors.New("registry requires credentials")
+		}
+		req := &http.Request{Header: make(http.Header)}
+		req.SetBasicAuth(creds.Username, creds.Password)
+		return req.Header.Get("Authorization"), nil
+	case strings.HasPrefix(challenge, "Bearer "):
+		if creds.Username == "" && creds.Token != "" {
+			return "Bearer " + creds.Token, nil
+		}
+		token, err := requestToken(hc, creds, challenge)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", errors.New("unsupported authentication challenge received")
+	}
+}
+
+// requestToken requests a bearer token using the details of the
+// authentication challenge provided. When a username is available, the
+// credentials are sent to the token service using basic auth.
+func requestToken(hc *http.Client, creds *hub.ChartRepositoryCredentials, challenge string) (string, error) {
+	params := make(map[string]string)
+	for _, m := range bearerChallengeParamRE.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	if params["realm"] == "" {
+		return "", errors.New("authentication realm not provided")
+	}
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	for _, param := range []string{"service", "scope"} {
+		if params[param] != "" {
+			q.Set(param, params[param])
+		}
+	}
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if creds.Username != "" {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code received requesting token: %d", resp.StatusCode)
+	}
+	var tokenResp struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+		return "", err
+	}
+	if tokenResp.Token != "" {
+		return tokenResp.Token, nil
+	}
+	return tokenResp.AccessToken, nil
+}
+
+// ociTag represents a semver tag of a chart repository located in an OCI
+// registry.
+type ociTag struct {
+	name           string
+	version        string
+	manifestDigest string
+	manifest       *ociManifest
+}
+
+// loadOCIIndex builds an index file from the charts available in the OCI
+// repository provided. Each semver tag is considered a chart version, and the
+// digest of its manifest is used as the chart version digest. When the last
+// index information is provided, the index file will only be returned if the
+// tags or their manifests have changed. To detect changes, the manifests
+// digests are obtained using HEAD requests, so manifests are only downloaded
+// when something has changed.
+func loadOCIIndex(c *OCIClient, r *hub.ChartRepository, lastInfo *hub.ChartRepositoryIndexInfo) (
+	*repo.IndexFile,
+	*hub.ChartRepositoryIndexInfo,
+	error,
+) {
+	host, repository, _, err := parseOCIReference(r.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	tags, err := c.ListTags(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(tags)
+
+	// Get the manifests digests of the semver tags and check if they have
+	// changed
+	var semverTags []*ociTag
+	h := sha256.New()
+	for _, tag := range tags {
+		// OCI tags cannot contain the + character, so Helm replaces it with _
+		version := strings.Replace(tag, "_", "+", -1)
+		if _, err := semver.StrictNewVersion(version); err != nil {
+			continue
+		}
+		t := &ociTag{name: tag, version: version}
+		t.manifestDigest, err = c.getManifestDigest(r, host, repository, tag)
+		if err != nil {
+			return nil, nil, err
+		}
+		if t.manifestDigest == "" {
+			// The registry did not report the digest, so the manifest needs to
+			// be downloaded to compute it
+			t.manifest, t.manifestDigest, err = c.getManifest(r, host, repository, tag)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		fmt.Fprintf(h, "%s@%s\n", tag, t.manifestDigest)
+		semverTags = append(semverTags, t)
+	}
+	info := &hub.ChartRepositoryIndexInfo{
+		Digest: fmt.Sprintf("%x", h.Sum(nil)),
+	}
+	if lastInfo != nil && info.Digest == lastInfo.Digest {
+		return nil, info, nil
+	}
+
+	// Collect chart versions from the tags whose manifest contains a chart
+	name := path.Base(repository)
+	var chartVersions repo.ChartVersions
+	for _, t := range semverTags {
+		if t.manifest == nil {
+			t.manifest, _, err = c.getManifest(r, host, repository, t.name)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+		if _, err := t.manifest.chartLayerDigest(); err != nil {
+			continue
+		}
+		chartVersions = append(chartVersions, &repo.ChartVersion{
+			Metadata: &chart.Metadata{
+				Name:    name,
+				Version: t.version,
+			},
+			Digest: t.manifestDigest,
+			URLs:   []string{fmt.Sprintf("%s://%s/%s:%s", ociScheme, host, repository, t.name)},
+		})
+	}
+
+	// Prepare index file
+	indexFile := repo.NewIndexFile()
+	if len(chartVersions) > 0 {
+		indexFile.Entries[name] = chartVersions
+	}
+	indexFile.SortEntries()
+	return indexFile, info, nil
+}
+
+// endpointURL returns the url of the provided endpoint of the registry
+// repository given.
+func endpointURL(host, repository, endpoint string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/%s", registryScheme(host), host, repository, endpoint)
+}
+
+// nextPageURL returns the url of the next page referenced in the Link header
+// provided, resolved against the url of the current page. An empty string is
+// returned when there are no more pages.
+func nextPageURL(current, link string) (string, error) {
+	m := nextPageLinkRE.FindStringSubmatch(link)
+	if m == nil {
+		return "", nil
+	}
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", err
+	}
+	next, err := url.Parse(m[1])
+	if err != nil {
+		return "", err
+	}
+	return base.ResolveReference(next).String(), nil
+}
+
+// parseOCIReference parses the provided OCI reference, returning the
+// registry host, the repository and the tag (if available).
+func parseOCIReference(u string) (host, repository, tag string, err error) {
+	if !IsOCIReference(u) {
+		return "", "", "", errors.New("invalid oci reference")
+	}
+	ref := strings.TrimPrefix(u, ociScheme+"://")
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", errors.New("invalid oci reference")
+	}
+	host, repository = parts[0], strings.TrimSuffix(parts[1], "/")
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	if repository == "" {
+		return "", "", "", errors.New("invalid oci reference")
+	}
+	return host, repository, tag, nil
+}
+
+// registryScheme returns the scheme that should be used to reach the registry
+// host provided. Plain http is only used for registries running locally.
+func registryScheme(host string) string {
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	if hostname == "localhost" || net.ParseIP(hostname).IsLoopback() {
+		return "http"
+	}
+	return "https"
+}
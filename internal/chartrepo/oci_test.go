@@ -0,0 +1,229 @@
+package chartrepo
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOCIIndex(t *testing.T) {
+	registry := tests.NewOCIRegistry()
+	defer registry.Close()
+	digest1 := registry.PushChart("charts/pkg1", "1.0.0", []byte("pkg1-1.0.0"))
+	digest2 := registry.PushChart("charts/pkg1", "1.1.0-rc.1_build.2", []byte("pkg1-1.1.0"))
+	registry.PushChart("charts/pkg1", "latest", []byte("pkg1-1.1.0"))
+	registry.PushManifest("charts/pkg1", "2.0.0", []byte(`{"schemaVersion":2,"layers":[]}`))
+	r := &hub.ChartRepository{URL: "oci://" + registry.Host() + "/charts/pkg1"}
+
+	t.Run("index file built from semver tags", func(t *testing.T) {
+		l := &IndexLoader{}
+
+		indexFile, err := l.LoadIndex(r)
+		require.NoError(t, err)
+		require.Len(t, indexFile.Entries["pkg1"], 2)
+		v1 := indexFile.Entries["pkg1"][0]
+		assert.Equal(t, "1.1.0-rc.1+build.2", v1.Version)
+		assert.Equal(t, digest2, v1.Digest)
+		assert.Equal(t, []string{r.URL + ":1.1.0-rc.1_build.2"}, v1.URLs)
+		v2 := indexFile.Entries["pkg1"][1]
+		assert.Equal(t, "pkg1", v2.Name)
+		assert.Equal(t, "1.0.0", v2.Version)
+		assert.Equal(t, digest1, v2.Digest)
+		assert.Equal(t, []string{r.URL + ":1.0.0"}, v2.URLs)
+	})
+
+	t.Run("index file unchanged", func(t *testing.T) {
+		l := &IndexLoader{}
+		_, info, err := l.LoadIndexIfChanged(r)
+		require.NoError(t, err)
+		manifestDownloads := registry.ManifestDownloads()
+
+		indexFile, info2, err := l.LoadIndexIfChanged(&hub.ChartRepository{
+			URL:           r.URL,
+			LastIndexInfo: info,
+		})
+		require.NoError(t, err)
+		assert.Nil(t, indexFile)
+		assert.Equal(t, info, info2)
+		assert.Equal(t, manifestDownloads, registry.ManifestDownloads())
+	})
+
+	t.Run("index file changed when a tag is pushed again", func(t *testing.T) {
+		registry := tests.NewOCIRegistry()
+		defer registry.Close()
+		registry.PushChart("pkg2", "1.0.0", []byte("pkg2-1.0.0"))
+		r := &hub.ChartRepository{URL: "oci://" + registry.Host() + "/pkg2"}
+		l := &IndexLoader{}
+		_, info, err := l.LoadIndexIfChanged(r)
+		require.NoError(t, err)
+
+		digest := registry.PushChart("pkg2", "1.0.0", []byte("pkg2-1.0.0-updated"))
+		indexFile, info2, err := l.LoadIndexIfChanged(&hub.ChartRepository{
+			URL:           r.URL,
+			LastIndexInfo: info,
+		})
+		require.NoError(t, err)
+		require.Len(t, indexFile.Entries["pkg2"], 1)
+		assert.Equal(t, digest, indexFile.Entries["pkg2"][0].Digest)
+		assert.NotEqual(t, info, info2)
+	})
+
+	t.Run("tags listed across several pages", func(t *testing.T) {
+		registry := tests.NewOCIRegistry()
+		defer registry.Close()
+		registry.TagsPageSize = 2
+		for _, tag := range []string{"1.0.0", "1.1.0", "1.2.0", "2.0.0", "latest"} {
+			registry.PushChart("pkg2", tag, []byte("pkg2-"+tag))
+		}
+		r := &hub.ChartRepository{URL: "oci://" + registry.Host() + "/pkg2"}
+
+		tags, err := (&OCIClient{}).ListTags(r)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"1.0.0", "1.1.0", "1.2.0", "2.0.0", "latest"}, tags)
+
+		indexFile, err := (&IndexLoader{}).LoadIndex(r)
+		require.NoError(t, err)
+		assert.Len(t, indexFile.Entries["pkg2"], 4)
+	})
+
+	t.Run("registry not reporting manifests digests", func(t *testing.T) {
+		registry := tests.NewOCIRegistry()
+		defer registry.Close()
+		registry.OmitDigestHeader = true
+		digest := registry.PushChart("pkg2", "1.0.0", []byte("pkg2-1.0.0"))
+		l := &IndexLoader{}
+
+		indexFile, err := l.LoadIndex(&hub.ChartRepository{URL: "oci://" + registry.Host() + "/pkg2"})
+		require.NoError(t, err)
+		require.Len(t, indexFile.Entries["pkg2"], 1)
+		assert.Equal(t, digest, indexFile.Entries["pkg2"][0].Digest)
+		assert.Equal(t, 1, registry.ManifestDownloads())
+	})
+
+	t.Run("registry requiring bearer token", func(t *testing.T) {
+		registry := tests.NewOCIRegistry()
+		defer registry.Close()
+		registry.RequireToken = true
+		registry.PushChart("pkg2", "1.0.0", []byte("pkg2-1.0.0"))
+		l := &IndexLoader{}
+
+		indexFile, err := l.LoadIndex(&hub.ChartRepository{URL: "oci://" + registry.Host() + "/pkg2"})
+		require.NoError(t, err)
+		assert.Len(t, indexFile.Entries["pkg2"], 1)
+	})
+
+	t.Run("repository not found", func(t *testing.T) {
+		l := &IndexLoader{}
+
+		_, err := l.LoadIndex(&hub.ChartRepository{URL: "oci://" + registry.Host() + "/charts/pkg2"})
+		assert.Error(t, err)
+	})
+}
+
+func TestOCIClientPullChart(t *testing.T) {
+	registry := tests.NewOCIRegistry()
+	defer registry.Close()
+	registry.PushChart("charts/pkg1", "1.0.0", []byte("pkg1-1.0.0"))
+	registry.PushManifest("charts/pkg1", "2.0.0", []byte(`{"schemaVersion":2,"layers":[]}`))
+	ref := "oci://" + registry.Host() + "/charts/pkg1"
+	r := &hub.ChartRepository{URL: ref}
+	c := &OCIClient{}
+
+	t.Run("chart pulled successfully", func(t *testing.T) {
+		data, err := c.PullChart(r, ref+":1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("pkg1-1.0.0"), data)
+	})
+
+	t.Run("tag not provided", func(t *testing.T) {
+		_, err := c.PullChart(r, ref)
+		assert.Error(t, err)
+	})
+
+	t.Run("tag not found", func(t *testing.T) {
+		_, err := c.PullChart(r, ref+":3.0.0")
+		assert.Error(t, err)
+	})
+
+	t.Run("manifest without chart layer", func(t *testing.T) {
+		_, err := c.PullChart(r, ref+":2.0.0")
+		assert.Equal(t, errNoChartLayer, err)
+	})
+
+	t.Run("chart layer digest mismatch", func(t *testing.T) {
+		registry := tests.NewOCIRegistry()
+		defer registry.Close()
+		archive := []byte("pkg1-1.0.0")
+		registry.PushChart("charts/pkg1", "1.0.0", archive)
+		registry.TamperBlob(fmt.Sprintf("sha256:%x", sha256.Sum256(archive)), []byte("tampered"))
+		ref := "oci://" + registry.Host() + "/charts/pkg1"
+
+		_, err := (&OCIClient{}).PullChart(&hub.ChartRepository{URL: ref}, ref+":1.0.0")
+		var e *DigestMismatchError
+		assert.True(t, errors.As(err, &e))
+	})
+
+	t.Run("registry requiring credentials", func(t *testing.T) {
+		registry := tests.NewOCIRegistry()
+		defer registry.Close()
+		registry.RequireToken = true
+		registry.Username = "user"
+		registry.Password = "pass"
+		registry.PushChart("charts/pkg1", "1.0.0", []byte("pkg1-1.0.0"))
+		ref := "oci://" + registry.Host() + "/charts/pkg1"
+
+		_, err := (&OCIClient{}).PullChart(&hub.ChartRepository{URL: ref}, ref+":1.0.0")
+		assert.Error(t, err)
+
+		data, err := (&OCIClient{}).PullChart(&hub.ChartRepository{
+			URL: ref,
+			Credentials: &hub.ChartRepositoryCredentials{
+				Username: "user",
+				Password: "pass",
+			},
+		}, ref+":1.0.0")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("pkg1-1.0.0"), data)
+	})
+}
+
+func TestParseOCIReference(t *testing.T) {
+	testCases := []struct {
+		ref        string
+		host       string
+		repository string
+		tag        string
+		valid      bool
+	}{
+		{"oci://registry.io/charts/pkg1", "registry.io", "charts/pkg1", "", true},
+		{"oci://registry.io:5000/charts/pkg1:1.0.0", "registry.io:5000", "charts/pkg1", "1.0.0", true},
+		{"oci://registry.io/pkg1/", "registry.io", "pkg1", "", true},
+		{"oci://registry.io", "", "", "", false},
+		{"oci:///pkg1", "", "", "", false},
+		{"https://registry.io/pkg1", "", "", "", false},
+	}
+	for _, tc := range testCases {
+		host, repository, tag, err := parseOCIReference(tc.ref)
+		if !tc.valid {
+			assert.Error(t, err, tc.ref)
+			continue
+		}
+		require.NoError(t, err, tc.ref)
+		assert.Equal(t, tc.host, host, tc.ref)
+		assert.Equal(t, tc.repository, repository, tc.ref)
+		assert.Equal(t, tc.tag, tag, tc.ref)
+	}
+}
+
+func TestRegistryScheme(t *testing.T) {
+	assert.Equal(t, "http", registryScheme("localhost:5000"))
+	assert.Equal(t, "http", registryScheme("127.0.0.1:5000"))
+	assert.Equal(t, "https", registryScheme("registry.io"))
+	assert.Equal(t, "https", registryScheme("registry.io:5000"))
+}
@@ -0,0 +1,329 @@
+package chartrepo
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// csvKind represents the kind of the OLM cluster service versions.
+	csvKind = "ClusterServiceVersion"
+
+	// crdKind represents the kind of the Kubernetes custom resource
+	// definitions.
+	crdKind = "CustomResourceDefinition"
+)
+
+// olmPackage represents an OLM package manifest, which defines the channels
+// available for an operator.
+type olmPackage struct {
+	PackageName    string        `json:"packageName"`
+	DefaultChannel string        `json:"defaultChannel"`
+	Channels       []*olmChannel `json:"channels"`
+}
+
+// olmChannel represents a channel of an OLM package.
+type olmChannel struct {
+	Name       string `json:"name"`
+	CurrentCSV string `json:"currentCSV"`
+}
+
+// olmCSV represents the subset of the fields of an OLM cluster service
+// version needed to prepare the corresponding package version.
+type olmCSV struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name        string            `json:"name"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		DisplayName string   `json:"displayName"`
+		Description string   `json:"description"`
+		Version     string   `json:"version"`
+		Replaces    string   `json:"replaces"`
+		Keywords    []string `json:"keywords"`
+		Maturity    string   `json:"maturity"`
+		Provider    struct {
+			Name string `json:"name"`
+		} `json:"provider"`
+		Links       []*hub.Link       `json:"links"`
+		Maintainers []*hub.Maintainer `json:"maintainers"`
+		Icon        []struct {
+			Data      string `json:"base64data"`
+			MediaType string `json:"mediatype"`
+		} `json:"icon"`
+		InstallModes []*olmInstallMode `json:"installModes"`
+		CRDs         struct {
+			Owned []*olmCRD `json:"owned"`
+		} `json:"customresourcedefinitions"`
+		RelatedImages []struct {
+			Image string `json:"image"`
+		} `json:"relatedImages"`
+	} `json:"spec"`
+}
+
+// olmInstallMode represents an install mode supported (or not) by an
+// operator.
+type olmInstallMode struct {
+	Type      string `json:"type"`
+	Supported bool   `json:"supported"`
+}
+
+// olmCRD represents a custom resource definition owned by an operator.
+type olmCRD struct {
+	Name        string `json:"name"`
+	Group       string `json:"group"`
+	Version     string `json:"version"`
+	Kind        string `json:"kind"`
+	DisplayName string `json:"displayName,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// olmCRDManifest represents the subset of the fields of a custom resource
+// definition manifest needed to complete the owned CRDs information.
+type olmCRDManifest struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group string `json:"group"`
+	} `json:"spec"`
+}
+
+// loadOperatorPackages loads the operators available in the given directory,
+// which is expected to follow the OLM package manifests layout used by
+// OperatorHub: each operator lives in its own directory, containing a package
+// manifest (*.package.yaml) that defines its channels and the bundles of each
+// version (cluster service version and custom resource definitions), usually
+// in a subdirectory per version. A package version is loaded for each cluster
+// service version found. When a source url is provided, it's used to build a
+// link to each of the cluster service versions source file.
+func loadOperatorPackages(basePath, sourceURL string) ([]*hub.Package, error) {
+	packages := make([]*hub.Package, 0)
+	err := filepath.Walk(basePath, func(pkgPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if pkgPath != basePath && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isOLMPackageFile(info.Name()) {
+			return nil
+		}
+		data, err := ioutil.ReadFile(pkgPath)
+		if err != nil {
+			return err
+		}
+		var op *olmPackage
+		if err := yaml.Unmarshal(data, &op); err != nil || op == nil || op.PackageName == "" {
+			relPath, _ := filepath.Rel(basePath, pkgPath)
+			return fmt.Errorf("error loading %s: invalid package manifest", filepath.ToSlash(relPath))
+		}
+		operatorPackages, err := loadOperatorVersions(op, data, basePath, filepath.Dir(pkgPath), sourceURL)
+		if err != nil {
+			return err
+		}
+		packages = append(packages, operatorPackages...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+// loadOperatorVersions loads a package version for each of the cluster
+// service versions available in the operator directory provided.
+func loadOperatorVersions(
+	op *olmPackage,
+	opData []byte,
+	basePath, opPath, sourceURL string,
+) ([]*hub.Package, error) {
+	// Collect the cluster service versions and the custom resource
+	// definitions available in the operator directory, per bundle directory
+	csvs := make(map[string][]byte)
+	crdsGroups := make(map[string]map[string]string)
+	err := filepath.Walk(opPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != opPath && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(p); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		if isOLMPackageFile(info.Name()) {
+			return nil
+		}
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		var md olmCRDManifest
+		if err := yaml.Unmarshal(data, &md); err != nil {
+			return nil
+		}
+		switch md.Kind {
+		case csvKind:
+			csvs[p] = data
+		case crdKind:
+			dir := filepath.Dir(p)
+			if crdsGroups[dir] == nil {
+				crdsGroups[dir] = make(map[string]string)
+			}
+			crdsGroups[dir][md.Metadata.Name] = md.Spec.Group
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare a package version for each cluster service version
+	csvsPaths := make([]string, 0, len(csvs))
+	for p := range csvs {
+		csvsPaths = append(csvsPaths, p)
+	}
+	sort.Strings(csvsPaths)
+	packages := make([]*hub.Package, 0, len(csvs))
+	for _, csvPath := range csvsPaths {
+		relPath, err := filepath.Rel(basePath, csvPath)
+		if err != nil {
+			return nil, err
+		}
+		var csv *olmCSV
+		if err := yaml.Unmarshal(csvs[csvPath], &csv); err != nil {
+			return nil, fmt.Errorf("error loading %s: %w", filepath.ToSlash(relPath), err)
+		}
+		if csv.Spec.Version == "" {
+			return nil, fmt.Errorf("error loading %s: version not provided", filepath.ToSlash(relPath))
+		}
+		p := prepareOperatorPackage(op, csv, crdsGroups[filepath.Dir(csvPath)])
+		digest := sha256.New()
+		digest.Write(opData)
+		digest.Write(csvs[csvPath])
+		p.Digest = fmt.Sprintf("%x", digest.Sum(nil))
+		if sourceURL != "" {
+			p.Links = append(p.Links, &hub.Link{
+				Name: "source",
+				URL:  sourceURL + "/" + filepath.ToSlash(relPath),
+			})
+		}
+		packages = append(packages, p)
+	}
+	return packages, nil
+}
+
+// prepareOperatorPackage prepares a package version from the OLM package
+// manifest and cluster service version provided. The groups of the custom
+// resource definitions shipped in the bundle, by name, are used to complete
+// the information of the CRDs owned by the operator.
+func prepareOperatorPackage(op *olmPackage, csv *olmCSV, crdsGroups map[string]string) *hub.Package {
+	p := &hub.Package{
+		Kind:        hub.Operator,
+		Name:        op.PackageName,
+		DisplayName: csv.Spec.DisplayName,
+		Description: csv.Metadata.Annotations["description"],
+		Keywords:    csv.Spec.Keywords,
+		Readme:      csv.Spec.Description,
+		Version:     csv.Spec.Version,
+	}
+	if len(csv.Spec.Icon) > 0 && csv.Spec.Icon[0].Data != "" {
+		p.LogoURL = fmt.Sprintf("data:%s;base64,%s", csv.Spec.Icon[0].MediaType, csv.Spec.Icon[0].Data)
+	}
+	for _, link := range csv.Spec.Links {
+		if link.URL != "" {
+			p.Links = append(p.Links, &hub.Link{Name: link.Name, URL: link.URL})
+		}
+	}
+	for _, maintainer := range csv.Spec.Maintainers {
+		if maintainer.Email != "" {
+			p.Maintainers = append(p.Maintainers, &hub.Maintainer{
+				Name:  maintainer.Name,
+				Email: maintainer.Email,
+			})
+		}
+	}
+
+	// Container images used by the operator
+	imagesSet := make(map[string]struct{})
+	refs := []string{csv.Metadata.Annotations["containerImage"]}
+	for _, ri := range csv.Spec.RelatedImages {
+		refs = append(refs, ri.Image)
+	}
+	for _, ref := range refs {
+		if image, err := pkg.NormalizeImageReference(ref, true); err == nil {
+			imagesSet[image] = struct{}{}
+		}
+	}
+	for image := range imagesSet {
+		p.Images = append(p.Images, image)
+	}
+	sort.Strings(p.Images)
+
+	// Owned CRDs
+	crds := make([]*olmCRD, 0, len(csv.Spec.CRDs.Owned))
+	for _, crd := range csv.Spec.CRDs.Owned {
+		group, ok := crdsGroups[crd.Name]
+		if !ok || group == "" {
+			if i := strings.Index(crd.Name, "."); i >= 0 {
+				group = crd.Name[i+1:]
+			}
+		}
+		crds = append(crds, &olmCRD{
+			Name:        crd.Name,
+			Group:       group,
+			Version:     crd.Version,
+			Kind:        crd.Kind,
+			DisplayName: crd.DisplayName,
+			Description: crd.Description,
+		})
+	}
+
+	// Channels the version is the head of
+	var isDefaultChannelHead bool
+	for _, channel := range op.Channels {
+		if channel.Name == op.DefaultChannel && channel.CurrentCSV == csv.Metadata.Name {
+			isDefaultChannelHead = true
+		}
+	}
+
+	p.Data = map[string]interface{}{
+		"csvName":              csv.Metadata.Name,
+		"replaces":             csv.Spec.Replaces,
+		"provider":             csv.Spec.Provider.Name,
+		"maturity":             csv.Spec.Maturity,
+		"capabilities":         csv.Metadata.Annotations["capabilities"],
+		"installModes":         csv.Spec.InstallModes,
+		"crds":                 crds,
+		"channels":             op.Channels,
+		"defaultChannel":       op.DefaultChannel,
+		"isDefaultChannelHead": isDefaultChannelHead,
+	}
+	return p
+}
+
+// isOLMPackageFile checks if the file name provided corresponds to an OLM
+// package manifest.
+func isOLMPackageFile(name string) bool {
+	return strings.HasSuffix(name, ".package.yaml") ||
+		strings.HasSuffix(name, ".package.yml") ||
+		name == "package.yaml"
+}
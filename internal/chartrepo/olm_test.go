@@ -0,0 +1,81 @@
+package chartrepo
+
+import (
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadOperatorPackages(t *testing.T) {
+	t.Run("invalid package manifest", func(t *testing.T) {
+		_, err := loadOperatorPackages("testdata/operators-invalid", "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "op1/op1.package.yaml")
+	})
+
+	t.Run("operator versions loaded", func(t *testing.T) {
+		packages, err := loadOperatorPackages("testdata/operators", "https://github.com/org/repo/blob/HEAD")
+		require.NoError(t, err)
+		require.Len(t, packages, 2)
+
+		channels := []*olmChannel{
+			{Name: "singlenamespace-alpha", CurrentCSV: "etcdoperator.v0.9.4"},
+			{Name: "clusterwide-alpha", CurrentCSV: "etcdoperator.v0.9.2"},
+		}
+		p1 := packages[0]
+		assert.Equal(t, hub.Operator, p1.Kind)
+		assert.Equal(t, "etcd", p1.Name)
+		assert.Equal(t, "0.9.2", p1.Version)
+		assert.Empty(t, p1.Images)
+		assert.Equal(t, false, p1.Data["isDefaultChannelHead"])
+		assert.Equal(t, channels, p1.Data["channels"])
+
+		p2 := packages[1]
+		assert.Equal(t, hub.Operator, p2.Kind)
+		assert.Equal(t, "etcd", p2.Name)
+		assert.Equal(t, "etcd", p2.DisplayName)
+		assert.Equal(t, "0.9.4", p2.Version)
+		assert.Equal(t, "Create and maintain highly-available etcd clusters on Kubernetes", p2.Description)
+		assert.Equal(t, "# etcd\nThe etcd Operator creates and maintains etcd clusters.\n", p2.Readme)
+		assert.Equal(t, []string{"etcd", "key value"}, p2.Keywords)
+		assert.Equal(t, "data:image/png;base64,iVBORw0KGgo=", p2.LogoURL)
+		assert.Equal(t, []*hub.Maintainer{
+			{Name: "etcd Community", Email: "etcd-dev@googlegroups.com"},
+		}, p2.Maintainers)
+		assert.Equal(t, []*hub.Link{
+			{Name: "Blog", URL: "https://coreos.com/etcd"},
+			{Name: "source", URL: "https://github.com/org/repo/blob/HEAD/etcd/0.9.4/etcdoperator.v0.9.4.clusterserviceversion.yaml"},
+		}, p2.Links)
+		assert.Equal(t, []string{
+			"quay.io/coreos/etcd-operator@sha256:66a37fd61a06a43969854ee6d3e21087a98b93838e284a6086b13917f96b0d9b",
+		}, p2.Images)
+		assert.NotEmpty(t, p2.Digest)
+		assert.NotEqual(t, p1.Digest, p2.Digest)
+		assert.Equal(t, map[string]interface{}{
+			"csvName":      "etcdoperator.v0.9.4",
+			"replaces":     "etcdoperator.v0.9.2",
+			"provider":     "CNCF",
+			"maturity":     "alpha",
+			"capabilities": "Full Lifecycle",
+			"installModes": []*olmInstallMode{
+				{Type: "OwnNamespace", Supported: true},
+				{Type: "AllNamespaces", Supported: false},
+			},
+			"crds": []*olmCRD{
+				{
+					Name:        "etcdclusters.etcd.database.coreos.com",
+					Group:       "etcd.database.coreos.com",
+					Version:     "v1beta2",
+					Kind:        "EtcdCluster",
+					DisplayName: "etcd Cluster",
+					Description: "Represents a cluster of etcd nodes.",
+				},
+			},
+			"channels":             channels,
+			"defaultChannel":       "singlenamespace-alpha",
+			"isDefaultChannelHead": true,
+		}, p2.Data)
+	})
+}
@@ -0,0 +1,290 @@
+package chartrepo
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// gitCloneTimeout represents the maximum amount of time cloning a git
+	// repository can take.
+	gitCloneTimeout = 2 * time.Minute
+)
+
+// securityHubKinds represents the kind used in the Cloud Native Security Hub
+// files for each of the package kinds supported.
+var securityHubKinds = map[hub.PackageKind]string{
+	hub.Falco: "FalcoRules",
+	hub.OPA:   "OpenPolicyAgentPolicies",
+}
+
+// errInvalidKind indicates that the kind of the repository provided is not
+// supported by the packages loader.
+var errInvalidKind = errors.New("invalid repository kind")
+
+// gitClone clones the git repository located at the url provided into the
+// given directory. Only the latest commit is fetched.
+var gitClone = func(ctx context.Context, u, dir string) error {
+	cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--quiet", "--", u, dir) // #nosec
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("error cloning git repository: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// securityHubEntry represents a package defined using the Cloud Native
+// Security Hub format, used by Falco rules and OPA policies.
+type securityHubEntry struct {
+	Kind             string                 `json:"kind"`
+	Name             string                 `json:"name"`
+	ShortDescription string                 `json:"shortDescription"`
+	Version          string                 `json:"version"`
+	Description      string                 `json:"description"`
+	Keywords         []string               `json:"keywords"`
+	Icon             string                 `json:"icon"`
+	Rules            []*securityHubResource `json:"rules"`
+	Policies         []*securityHubResource `json:"policies"`
+}
+
+// securityHubResource represents a Falco rule or an OPA policy defined in a
+// Cloud Native Security Hub file.
+type securityHubResource struct {
+	Raw string `json:"raw"`
+}
+
+// PackagesLoader provides a mechanism to load the packages available in
+// repositories of kinds other than Helm charts. Packages are defined using
+// Artifact Hub metadata files (artifacthub-pkg.yml), which can be used by any
+// kind, or Cloud Native Security Hub files in the case of Falco rules and OPA
+// policies. Operators are loaded from OLM package manifests directories. The
+// repository url can point to a git repository, to a local directory or
+// directly to a metadata file served over http(s). The fragment of git urls,
+// if any, is used as the path of the directory containing the packages in the
+// git repository.
+type PackagesLoader struct{}
+
+// LoadPackagesIfChanged loads the packages available in the provided
+// repository only if they have changed since the last time they were
+// processed. To detect changes, the digest of all packages available is
+// compared with the one previously processed. When the packages have not
+// changed, no packages are returned. The information about the packages
+// loaded is returned as well, so that it can be stored for the next run.
+func (l *PackagesLoader) LoadPackagesIfChanged(r *hub.ChartRepository) (
+	[]*hub.Package,
+	*hub.ChartRepositoryIndexInfo,
+	error,
+) {
+	if r.Kind == hub.Chart || r.Kind < 0 {
+		return nil, nil, errInvalidKind
+	}
+
+	// Get the directory containing the packages
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, nil, err
+	}
+	var packages []*hub.Package
+	var basePath, sourceURL string
+	switch {
+	case u.Scheme == "" || u.Scheme == "file":
+		basePath = u.Path
+	case isMetadataFile(u.Path) && (u.Scheme == "http" || u.Scheme == "https"):
+		p, err := loadRemotePackage(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		packages = []*hub.Package{p}
+	default:
+		tmpDir, err := ioutil.TempDir("", "artifacthub")
+		if err != nil {
+			return nil, nil, err
+		}
+		defer os.RemoveAll(tmpDir)
+		subPath := path.Clean("/" + u.Fragment)
+		u.Fragment = ""
+		ctx, cancel := context.WithTimeout(context.Background(), gitCloneTimeout)
+		defer cancel()
+		if err := gitClone(ctx, u.String(), tmpDir); err != nil {
+			return nil, nil, err
+		}
+		basePath = filepath.Join(tmpDir, filepath.FromSlash(subPath))
+		sourceURL = strings.TrimSuffix(u.String(), ".git") + "/blob/HEAD" + strings.TrimSuffix(subPath, "/")
+	}
+
+	// Load packages and check if they have changed
+	if packages == nil {
+		if r.Kind == hub.Operator {
+			packages, err = loadOperatorPackages(basePath, sourceURL)
+		} else {
+			packages, err = loadPackages(r.Kind, basePath, sourceURL)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	info := &hub.ChartRepositoryIndexInfo{Digest: packagesDigest(packages)}
+	if r.LastIndexInfo != nil && r.LastIndexInfo.Digest == info.Digest {
+		return nil, info, nil
+	}
+	return packages, info, nil
+}
+
+// loadPackages loads the packages of the kind provided defined in the files
+// available in the given directory. Artifact Hub metadata files are loaded for
+// any kind, whereas Cloud Native Security Hub files are only considered for
+// Falco rules and OPA policies. Other files, or files that do not define a
+// package of the kind provided, are ignored. When a source url is provided,
+// it's used to build a link to each of the packages source file.
+func loadPackages(kind hub.PackageKind, basePath, sourceURL string) ([]*hub.Package, error) {
+	packages := make([]*hub.Package, 0)
+	err := filepath.Walk(basePath, func(pkgPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if pkgPath != basePath && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(pkgPath); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		data, err := ioutil.ReadFile(pkgPath)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(basePath, pkgPath)
+		if err != nil {
+			return err
+		}
+
+		var p *hub.Package
+		if isMetadataFile(pkgPath) {
+			md, err := pkg.ParseMetadata(data)
+			if err != nil {
+				return fmt.Errorf("error loading %s: %w", filepath.ToSlash(relPath), err)
+			}
+			p = pkg.PreparePackageFromMetadata(kind, md)
+		} else {
+			p = preparePackageFromSecurityHubFile(kind, data)
+			if p == nil {
+				return nil
+			}
+		}
+		p.Digest = fmt.Sprintf("%x", sha256.Sum256(data))
+		if sourceURL != "" {
+			p.Links = append(p.Links, &hub.Link{
+				Name: "source",
+				URL:  sourceURL + "/" + filepath.ToSlash(relPath),
+			})
+		}
+		packages = append(packages, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return packages, nil
+}
+
+// loadRemotePackage loads the package defined in the Artifact Hub metadata
+// file the repository url provided points to.
+func loadRemotePackage(r *hub.ChartRepository) (*hub.Package, error) {
+	req, err := http.NewRequest("GET", r.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	setAuthHeader(req, r)
+	hc, err := httpClient(r, indexHTTPClient)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code received: %d", resp.StatusCode)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	md, err := pkg.ParseMetadata(data)
+	if err != nil {
+		return nil, fmt.Errorf("error loading %s: %w", r.URL, err)
+	}
+	p := pkg.PreparePackageFromMetadata(r.Kind, md)
+	p.Digest = fmt.Sprintf("%x", sha256.Sum256(data))
+	return p, nil
+}
+
+// preparePackageFromSecurityHubFile prepares a package of the kind provided
+// from the Cloud Native Security Hub file content given. When the content
+// does not define a package of the kind provided, nil is returned.
+func preparePackageFromSecurityHubFile(kind hub.PackageKind, data []byte) *hub.Package {
+	shKind, ok := securityHubKinds[kind]
+	if !ok {
+		return nil
+	}
+	var e *securityHubEntry
+	if err := yaml.Unmarshal(data, &e); err != nil || e == nil {
+		return nil
+	}
+	if e.Kind != shKind || e.Name == "" || e.Version == "" {
+		return nil
+	}
+	p := &hub.Package{
+		Kind:        kind,
+		Name:        e.Name,
+		LogoURL:     e.Icon,
+		Description: e.ShortDescription,
+		Keywords:    e.Keywords,
+		Version:     e.Version,
+		Readme:      e.Description,
+	}
+	switch kind {
+	case hub.Falco:
+		p.Data = map[string]interface{}{"rules": e.Rules}
+	case hub.OPA:
+		p.Data = map[string]interface{}{"policies": e.Policies}
+	}
+	return p
+}
+
+// isMetadataFile checks if the path provided corresponds to an Artifact Hub
+// metadata file.
+func isMetadataFile(p string) bool {
+	base := path.Base(filepath.ToSlash(p))
+	return base == pkg.MetadataFile || base == strings.TrimSuffix(pkg.MetadataFile, ".yml")+".yaml"
+}
+
+// packagesDigest returns a digest of the packages provided, which changes
+// when any of them is added, updated or removed.
+func packagesDigest(packages []*hub.Package) string {
+	entries := make([]string, 0, len(packages))
+	for _, p := range packages {
+		entries = append(entries, fmt.Sprintf("%s@%s:%s", p.Name, p.Version, p.Digest))
+	}
+	sort.Strings(entries)
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(entries, "\n"))))
+}
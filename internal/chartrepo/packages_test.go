@@ -0,0 +1,219 @@
+package chartrepo
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/artifacthub/hub/internal/pkg"
+	"github.com/artifacthub/hub/internal/tests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPackagesIfChanged(t *testing.T) {
+	l := &PackagesLoader{}
+	errFake := errors.New("fake error for tests")
+
+	t.Run("invalid repository kind", func(t *testing.T) {
+		_, _, err := l.LoadPackagesIfChanged(&hub.ChartRepository{Kind: hub.Chart, URL: "testdata/packages"})
+		assert.Equal(t, errInvalidKind, err)
+	})
+
+	t.Run("directory not found", func(t *testing.T) {
+		_, _, err := l.LoadPackagesIfChanged(&hub.ChartRepository{Kind: hub.Falco, URL: "testdata/notfound"})
+		assert.Error(t, err)
+	})
+
+	t.Run("falco rules loaded from local directory", func(t *testing.T) {
+		packages, info, err := l.LoadPackagesIfChanged(&hub.ChartRepository{
+			Kind: hub.Falco,
+			URL:  "testdata/packages",
+		})
+		require.NoError(t, err)
+		require.Len(t, packages, 1)
+		p := packages[0]
+		assert.Equal(t, hub.Falco, p.Kind)
+		assert.Equal(t, "Nginx", p.Name)
+		assert.Equal(t, "1.0.0", p.Version)
+		assert.Equal(t, "Falco rules for securing Nginx", p.Description)
+		assert.Equal(t, []string{"nginx"}, p.Keywords)
+		assert.Equal(t, "# Nginx Falco Rules\n", p.Readme)
+		assert.Equal(t, "https://upload.wikimedia.org/wikipedia/commons/c/c5/Nginx_logo.svg", p.LogoURL)
+		assert.NotEmpty(t, p.Digest)
+		assert.Empty(t, p.Links)
+		assert.Len(t, p.Data["rules"], 1)
+		assert.Equal(t, packagesDigest(packages), info.Digest)
+	})
+
+	t.Run("packages loaded can be registered in the chart repository", func(t *testing.T) {
+		r := &hub.ChartRepository{
+			ChartRepositoryID: "00000000-0000-0000-0000-000000000001",
+			Kind:              hub.Falco,
+			URL:               "testdata/packages",
+		}
+		packages, _, err := l.LoadPackagesIfChanged(r)
+		require.NoError(t, err)
+		require.Len(t, packages, 1)
+
+		db := &tests.DBMock{}
+		db.On("QueryRow", "select get_package_kinds()").Return([]byte(`[{"kind": 1, "slug": "falco"}]`), nil)
+		db.On("Exec", "select register_package($1::jsonb)", mock.Anything).Return(nil)
+		p := packages[0]
+		p.ChartRepository = &hub.ChartRepository{ChartRepositoryID: r.ChartRepositoryID}
+		err = pkg.NewManager(db).Register(context.Background(), p)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("opa policies loaded from local directory", func(t *testing.T) {
+		basePath, err := filepath.Abs("testdata/packages/opa")
+		require.NoError(t, err)
+
+		packages, _, err := l.LoadPackagesIfChanged(&hub.ChartRepository{
+			Kind: hub.OPA,
+			URL:  "file://" + filepath.ToSlash(basePath),
+		})
+		require.NoError(t, err)
+		require.Len(t, packages, 1)
+		assert.Equal(t, hub.OPA, packages[0].Kind)
+		assert.Equal(t, "Pod Security Policy", packages[0].Name)
+		assert.Len(t, packages[0].Data["policies"], 1)
+	})
+
+	t.Run("operators loaded from local directory", func(t *testing.T) {
+		packages, info, err := l.LoadPackagesIfChanged(&hub.ChartRepository{
+			Kind: hub.Operator,
+			URL:  "testdata/operators",
+		})
+		require.NoError(t, err)
+		require.Len(t, packages, 2)
+		assert.Equal(t, hub.Operator, packages[0].Kind)
+		assert.Equal(t, "etcd", packages[0].Name)
+		assert.Equal(t, packagesDigest(packages), info.Digest)
+	})
+
+	t.Run("packages loaded from metadata files", func(t *testing.T) {
+		kind := hub.PackageKind(4)
+		packages, _, err := l.LoadPackagesIfChanged(&hub.ChartRepository{
+			Kind: kind,
+			URL:  "testdata/metadata/valid",
+		})
+		require.NoError(t, err)
+		require.Len(t, packages, 1)
+		p := packages[0]
+		assert.Equal(t, kind, p.Kind)
+		assert.Equal(t, "pkg1", p.Name)
+		assert.Equal(t, "Package 1", p.DisplayName)
+		assert.Equal(t, "1.0.0", p.Version)
+		assert.Equal(t, "2.0.0", p.AppVersion)
+		assert.Equal(t, "# Package 1\n", p.Readme)
+		assert.Equal(t, []*hub.Link{{Name: "docs", URL: "https://example.com/pkg1/docs"}}, p.Links)
+		assert.Equal(t, map[string]interface{}{"key": "value"}, p.Data)
+		assert.NotEmpty(t, p.Digest)
+	})
+
+	t.Run("invalid metadata file", func(t *testing.T) {
+		_, _, err := l.LoadPackagesIfChanged(&hub.ChartRepository{
+			Kind: hub.PackageKind(4),
+			URL:  "testdata/metadata/invalid",
+		})
+		assert.True(t, errors.Is(err, pkg.ErrInvalidMetadata))
+		assert.Contains(t, err.Error(), "pkg1/artifacthub-pkg.yml")
+	})
+
+	t.Run("package loaded from remote metadata file", func(t *testing.T) {
+		data, err := ioutil.ReadFile("testdata/metadata/valid/pkg1/artifacthub-pkg.yml")
+		require.NoError(t, err)
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/pkg1/artifacthub-pkg.yml" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(data)
+		}))
+		defer s.Close()
+
+		packages, info, err := l.LoadPackagesIfChanged(&hub.ChartRepository{
+			Kind: hub.PackageKind(4),
+			URL:  s.URL + "/pkg1/artifacthub-pkg.yml",
+		})
+		require.NoError(t, err)
+		require.Len(t, packages, 1)
+		assert.Equal(t, "pkg1", packages[0].Name)
+		assert.Equal(t, packagesDigest(packages), info.Digest)
+
+		_, _, err = l.LoadPackagesIfChanged(&hub.ChartRepository{
+			Kind: hub.PackageKind(4),
+			URL:  s.URL + "/pkg2/artifacthub-pkg.yml",
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("packages unchanged", func(t *testing.T) {
+		r := &hub.ChartRepository{Kind: hub.Falco, URL: "testdata/packages"}
+		_, info, err := l.LoadPackagesIfChanged(r)
+		require.NoError(t, err)
+
+		r.LastIndexInfo = info
+		packages, info2, err := l.LoadPackagesIfChanged(r)
+		require.NoError(t, err)
+		assert.Nil(t, packages)
+		assert.Equal(t, info, info2)
+	})
+
+	t.Run("error cloning git repository", func(t *testing.T) {
+		defer setGitClone(func(ctx context.Context, u, dir string) error {
+			return errFake
+		})()
+
+		_, _, err := l.LoadPackagesIfChanged(&hub.ChartRepository{
+			Kind: hub.Falco,
+			URL:  "https://github.com/org/repo.git",
+		})
+		assert.Equal(t, errFake, err)
+	})
+
+	t.Run("falco rules loaded from git repository", func(t *testing.T) {
+		var clonedURL string
+		defer setGitClone(func(ctx context.Context, u, dir string) error {
+			clonedURL = u
+			data, err := ioutil.ReadFile("testdata/packages/falco/nginx.yaml")
+			if err != nil {
+				return err
+			}
+			pkgPath := filepath.Join(dir, "resources", "falco", "nginx.yaml")
+			if err := os.MkdirAll(filepath.Dir(pkgPath), 0700); err != nil {
+				return err
+			}
+			return ioutil.WriteFile(pkgPath, data, 0600)
+		})()
+
+		packages, _, err := l.LoadPackagesIfChanged(&hub.ChartRepository{
+			Kind: hub.Falco,
+			URL:  "https://github.com/org/repo.git#resources",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "https://github.com/org/repo.git", clonedURL)
+		require.Len(t, packages, 1)
+		assert.Equal(t, []*hub.Link{
+			{
+				Name: "source",
+				URL:  "https://github.com/org/repo/blob/HEAD/resources/falco/nginx.yaml",
+			},
+		}, packages[0].Links)
+	})
+}
+
+func setGitClone(f func(ctx context.Context, u, dir string) error) func() {
+	prev := gitClone
+	gitClone = f
+	return func() { gitClone = prev }
+}
@@ -0,0 +1,93 @@
+package chartrepo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+	pgperrors "golang.org/x/crypto/openpgp/errors"
+	"helm.sh/helm/v3/pkg/provenance"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// armoredPublicKeyHeader represents the header of an armored public key
+	// block. It's used to split keyrings containing multiple blocks.
+	armoredPublicKeyHeader = "-----BEGIN PGP PUBLIC KEY BLOCK-----"
+)
+
+// VerifyProvenance verifies the provenance file provided against the chart
+// archive given, checking its signature using the publisher's keyring when
+// available. It returns the resulting signature status and, when the
+// signature was verified, the fingerprint of the key used to sign it.
+func VerifyProvenance(
+	archive []byte,
+	archiveName string,
+	prov []byte,
+	keyring string,
+) (hub.SignatureStatus, string) {
+	// Decode provenance file and check the archive digest matches
+	block, _ := clearsign.Decode(prov)
+	if block == nil {
+		return hub.InvalidSignature, ""
+	}
+	sums, err := parseProvenanceSums(block.Plaintext)
+	if err != nil {
+		return hub.InvalidSignature, ""
+	}
+	if sums.Files[archiveName] != fmt.Sprintf("sha256:%x", sha256.Sum256(archive)) {
+		return hub.InvalidSignature, ""
+	}
+
+	// Check signature using the publisher's keyring
+	entities := parseKeyring(keyring)
+	if len(entities) == 0 {
+		return hub.Signed, ""
+	}
+	signer, err := openpgp.CheckDetachedSignature(
+		entities,
+		bytes.NewReader(block.Bytes),
+		block.ArmoredSignature.Body,
+	)
+	if err != nil {
+		if errors.Is(err, pgperrors.ErrUnknownIssuer) {
+			return hub.Signed, ""
+		}
+		return hub.InvalidSignature, ""
+	}
+	return hub.Verified, fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint)
+}
+
+// parseProvenanceSums extracts the files checksums from the provenance file
+// message block, which contains the chart metadata followed by the checksums
+// in yaml format, separated by a yaml document end marker.
+func parseProvenanceSums(data []byte) (*provenance.SumCollection, error) {
+	parts := bytes.Split(data, []byte("\n...\n"))
+	if len(parts) < 2 {
+		return nil, errors.New("invalid provenance message block")
+	}
+	sums := &provenance.SumCollection{}
+	if err := yaml.Unmarshal(parts[1], sums); err != nil {
+		return nil, err
+	}
+	return sums, nil
+}
+
+// parseKeyring parses the armored public keys contained in the keyring
+// provided. Keys that cannot be parsed are ignored.
+func parseKeyring(keyring string) openpgp.EntityList {
+	var entities openpgp.EntityList
+	for _, part := range strings.Split(keyring, armoredPublicKeyHeader)[1:] {
+		el, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredPublicKeyHeader + part))
+		if err != nil {
+			continue
+		}
+		entities = append(entities, el...)
+	}
+	return entities
+}
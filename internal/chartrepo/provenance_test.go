@@ -0,0 +1,72 @@
+package chartrepo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/clearsign"
+)
+
+func TestVerifyProvenance(t *testing.T) {
+	archive := []byte("pkg1-1.0.0")
+	signer, signerKeyring := newTestKey(t, "signer")
+	_, otherKeyring := newTestKey(t, "other")
+	prov := signProvenance(t, signer, "pkg1-1.0.0.tgz", archive)
+	fingerprint := fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint)
+
+	testCases := []struct {
+		name                string
+		archive             []byte
+		prov                []byte
+		keyring             string
+		expectedStatus      hub.SignatureStatus
+		expectedFingerprint string
+	}{
+		{"invalid provenance file", archive, []byte("invalid"), signerKeyring, hub.InvalidSignature, ""},
+		{"archive digest mismatch", []byte("other"), prov, signerKeyring, hub.InvalidSignature, ""},
+		{"keyring not available", archive, prov, "", hub.Signed, ""},
+		{"signer key not in keyring", archive, prov, otherKeyring, hub.Signed, ""},
+		{"signature verified", archive, prov, signerKeyring, hub.Verified, fingerprint},
+		{"signature verified (multiple keys)", archive, prov, otherKeyring + "\n" + signerKeyring, hub.Verified, fingerprint},
+		{"tampered signature", archive, bytes.Replace(prov, []byte("name: pkg1"), []byte("name: pkg2"), 1), signerKeyring, hub.InvalidSignature, ""},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			status, fingerprint := VerifyProvenance(tc.archive, "pkg1-1.0.0.tgz", tc.prov, tc.keyring)
+			assert.Equal(t, tc.expectedStatus, status)
+			assert.Equal(t, tc.expectedFingerprint, fingerprint)
+		})
+	}
+}
+
+func newTestKey(t *testing.T, name string) (*openpgp.Entity, string) {
+	t.Helper()
+	e, err := openpgp.NewEntity(name, "", name+"@email.com", nil)
+	require.NoError(t, err)
+	var b bytes.Buffer
+	w, err := armor.Encode(&b, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, e.Serialize(w))
+	require.NoError(t, w.Close())
+	return e, b.String()
+}
+
+func signProvenance(t *testing.T, e *openpgp.Entity, archiveName string, archive []byte) []byte {
+	t.Helper()
+	var b bytes.Buffer
+	w, err := clearsign.Encode(&b, e.PrivateKey, nil)
+	require.NoError(t, err)
+	_, err = fmt.Fprintf(w, "apiVersion: v2\nname: pkg1\nversion: 1.0.0\n\n...\nfiles:\n  %s: sha256:%x\n",
+		archiveName, sha256.Sum256(archive))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
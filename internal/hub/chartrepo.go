@@ -6,26 +6,163 @@ import (
 	"helm.sh/helm/v3/pkg/repo"
 )
 
-// ChartRepository represents a Helm chart repository.
+// ChartRepository represents a Helm chart repository. Repositories of other
+// kinds, like Falco rules, OPA policies or operators, are supported as well.
+// In that case the url points to a git repository or a local directory.
 type ChartRepository struct {
+	ChartRepositoryID string      `json:"chart_repository_id"`
+	Kind              PackageKind `json:"kind"`
+	Name              string      `json:"name"`
+	DisplayName       string      `json:"display_name"`
+	URL               string      `json:"url"`
+	UserID            string      `json:"user_id"`
+
+	LastIndexInfo    *ChartRepositoryIndexInfo   `json:"last_index_info,omitempty"`
+	TrackingInterval int                         `json:"tracking_interval,omitempty"` // Seconds
+	Credentials      *ChartRepositoryCredentials `json:"credentials,omitempty"`
+	TLSConfig        *ChartRepositoryTLSConfig   `json:"tls_config,omitempty"`
+	Keyring          string                      `json:"keyring,omitempty"` // Armored public keys
+}
+
+// ChartRepositoryCredentials represents the credentials used to access a
+// private chart repository. Basic auth is used when a username is provided,
+// otherwise the token is sent as a bearer token. They are stored encrypted.
+type ChartRepositoryCredentials struct {
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+}
+
+// ChartRepositoryTLSConfig represents the TLS settings used to access a chart
+// repository.
+type ChartRepositoryTLSConfig struct {
+	CABundle           string `json:"ca_bundle,omitempty"` // PEM encoded
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+}
+
+// ChartRepositoryIndexInfo represents some information about the last index
+// file processed for a chart repository, used to detect if it has changed.
+type ChartRepositoryIndexInfo struct {
+	Digest       string `json:"digest"`
+	ETag         string `json:"etag"`
+	LastModified string `json:"last_modified"`
+}
+
+// ChartRepositorySyncRequest represents a request to track a chart repository
+// on demand, usually registered by its owner from the API.
+type ChartRepositorySyncRequest struct {
+	SyncRequestID     string `json:"sync_request_id"`
 	ChartRepositoryID string `json:"chart_repository_id"`
-	Name              string `json:"name"`
-	DisplayName       string `json:"display_name"`
-	URL               string `json:"url"`
-	UserID            string `json:"user_id"`
 }
 
+// TrackingRun represents the results of a chart repository tracking run.
+type TrackingRun struct {
+	ChartRepositoryID    string           `json:"chart_repository_id"`
+	StartedAt            int64            `json:"started_at"`
+	FinishedAt           int64            `json:"finished_at"`
+	VersionsRegistered   int              `json:"versions_registered"`
+	VersionsUnregistered int              `json:"versions_unregistered"`
+	VersionsSkipped      int              `json:"versions_skipped"`
+	Errors               []*TrackingError `json:"errors"`
+}
+
+// TrackingError represents an error that occurred while tracking a chart
+// repository, optionally related to a specific chart version. Errors are
+// identified by a machine-readable code, and classified by severity so that
+// issues that do not prevent packages from being registered (i.e. logos that
+// could not be fetched) can be told apart.
+type TrackingError struct {
+	Code     TrackingErrorCode     `json:"code"`
+	Severity TrackingErrorSeverity `json:"severity"`
+	Chart    string                `json:"chart,omitempty"`
+	Version  string                `json:"version,omitempty"`
+	URL      string                `json:"url,omitempty"`
+	Message  string                `json:"message"`
+}
+
+// Error implements the error interface.
+func (e *TrackingError) Error() string {
+	return e.Message
+}
+
+// TrackingErrorCode represents the code identifying the kind of a tracking
+// error.
+type TrackingErrorCode string
+
+const (
+	// IndexUnreachable indicates that the repository index file (or the
+	// packages of repositories of other kinds) could not be loaded.
+	IndexUnreachable TrackingErrorCode = "index_unreachable"
+
+	// InvalidChartURL indicates that the url of a chart archive is not valid.
+	InvalidChartURL TrackingErrorCode = "invalid_chart_url"
+
+	// ChartDownloadFailed indicates that a chart archive could not be
+	// downloaded.
+	ChartDownloadFailed TrackingErrorCode = "chart_download_failed"
+
+	// InvalidChart indicates that a chart archive could not be loaded.
+	InvalidChart TrackingErrorCode = "invalid_chart"
+
+	// DigestMismatch indicates that a chart archive does not match the digest
+	// published in the repository index.
+	DigestMismatch TrackingErrorCode = "digest_mismatch"
+
+	// InvalidSemver indicates that the version of a package is not a valid
+	// semantic version.
+	InvalidSemver TrackingErrorCode = "invalid_semver"
+
+	// InvalidAnnotations indicates that some of the artifacthub.io annotations
+	// of a chart are not valid, so they were ignored.
+	InvalidAnnotations TrackingErrorCode = "invalid_annotations"
+
+	// LogoFailed indicates that the logo of a package could not be fetched.
+	LogoFailed TrackingErrorCode = "logo_failed"
+
+	// RegisterFailed indicates that a package version could not be
+	// registered.
+	RegisterFailed TrackingErrorCode = "register_failed"
+
+	// UnregisterFailed indicates that a package version could not be
+	// unregistered.
+	UnregisterFailed TrackingErrorCode = "unregister_failed"
+
+	// UnknownTrackingError represents any other error.
+	UnknownTrackingError TrackingErrorCode = "unknown"
+)
+
+// TrackingErrorSeverity represents the severity of a tracking error.
+type TrackingErrorSeverity string
+
+const (
+	// SeverityWarning indicates that the issue did not prevent the package
+	// version affected from being registered.
+	SeverityWarning TrackingErrorSeverity = "warning"
+
+	// SeverityError indicates that the issue prevented the repository or the
+	// package version affected from being processed.
+	SeverityError TrackingErrorSeverity = "error"
+)
+
 // ChartRepositoryManager describes the methods an ChartRepositoryManager
 // implementation must provide.
 type ChartRepositoryManager interface {
 	Add(ctx context.Context, orgName string, r *ChartRepository) error
 	CheckAvailability(ctx context.Context, resourceKind, value string) (bool, error)
+	ClaimSyncRequests(ctx context.Context, chartRepositoriesIDs []string) ([]*ChartRepositorySyncRequest, error)
+	CompleteSyncRequests(ctx context.Context, syncRequestsIDs []string) error
 	Delete(ctx context.Context, name string) error
 	GetAll(ctx context.Context) ([]*ChartRepository, error)
 	GetByName(ctx context.Context, name string) (*ChartRepository, error)
 	GetPackagesDigest(ctx context.Context, chartRepositoryID string) (map[string]string, error)
 	GetOwnedByOrgJSON(ctx context.Context, orgName string) ([]byte, error)
 	GetOwnedByUserJSON(ctx context.Context) ([]byte, error)
+	GetSyncRequestJSON(ctx context.Context, name, syncRequestID string) ([]byte, error)
+	GetLintSummaryJSON(ctx context.Context, name string) ([]byte, error)
+	GetTrackingRunsJSON(ctx context.Context, name string, limit int) ([]byte, error)
+	RegisterTrackingRun(ctx context.Context, run *TrackingRun) error
+	RequestSync(ctx context.Context, name string) (string, error)
+	SetLastIndexInfo(ctx context.Context, chartRepositoryID string, info *ChartRepositoryIndexInfo) error
 	SetLastTrackingResults(ctx context.Context, chartRepositoryID, errs string) error
 	Update(ctx context.Context, r *ChartRepository) error
 }
@@ -34,4 +171,12 @@ type ChartRepositoryManager interface {
 // index loader implementation should provide.
 type ChartRepositoryIndexLoader interface {
 	LoadIndex(r *ChartRepository) (*repo.IndexFile, error)
+	LoadIndexIfChanged(r *ChartRepository) (*repo.IndexFile, *ChartRepositoryIndexInfo, error)
+}
+
+// ChartRepositoryPackagesLoader interface defines the methods a loader of the
+// packages available in repositories of kinds other than Helm charts should
+// provide.
+type ChartRepositoryPackagesLoader interface {
+	LoadPackagesIfChanged(r *ChartRepository) ([]*Package, *ChartRepositoryIndexInfo, error)
 }
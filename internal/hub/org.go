@@ -11,6 +11,7 @@ type Organization struct {
 	Description    string `json:"description"`
 	HomeURL        string `json:"home_url"`
 	LogoImageID    string `json:"logo_image_id"`
+	Keyring        string `json:"keyring"`
 }
 
 // OrganizationManager describes the methods an OrganizationManager
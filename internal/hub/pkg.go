@@ -1,9 +1,45 @@
 package hub
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+)
+
+// Change represents a change introduced in a package version.
+type Change struct {
+	Kind        ChangeKind `json:"kind"`
+	Description string     `json:"description"`
+}
+
+// ChangeKind represents the kind of a change introduced in a package version.
+type ChangeKind string
+
+const (
+	// Added represents a change that adds a new feature.
+	Added ChangeKind = "added"
+
+	// Changed represents a change in existing functionality, including
+	// deprecations and removals.
+	Changed ChangeKind = "changed"
+
+	// Fixed represents a change that fixes a bug.
+	Fixed ChangeKind = "fixed"
 
-// GetPackageInput represents the input used to get a specific package.
+	// Security represents a change that fixes a vulnerability.
+	Security ChangeKind = "security"
+)
+
+// Dependency represents a dependency of a package.
+type Dependency struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	Repository string `json:"repository"`
+}
+
+// GetPackageInput represents the input used to get a specific package. The
+// package kind is identified by its slug (i.e. falco).
 type GetPackageInput struct {
+	PackageKind         string `json:"package_kind,omitempty"`
 	ChartRepositoryName string `json:"chart_repository_name"`
 	PackageName         string `json:"package_name"`
 	Version             string `json:"version"`
@@ -20,35 +56,88 @@ type Maintainer struct {
 	MaintainerID string `json:"maintainer_id"`
 	Name         string `json:"name"`
 	Email        string `json:"email"`
+	URL          string `json:"url,omitempty"`
 }
 
 // Package represents a Kubernetes package.
 type Package struct {
-	PackageID         string                 `json:"package_id"`
-	Kind              PackageKind            `json:"kind"`
-	Name              string                 `json:"name"`
-	NormalizedName    string                 `json:"normalized_name"`
-	LogoURL           string                 `json:"logo_url"`
-	LogoImageID       string                 `json:"logo_image_id"`
-	Stars             int                    `json:"stars"`
-	DisplayName       string                 `json:"display_name"`
-	Description       string                 `json:"description"`
-	Keywords          []string               `json:"keywords"`
-	HomeURL           string                 `json:"home_url"`
-	Readme            string                 `json:"readme"`
-	Links             []*Link                `json:"links"`
-	Data              map[string]interface{} `json:"data"`
-	Version           string                 `json:"version"`
-	AvailableVersions []string               `json:"available_versions"`
-	AppVersion        string                 `json:"app_version"`
-	Digest            string                 `json:"digest"`
-	Deprecated        bool                   `json:"deprecated"`
-	Maintainers       []*Maintainer          `json:"maintainers"`
-	UserID            string                 `json:"user_id"`
-	UserAlias         string                 `json:"user_alias"`
-	OrganizationID    string                 `json:"organization_id"`
-	OrganizationName  string                 `json:"organization_name"`
-	ChartRepository   *ChartRepository       `json:"chart_repository"`
+	PackageID               string                 `json:"package_id"`
+	Kind                    PackageKind            `json:"kind"`
+	Name                    string                 `json:"name"`
+	NormalizedName          string                 `json:"normalized_name"`
+	LogoURL                 string                 `json:"logo_url"`
+	LogoImageID             string                 `json:"logo_image_id"`
+	Stars                   int                    `json:"stars"`
+	DisplayName             string                 `json:"display_name"`
+	Description             string                 `json:"description"`
+	Keywords                []string               `json:"keywords"`
+	HomeURL                 string                 `json:"home_url"`
+	Readme                  string                 `json:"readme"`
+	Links                   []*Link                `json:"links"`
+	Data                    map[string]interface{} `json:"data"`
+	Version                 string                 `json:"version"`
+	AvailableVersions       []string               `json:"available_versions"`
+	AppVersion              string                 `json:"app_version"`
+	Digest                  string                 `json:"digest"`
+	Deprecated              bool                   `json:"deprecated"`
+	Signed                  bool                   `json:"signed"`
+	SignatureStatus         SignatureStatus        `json:"signature_status,omitempty"`
+	SignatureKeyFingerprint string                 `json:"signature_key_fingerprint,omitempty"`
+	DefaultValues           string                 `json:"default_values,omitempty"`
+	ValuesSchema            json.RawMessage        `json:"values_schema,omitempty"`
+	Templates               []string               `json:"templates,omitempty"`
+	Dependencies            []*Dependency          `json:"dependencies,omitempty"`
+	Images                  []string               `json:"images,omitempty"`
+	ContentFingerprint      string                 `json:"content_fingerprint,omitempty"`
+	UpstreamSource          string                 `json:"upstream_source,omitempty"`
+	Lint                    *LintReport            `json:"lint,omitempty"`
+	Annotations             map[string]string      `json:"annotations,omitempty"`
+	KubeVersion             string                 `json:"kube_version,omitempty"`
+	ChartType               ChartType              `json:"chart_type,omitempty"`
+	Sources                 []string               `json:"sources,omitempty"`
+	APIVersion              string                 `json:"api_version,omitempty"`
+	License                 string                 `json:"license,omitempty"`
+	Changes                 []*Change              `json:"changes,omitempty"`
+	Prerelease              bool                   `json:"prerelease,omitempty"`
+	ContainsSecurityUpdates bool                   `json:"contains_security_updates,omitempty"`
+	Maintainers             []*Maintainer          `json:"maintainers"`
+	UserID                  string                 `json:"user_id"`
+	UserAlias               string                 `json:"user_alias"`
+	OrganizationID          string                 `json:"organization_id"`
+	OrganizationName        string                 `json:"organization_name"`
+	ChartRepository         *ChartRepository       `json:"chart_repository"`
+}
+
+// ChartType represents the type of a Helm chart.
+type ChartType string
+
+const (
+	// ApplicationChart represents a Helm chart that can be installed. Charts
+	// that do not specify their type are application charts.
+	ApplicationChart ChartType = "application"
+
+	// LibraryChart represents a Helm chart providing utilities to other
+	// charts, which cannot be installed on its own.
+	LibraryChart ChartType = "library"
+)
+
+// LintReport represents the results of linting a package version, with the
+// messages found grouped by severity.
+type LintReport struct {
+	Errors   []*LintMessage `json:"errors,omitempty"`
+	Warnings []*LintMessage `json:"warnings,omitempty"`
+	Info     []*LintMessage `json:"info,omitempty"`
+}
+
+// IsClean checks if the lint report does not contain any error or warning.
+func (r *LintReport) IsClean() bool {
+	return len(r.Errors) == 0 && len(r.Warnings) == 0
+}
+
+// LintMessage represents a message reported when linting a package version.
+type LintMessage struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
 }
 
 // PackageKind represents the kind of a given package.
@@ -63,16 +152,55 @@ const (
 
 	// OPA represents a set of OPA policies.
 	OPA PackageKind = 2
+
+	// Operator represents a Kubernetes operator packaged for the Operator
+	// Lifecycle Manager (OLM).
+	Operator PackageKind = 3
+)
+
+// PackageKindInfo represents a package kind registered in the database. New
+// kinds can be supported by registering them in the package_kind table.
+type PackageKindInfo struct {
+	Kind PackageKind `json:"kind"`
+	Name string      `json:"name"`
+	Slug string      `json:"slug"`
+}
+
+// SignatureStatus represents the result of verifying the provenance file of a
+// package version.
+type SignatureStatus string
+
+const (
+	// Signed indicates that the package version has a provenance file matching
+	// its content, but its signature could not be verified as no matching key
+	// was found. Anyone can produce such a file, so it proves nothing about the
+	// package publisher and it's not considered trusted.
+	Signed SignatureStatus = "signed"
+
+	// Verified indicates that the signature of the package version provenance
+	// file was verified using one of the publisher's keys.
+	Verified SignatureStatus = "verified"
+
+	// InvalidSignature indicates that the package version provenance file is
+	// not valid or its signature does not match the package content.
+	InvalidSignature SignatureStatus = "invalid"
 )
 
 // PackageManager describes the methods a PackageManager implementation must
 // provide.
 type PackageManager interface {
+	GetChangelogJSON(ctx context.Context, input *GetPackageInput) ([]byte, error)
+	GetDependenciesJSON(ctx context.Context, input *GetPackageInput) ([]byte, error)
+	GetDependentsJSON(ctx context.Context, input *GetPackageInput) ([]byte, error)
 	GetJSON(ctx context.Context, input *GetPackageInput) ([]byte, error)
+	GetKinds(ctx context.Context) ([]*PackageKindInfo, error)
+	GetOrgPackagesVersions(ctx context.Context, orgID string) ([]*Package, error)
 	GetStarredByUserJSON(ctx context.Context) ([]byte, error)
 	GetStarsJSON(ctx context.Context, packageID string) ([]byte, error)
 	GetStatsJSON(ctx context.Context) ([]byte, error)
 	GetUpdatesJSON(ctx context.Context) ([]byte, error)
+	GetValues(ctx context.Context, input *GetPackageInput) ([]byte, error)
+	GetValuesSchemaJSON(ctx context.Context, input *GetPackageInput) ([]byte, error)
 	Register(ctx context.Context, pkg *Package) error
 	SearchJSON(ctx context.Context, input *SearchPackageInput) ([]byte, error)
 	ToggleStar(ctx context.Context, packageID string) error
@@ -90,4 +218,12 @@ type SearchPackageInput struct {
 	Orgs              []string      `json:"orgs,omitempty"`
 	ChartRepositories []string      `json:"chart_repositories,omitempty"`
 	Deprecated        bool          `json:"deprecated"`
+	Signed            bool          `json:"signed,omitempty"`
+	LintClean         bool          `json:"lint_clean,omitempty"`
+	ResourceKinds     []string      `json:"resource_kinds,omitempty"`
+	CRDs              []string      `json:"crds,omitempty"`
+	Images            []string      `json:"images,omitempty"`
+	ChartTypes        []ChartType   `json:"chart_types,omitempty"`
+	KubeVersion       string        `json:"kube_version,omitempty"`
+	IncludeDuplicates bool          `json:"include_duplicates,omitempty"`
 }
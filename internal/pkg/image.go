@@ -0,0 +1,77 @@
+package pkg
+
+import (
+	"errors"
+	"strings"
+)
+
+const (
+	// defaultRegistry represents the registry used when the image reference
+	// does not include one.
+	defaultRegistry = "docker.io"
+
+	// defaultTag represents the tag used when the image reference does not
+	// include a tag or a digest.
+	defaultTag = "latest"
+)
+
+// errInvalidImageReference indicates that the image reference provided is
+// not valid.
+var errInvalidImageReference = errors.New("invalid image reference")
+
+// NormalizeImageReference returns the provided container image reference in
+// its normalized form (registry/repository:tag@digest). Official images from
+// the default registry are prefixed with library. When requested, the default
+// tag is added to references that do not include a tag nor a digest.
+func NormalizeImageReference(ref string, addDefaultTag bool) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.ContainsAny(ref, " \t\n{}") {
+		return "", errInvalidImageReference
+	}
+
+	// Extract digest and tag
+	var digest, tag string
+	if i := strings.Index(ref, "@"); i != -1 {
+		ref, digest = ref[:i], ref[i+1:]
+		if !strings.Contains(digest, ":") {
+			return "", errInvalidImageReference
+		}
+	}
+	if i := strings.LastIndex(ref, ":"); i != -1 && !strings.Contains(ref[i:], "/") {
+		ref, tag = ref[:i], ref[i+1:]
+		if tag == "" {
+			return "", errInvalidImageReference
+		}
+	}
+
+	// Extract registry and repository
+	registry, repository := defaultRegistry, ref
+	if i := strings.Index(ref, "/"); i != -1 {
+		host := ref[:i]
+		if strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry, repository = host, ref[i+1:]
+		}
+	}
+	if repository == "" || strings.HasSuffix(repository, "/") {
+		return "", errInvalidImageReference
+	}
+	if registry == "index.docker.io" {
+		registry = defaultRegistry
+	}
+	if registry == defaultRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	// Build normalized reference
+	normalized := registry + "/" + strings.ToLower(repository)
+	if tag == "" && digest == "" && addDefaultTag {
+		tag = defaultTag
+	}
+	if tag != "" {
+		normalized += ":" + tag
+	}
+	if digest != "" {
+		normalized += "@" + digest
+	}
+	return normalized, nil
+}
@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeImageReference(t *testing.T) {
+	testCases := []struct {
+		ref           string
+		addDefaultTag bool
+		normalized    string
+		valid         bool
+	}{
+		{"nginx", true, "docker.io/library/nginx:latest", true},
+		{"nginx", false, "docker.io/library/nginx", true},
+		{"nginx:1.17", true, "docker.io/library/nginx:1.17", true},
+		{"bitnami/redis:5.0", true, "docker.io/bitnami/redis:5.0", true},
+		{"docker.io/nginx:1.17", true, "docker.io/library/nginx:1.17", true},
+		{"index.docker.io/bitnami/redis", true, "docker.io/bitnami/redis:latest", true},
+		{"quay.io/coreos/etcd:v3.4.7", true, "quay.io/coreos/etcd:v3.4.7", true},
+		{"localhost/app", true, "localhost/app:latest", true},
+		{"registry.io:5000/team/app:1.0", true, "registry.io:5000/team/app:1.0", true},
+		{"registry.io:5000/team/app", false, "registry.io:5000/team/app", true},
+		{"nginx@sha256:abcd", true, "docker.io/library/nginx@sha256:abcd", true},
+		{"nginx:1.17@sha256:abcd", true, "docker.io/library/nginx:1.17@sha256:abcd", true},
+		{"", true, "", false},
+		{"nginx:", true, "", false},
+		{"nginx@abcd", true, "", false},
+		{"registry.io/", true, "", false},
+		{"{{ .Values.image }}", true, "", false},
+	}
+	for _, tc := range testCases {
+		normalized, err := NormalizeImageReference(tc.ref, tc.addDefaultTag)
+		if !tc.valid {
+			assert.Error(t, err, tc.ref)
+			continue
+		}
+		assert.NoError(t, err, tc.ref)
+		assert.Equal(t, tc.normalized, normalized, tc.ref)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/artifacthub/hub/internal/hub"
@@ -23,6 +25,9 @@ var (
 // Manager provides an API to manage packages.
 type Manager struct {
 	db hub.DB
+
+	mu    sync.Mutex
+	kinds map[hub.PackageKind]struct{}
 }
 
 // NewManager creates a new Manager instance.
@@ -32,6 +37,71 @@ func NewManager(db hub.DB) *Manager {
 	}
 }
 
+// GetChangelogJSON returns the changes introduced in each of the versions of
+// the package identified by the input provided as a json array, sorted by
+// version (newest first). The json array is built by the database.
+func (m *Manager) GetChangelogJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	// Validate input
+	if input.PackageName == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "package name not provided")
+	}
+
+	// Get package changelog from database
+	inputJSON, _ := json.Marshal(input)
+	dataJSON, err := m.dbQueryJSON(ctx, "select get_package_changelog($1::jsonb)", inputJSON)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return sortChangelog(dataJSON)
+}
+
+// GetDependenciesJSON returns the dependencies of the chart package version
+// identified by the input provided as a json array. The json array is built by
+// the database.
+func (m *Manager) GetDependenciesJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	if err := validateChartVersionInput(input); err != nil {
+		return nil, err
+	}
+
+	// Get package dependencies from database
+	inputJSON, _ := json.Marshal(input)
+	dataJSON, err := m.dbQueryJSON(ctx, "select get_package_dependencies($1::jsonb)", inputJSON)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return dataJSON, nil
+}
+
+// GetDependentsJSON returns the packages that depend on the chart package
+// identified by the input provided as a json array. The json array is built by
+// the database.
+func (m *Manager) GetDependentsJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	// Validate input
+	if input.ChartRepositoryName == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "chart repository name not provided")
+	}
+	if input.PackageName == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "package name not provided")
+	}
+
+	// Get package dependents from database
+	inputJSON, _ := json.Marshal(input)
+	dataJSON, err := m.dbQueryJSON(ctx, "select get_package_dependents($1::jsonb)", inputJSON)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return dataJSON, nil
+}
+
 // GetJSON returns the package identified by the input provided as a json
 // object. The json object is built by the database.
 func (m *Manager) GetJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
@@ -53,6 +123,40 @@ func (m *Manager) GetJSON(ctx context.Context, input *hub.GetPackageInput) ([]by
 	return dataJSON, nil
 }
 
+// GetKinds returns the package kinds registered in the database.
+func (m *Manager) GetKinds(ctx context.Context) ([]*hub.PackageKindInfo, error) {
+	dataJSON, err := m.dbQueryJSON(ctx, "select get_package_kinds()")
+	if err != nil {
+		return nil, err
+	}
+	var kinds []*hub.PackageKindInfo
+	if err := json.Unmarshal(dataJSON, &kinds); err != nil {
+		return nil, err
+	}
+	return kinds, nil
+}
+
+// GetOrgPackagesVersions returns the versions of the packages owned by the
+// organization provided that don't belong to any chart repository.
+func (m *Manager) GetOrgPackagesVersions(ctx context.Context, orgID string) ([]*hub.Package, error) {
+	// Validate input
+	if _, err := uuid.FromString(orgID); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid organization id")
+	}
+
+	// Get organization packages versions from database
+	query := "select get_org_packages_versions($1::uuid)"
+	dataJSON, err := m.dbQueryJSON(ctx, query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	var pp []*hub.Package
+	if err := json.Unmarshal(dataJSON, &pp); err != nil {
+		return nil, err
+	}
+	return pp, nil
+}
+
 // GetStarredByUserJSON returns a json object with packages starred by the user
 // doing the request. The json object is built by the database.
 func (m *Manager) GetStarredByUserJSON(ctx context.Context) ([]byte, error) {
@@ -90,10 +194,54 @@ func (m *Manager) GetUpdatesJSON(ctx context.Context) ([]byte, error) {
 	return m.dbQueryJSON(ctx, "select get_packages_updates()")
 }
 
+// GetValues returns the default values of the chart package version identified
+// by the input provided.
+func (m *Manager) GetValues(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	if err := validateChartVersionInput(input); err != nil {
+		return nil, err
+	}
+
+	// Get package values from database
+	var values string
+	inputJSON, _ := json.Marshal(input)
+	err := m.db.QueryRow(ctx, "select get_package_values($1::jsonb)", inputJSON).Scan(&values)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return []byte(values), nil
+}
+
+// GetValuesSchemaJSON returns the values schema of the chart package version
+// identified by the input provided as a json object. The json object is built
+// by the database.
+func (m *Manager) GetValuesSchemaJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	if err := validateChartVersionInput(input); err != nil {
+		return nil, err
+	}
+
+	// Get package values schema from database
+	inputJSON, _ := json.Marshal(input)
+	dataJSON, err := m.dbQueryJSON(ctx, "select get_package_values_schema($1::jsonb)", inputJSON)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return dataJSON, nil
+}
+
 // Register registers the package provided in the database.
 func (m *Manager) Register(ctx context.Context, pkg *hub.Package) error {
 	// Validate input
-	if !isValidKind(pkg.Kind) {
+	validKind, err := m.isValidKind(ctx, pkg.Kind)
+	if err != nil {
+		return err
+	}
+	if !validKind {
 		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid kind")
 	}
 	if pkg.Name == "" {
@@ -105,10 +253,10 @@ func (m *Manager) Register(ctx context.Context, pkg *hub.Package) error {
 	if _, err := semver.StrictNewVersion(pkg.Version); err != nil {
 		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid version (semantic version expected)")
 	}
-	if pkg.Kind == hub.Chart {
-		if pkg.ChartRepository == nil {
-			return fmt.Errorf("%w: %s", ErrInvalidInput, "chart repository not provided")
-		}
+	if pkg.Kind == hub.Chart && pkg.ChartRepository == nil {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "chart repository not provided")
+	}
+	if pkg.ChartRepository != nil {
 		if pkg.ChartRepository.ChartRepositoryID == "" {
 			return fmt.Errorf("%w: %s", ErrInvalidInput, "chart repository id not provided")
 		}
@@ -138,22 +286,16 @@ func (m *Manager) Register(ctx context.Context, pkg *hub.Package) error {
 				return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid organization id")
 			}
 		}
-		if pkg.ChartRepository != nil {
-			return fmt.Errorf("%w: %s", ErrInvalidInput, "unexpected chart repository provided")
-		}
 	}
 	for _, m := range pkg.Maintainers {
-		if m.Name == "" {
-			return fmt.Errorf("%w: %s", ErrInvalidInput, "maintainer name not provided")
-		}
-		if m.Email == "" {
-			return fmt.Errorf("%w: %s", ErrInvalidInput, "maintainer email not provided")
+		if m.Name == "" && m.Email == "" {
+			return fmt.Errorf("%w: %s", ErrInvalidInput, "maintainer name or email not provided")
 		}
 	}
 
 	// Register package in database
 	pkgJSON, _ := json.Marshal(pkg)
-	_, err := m.db.Exec(ctx, "select register_package($1::jsonb)", pkgJSON)
+	_, err = m.db.Exec(ctx, "select register_package($1::jsonb)", pkgJSON)
 	return err
 }
 
@@ -182,6 +324,35 @@ func (m *Manager) SearchJSON(ctx context.Context, input *hub.SearchPackageInput)
 			return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid chart repository name")
 		}
 	}
+	for _, kind := range input.ResourceKinds {
+		if kind == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid resource kind")
+		}
+	}
+	for _, crd := range input.CRDs {
+		if crd == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid crd")
+		}
+	}
+	for i, image := range input.Images {
+		normalized, err := NormalizeImageReference(image, false)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid image")
+		}
+		input.Images[i] = normalized
+	}
+	for _, chartType := range input.ChartTypes {
+		if chartType != hub.ApplicationChart && chartType != hub.LibraryChart {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid chart type")
+		}
+	}
+	if input.KubeVersion != "" {
+		v, err := semver.NewVersion(input.KubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidInput, "invalid kubernetes version")
+		}
+		input.KubeVersion = fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch())
+	}
 
 	// Search packages in database
 	inputJSON, _ := json.Marshal(input)
@@ -208,7 +379,11 @@ func (m *Manager) ToggleStar(ctx context.Context, packageID string) error {
 // Unregister unregisters the package provided from the database.
 func (m *Manager) Unregister(ctx context.Context, pkg *hub.Package) error {
 	// Validate input
-	if !isValidKind(pkg.Kind) {
+	validKind, err := m.isValidKind(ctx, pkg.Kind)
+	if err != nil {
+		return err
+	}
+	if !validKind {
 		return fmt.Errorf("%w: %s", ErrInvalidInput, "invalid kind")
 	}
 	if pkg.Name == "" {
@@ -223,7 +398,7 @@ func (m *Manager) Unregister(ctx context.Context, pkg *hub.Package) error {
 
 	// Unregister package from database
 	pkgJSON, _ := json.Marshal(pkg)
-	_, err := m.db.Exec(ctx, "select unregister_package($1::jsonb)", pkgJSON)
+	_, err = m.db.Exec(ctx, "select unregister_package($1::jsonb)", pkgJSON)
 	return err
 }
 
@@ -237,6 +412,44 @@ func (m *Manager) dbQueryJSON(ctx context.Context, query string, args ...interfa
 	return dataJSON, nil
 }
 
+// changelogEntry represents an entry of a package changelog, as it is
+// returned by the database.
+type changelogEntry struct {
+	Version string `json:"version"`
+	semver  *semver.Version
+	data    json.RawMessage
+}
+
+// sortChangelog sorts the entries of the changelog provided by version, newest
+// first, following the semantic versioning precedence rules. Entries whose
+// version is not valid are placed at the end.
+func sortChangelog(dataJSON []byte) ([]byte, error) {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(dataJSON, &raw); err != nil {
+		return nil, err
+	}
+	entries := make([]*changelogEntry, 0, len(raw))
+	for _, data := range raw {
+		e := &changelogEntry{data: data}
+		if err := json.Unmarshal(data, e); err != nil {
+			return nil, err
+		}
+		e.semver, _ = semver.NewVersion(e.Version)
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		vi, vj := entries[i].semver, entries[j].semver
+		if vi == nil || vj == nil {
+			return vi != nil
+		}
+		return vi.GreaterThan(vj)
+	})
+	for i, e := range entries {
+		raw[i] = e.data
+	}
+	return json.Marshal(raw)
+}
+
 // getUserID returns the user id from the context provided when available.
 func getUserID(ctx context.Context) *string {
 	var userID *string
@@ -247,12 +460,36 @@ func getUserID(ctx context.Context) *string {
 	return userID
 }
 
-// isValidKind checks if the provided package kind is valid.
-func isValidKind(kind hub.PackageKind) bool {
-	for _, validKind := range []hub.PackageKind{hub.Chart, hub.Falco, hub.OPA} {
-		if kind == validKind {
-			return true
+// validateChartVersionInput checks the input provided identifies a specific
+// version of a chart package.
+func validateChartVersionInput(input *hub.GetPackageInput) error {
+	if input.ChartRepositoryName == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "chart repository name not provided")
+	}
+	if input.PackageName == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "package name not provided")
+	}
+	if input.Version == "" {
+		return fmt.Errorf("%w: %s", ErrInvalidInput, "version not provided")
+	}
+	return nil
+}
+
+// isValidKind checks if the provided package kind is registered in the
+// database. The kinds registered are loaded the first time they are needed.
+func (m *Manager) isValidKind(ctx context.Context, kind hub.PackageKind) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.kinds == nil {
+		kinds, err := m.GetKinds(ctx)
+		if err != nil {
+			return false, err
+		}
+		m.kinds = make(map[hub.PackageKind]struct{}, len(kinds))
+		for _, k := range kinds {
+			m.kinds[k.Kind] = struct{}{}
 		}
 	}
-	return false
+	_, ok := m.kinds[kind]
+	return ok, nil
 }
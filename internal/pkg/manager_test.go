@@ -2,15 +2,233 @@ package pkg
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 
 	"github.com/artifacthub/hub/internal/hub"
 	"github.com/artifacthub/hub/internal/tests"
+	"github.com/jackc/pgx/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
+func TestGetChangelogJSON(t *testing.T) {
+	dbQuery := "select get_package_changelog($1::jsonb)"
+	input := &hub.GetPackageInput{
+		ChartRepositoryName: "repo1",
+		PackageName:         "pkg1",
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		m := NewManager(nil)
+		_, err := m.GetChangelogJSON(context.Background(), &hub.GetPackageInput{ChartRepositoryName: "repo1"})
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+		assert.Contains(t, err.Error(), "package name not provided")
+	})
+
+	t.Run("changelog sorted by version", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte(`[
+			{"version": "1.0.0-rc.9", "changes": []},
+			{"version": "invalid", "changes": []},
+			{"version": "1.0.0", "changes": []},
+			{"version": "1.0.0-rc.10", "changes": []},
+			{"version": "1.10.0", "changes": []},
+			{"version": "1.9.0", "changes": []}
+		]`), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetChangelogJSON(context.Background(), input)
+		require.NoError(t, err)
+		var entries []map[string]interface{}
+		require.NoError(t, json.Unmarshal(dataJSON, &entries))
+		versions := make([]string, 0, len(entries))
+		for _, e := range entries {
+			versions = append(versions, e["version"].(string))
+		}
+		assert.Equal(t, []string{
+			"1.10.0",
+			"1.9.0",
+			"1.0.0",
+			"1.0.0-rc.10",
+			"1.0.0-rc.9",
+			"invalid",
+		}, versions)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("invalid json data returned from database", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte("invalid json"), nil)
+		m := NewManager(db)
+
+		_, err := m.GetChangelogJSON(context.Background(), input)
+		assert.Error(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("package not found", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetChangelogJSON(context.Background(), input)
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetChangelogJSON(context.Background(), input)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetDependenciesJSON(t *testing.T) {
+	dbQuery := "select get_package_dependencies($1::jsonb)"
+	input := &hub.GetPackageInput{
+		ChartRepositoryName: "repo1",
+		PackageName:         "pkg1",
+		Version:             "1.0.0",
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			input  *hub.GetPackageInput
+		}{
+			{
+				"chart repository name not provided",
+				&hub.GetPackageInput{PackageName: "pkg1", Version: "1.0.0"},
+			},
+			{
+				"package name not provided",
+				&hub.GetPackageInput{ChartRepositoryName: "repo1", Version: "1.0.0"},
+			},
+			{
+				"version not provided",
+				&hub.GetPackageInput{ChartRepositoryName: "repo1", PackageName: "pkg1"},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				m := NewManager(nil)
+				_, err := m.GetDependenciesJSON(context.Background(), tc.input)
+				assert.True(t, errors.Is(err, ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependenciesJSON(context.Background(), input)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("package version not found", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependenciesJSON(context.Background(), input)
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependenciesJSON(context.Background(), input)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetDependentsJSON(t *testing.T) {
+	dbQuery := "select get_package_dependents($1::jsonb)"
+	input := &hub.GetPackageInput{
+		ChartRepositoryName: "repo1",
+		PackageName:         "pkg1",
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			input  *hub.GetPackageInput
+		}{
+			{
+				"chart repository name not provided",
+				&hub.GetPackageInput{PackageName: "pkg1"},
+			},
+			{
+				"package name not provided",
+				&hub.GetPackageInput{ChartRepositoryName: "repo1"},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				m := NewManager(nil)
+				_, err := m.GetDependentsJSON(context.Background(), tc.input)
+				assert.True(t, errors.Is(err, ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependentsJSON(context.Background(), input)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("package not found", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependentsJSON(context.Background(), input)
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetDependentsJSON(context.Background(), input)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestGetJSON(t *testing.T) {
 	dbQuery := "select get_package($1::jsonb)"
 
@@ -77,6 +295,96 @@ func TestGetStarredByUserJSON(t *testing.T) {
 	})
 }
 
+func TestGetKinds(t *testing.T) {
+	dbQuery := "select get_package_kinds()"
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery).Return(kindsJSON, nil)
+		m := NewManager(db)
+
+		kinds, err := m.GetKinds(context.Background())
+		require.NoError(t, err)
+		require.Len(t, kinds, 5)
+		assert.Equal(t, &hub.PackageKindInfo{
+			Kind: hub.PackageKind(4),
+			Name: "Kubectl plugins",
+			Slug: "krew",
+		}, kinds[4])
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		kinds, err := m.GetKinds(context.Background())
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, kinds)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetOrgPackagesVersions(t *testing.T) {
+	dbQuery := "select get_org_packages_versions($1::uuid)"
+	ctx := context.Background()
+	orgID := "00000000-0000-0000-0000-000000000001"
+
+	t.Run("invalid organization id", func(t *testing.T) {
+		m := NewManager(nil)
+		_, err := m.GetOrgPackagesVersions(ctx, "orgID")
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, orgID).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		_, err := m.GetOrgPackagesVersions(ctx, orgID)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("invalid json data returned from database", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, orgID).Return([]byte("invalid json"), nil)
+		m := NewManager(db)
+
+		_, err := m.GetOrgPackagesVersions(ctx, orgID)
+		assert.Error(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("packages versions returned successfully", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, orgID).Return([]byte(`
+		[
+			{
+				"kind": 1,
+				"name": "pkg1",
+				"version": "1.0.0",
+				"organization_id": "00000000-0000-0000-0000-000000000001"
+			}
+		]
+		`), nil)
+		m := NewManager(db)
+
+		pp, err := m.GetOrgPackagesVersions(ctx, orgID)
+		require.NoError(t, err)
+		assert.Equal(t, []*hub.Package{
+			{
+				Kind:           hub.Falco,
+				Name:           "pkg1",
+				Version:        "1.0.0",
+				OrganizationID: orgID,
+			},
+		}, pp)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestGetStarsJSON(t *testing.T) {
 	dbQuery := "select get_package_stars($1::uuid, $2::uuid)"
 	ctx := context.WithValue(context.Background(), hub.UserIDKey, "userID")
@@ -175,8 +483,128 @@ func TestGetUpdatesJSON(t *testing.T) {
 	})
 }
 
+func TestGetValues(t *testing.T) {
+	dbQuery := "select get_package_values($1::jsonb)"
+	input := &hub.GetPackageInput{
+		ChartRepositoryName: "repo1",
+		PackageName:         "pkg1",
+		Version:             "1.0.0",
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		testCases := []struct {
+			errMsg string
+			input  *hub.GetPackageInput
+		}{
+			{
+				"chart repository name not provided",
+				&hub.GetPackageInput{PackageName: "pkg1", Version: "1.0.0"},
+			},
+			{
+				"package name not provided",
+				&hub.GetPackageInput{ChartRepositoryName: "repo1", Version: "1.0.0"},
+			},
+			{
+				"version not provided",
+				&hub.GetPackageInput{ChartRepositoryName: "repo1", PackageName: "pkg1"},
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				m := NewManager(nil)
+				_, err := m.GetValues(context.Background(), tc.input)
+				assert.True(t, errors.Is(err, ErrInvalidInput))
+				assert.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return("key: value", nil)
+		m := NewManager(db)
+
+		values, err := m.GetValues(context.Background(), input)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("key: value"), values)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("values not found", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		m := NewManager(db)
+
+		values, err := m.GetValues(context.Background(), input)
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, values)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		values, err := m.GetValues(context.Background(), input)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, values)
+		db.AssertExpectations(t)
+	})
+}
+
+func TestGetValuesSchemaJSON(t *testing.T) {
+	dbQuery := "select get_package_values_schema($1::jsonb)"
+	input := &hub.GetPackageInput{
+		ChartRepositoryName: "repo1",
+		PackageName:         "pkg1",
+		Version:             "1.0.0",
+	}
+
+	t.Run("invalid input", func(t *testing.T) {
+		m := NewManager(nil)
+		_, err := m.GetValuesSchemaJSON(context.Background(), &hub.GetPackageInput{PackageName: "pkg1"})
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+
+	t.Run("database query succeeded", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return([]byte("dataJSON"), nil)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetValuesSchemaJSON(context.Background(), input)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dataJSON"), dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("values schema not found", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, pgx.ErrNoRows)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetValuesSchemaJSON(context.Background(), input)
+		assert.Equal(t, ErrNotFound, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("database error", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQuery, mock.Anything).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		dataJSON, err := m.GetValuesSchemaJSON(context.Background(), input)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		assert.Nil(t, dataJSON)
+		db.AssertExpectations(t)
+	})
+}
+
 func TestRegister(t *testing.T) {
 	dbQuery := "select register_package($1::jsonb)"
+	dbQueryKinds := "select get_package_kinds()"
 
 	p := &hub.Package{
 		Kind:        hub.Chart,
@@ -210,11 +638,27 @@ func TestRegister(t *testing.T) {
 		},
 	}
 
+	t.Run("database error getting package kinds", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryKinds).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		err := m.Register(context.Background(), p)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
 	t.Run("invalid input", func(t *testing.T) {
 		testCases := []struct {
 			errMsg string
 			p      *hub.Package
 		}{
+			{
+				"invalid kind",
+				&hub.Package{
+					Kind: hub.PackageKind(-1),
+				},
+			},
 			{
 				"invalid kind",
 				&hub.Package{
@@ -333,35 +777,30 @@ func TestRegister(t *testing.T) {
 				},
 			},
 			{
-				"unexpected chart repository provided",
+				"invalid chart repository id",
 				&hub.Package{
-					Kind:           hub.Falco,
-					Name:           "package1",
-					Version:        "1.0.0",
-					OrganizationID: "00000000-0000-0000-0000-000000000001",
+					Kind:    hub.Falco,
+					Name:    "package1",
+					Version: "1.0.0",
 					ChartRepository: &hub.ChartRepository{
-						ChartRepositoryID: "unexpected",
+						ChartRepositoryID: "invalid",
 					},
 				},
 			},
 			{
-				"maintainer name not provided",
+				"unexpected organization id provided",
 				&hub.Package{
-					Kind:    hub.Chart,
-					Name:    "package1",
-					Version: "1.0.0",
+					Kind:           hub.Falco,
+					Name:           "package1",
+					Version:        "1.0.0",
+					OrganizationID: "00000000-0000-0000-0000-000000000001",
 					ChartRepository: &hub.ChartRepository{
 						ChartRepositoryID: "00000000-0000-0000-0000-000000000001",
 					},
-					Maintainers: []*hub.Maintainer{
-						{
-							Email: "email",
-						},
-					},
 				},
 			},
 			{
-				"maintainer email not provided",
+				"maintainer name or email not provided",
 				&hub.Package{
 					Kind:    hub.Chart,
 					Name:    "package1",
@@ -371,7 +810,7 @@ func TestRegister(t *testing.T) {
 					},
 					Maintainers: []*hub.Maintainer{
 						{
-							Name: "name",
+							URL: "https://maintainer.url",
 						},
 					},
 				},
@@ -380,7 +819,9 @@ func TestRegister(t *testing.T) {
 		for _, tc := range testCases {
 			tc := tc
 			t.Run(tc.errMsg, func(t *testing.T) {
-				m := NewManager(nil)
+				db := &tests.DBMock{}
+				db.On("QueryRow", dbQueryKinds).Return(kindsJSON, nil)
+				m := NewManager(db)
 				err := m.Register(context.Background(), tc.p)
 				assert.True(t, errors.Is(err, ErrInvalidInput))
 				assert.Contains(t, err.Error(), tc.errMsg)
@@ -390,6 +831,7 @@ func TestRegister(t *testing.T) {
 
 	t.Run("successful package registration", func(t *testing.T) {
 		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryKinds).Return(kindsJSON, nil)
 		db.On("Exec", dbQuery, mock.Anything).Return(nil)
 		m := NewManager(db)
 
@@ -398,8 +840,65 @@ func TestRegister(t *testing.T) {
 		db.AssertExpectations(t)
 	})
 
+	t.Run("successful package registration with maintainers without email", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryKinds).Return(kindsJSON, nil)
+		db.On("Exec", dbQuery, mock.Anything).Return(nil)
+		m := NewManager(db)
+
+		p := *p
+		p.Maintainers = []*hub.Maintainer{
+			{
+				Name: "name1",
+			},
+			{
+				Email: "email2",
+			},
+		}
+		err := m.Register(context.Background(), &p)
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("successful registration of package of kind registered in database", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryKinds).Return(kindsJSON, nil)
+		db.On("Exec", dbQuery, mock.Anything).Return(nil)
+		m := NewManager(db)
+
+		err := m.Register(context.Background(), &hub.Package{
+			Kind:    hub.PackageKind(4),
+			Name:    "package1",
+			Version: "1.0.0",
+			ChartRepository: &hub.ChartRepository{
+				ChartRepositoryID: "00000000-0000-0000-0000-000000000001",
+			},
+		})
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
+	t.Run("successful non chart package registration from chart repository", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryKinds).Return(kindsJSON, nil)
+		db.On("Exec", dbQuery, mock.Anything).Return(nil)
+		m := NewManager(db)
+
+		err := m.Register(context.Background(), &hub.Package{
+			Kind:    hub.Falco,
+			Name:    "package1",
+			Version: "1.0.0",
+			ChartRepository: &hub.ChartRepository{
+				ChartRepositoryID: "00000000-0000-0000-0000-000000000001",
+			},
+		})
+		assert.NoError(t, err)
+		db.AssertExpectations(t)
+	})
+
 	t.Run("database error", func(t *testing.T) {
 		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryKinds).Return(kindsJSON, nil)
 		db.On("Exec", dbQuery, mock.Anything).Return(tests.ErrFakeDatabaseFailure)
 		m := NewManager(db)
 
@@ -467,6 +966,41 @@ func TestSearchJSON(t *testing.T) {
 					ChartRepositories: []string{""},
 				},
 			},
+			{
+				"invalid resource kind",
+				&hub.SearchPackageInput{
+					Limit:         10,
+					ResourceKinds: []string{""},
+				},
+			},
+			{
+				"invalid crd",
+				&hub.SearchPackageInput{
+					Limit: 10,
+					CRDs:  []string{""},
+				},
+			},
+			{
+				"invalid image",
+				&hub.SearchPackageInput{
+					Limit:  10,
+					Images: []string{"nginx:"},
+				},
+			},
+			{
+				"invalid chart type",
+				&hub.SearchPackageInput{
+					Limit:      10,
+					ChartTypes: []hub.ChartType{"plugin"},
+				},
+			},
+			{
+				"invalid kubernetes version",
+				&hub.SearchPackageInput{
+					Limit:       10,
+					KubeVersion: "latest",
+				},
+			},
 		}
 		for _, tc := range testCases {
 			tc := tc
@@ -557,6 +1091,7 @@ func TestToggleStar(t *testing.T) {
 
 func TestUnregister(t *testing.T) {
 	dbQuery := "select unregister_package($1::jsonb)"
+	dbQueryKinds := "select get_package_kinds()"
 
 	p := &hub.Package{
 		Kind:    hub.Chart,
@@ -567,11 +1102,27 @@ func TestUnregister(t *testing.T) {
 		},
 	}
 
+	t.Run("database error getting package kinds", func(t *testing.T) {
+		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryKinds).Return(nil, tests.ErrFakeDatabaseFailure)
+		m := NewManager(db)
+
+		err := m.Unregister(context.Background(), p)
+		assert.Equal(t, tests.ErrFakeDatabaseFailure, err)
+		db.AssertExpectations(t)
+	})
+
 	t.Run("invalid input", func(t *testing.T) {
 		testCases := []struct {
 			errMsg string
 			p      *hub.Package
 		}{
+			{
+				"invalid kind",
+				&hub.Package{
+					Kind: hub.PackageKind(-1),
+				},
+			},
 			{
 				"invalid kind",
 				&hub.Package{
@@ -603,7 +1154,9 @@ func TestUnregister(t *testing.T) {
 		for _, tc := range testCases {
 			tc := tc
 			t.Run(tc.errMsg, func(t *testing.T) {
-				m := NewManager(nil)
+				db := &tests.DBMock{}
+				db.On("QueryRow", dbQueryKinds).Return(kindsJSON, nil)
+				m := NewManager(db)
 				err := m.Unregister(context.Background(), tc.p)
 				assert.True(t, errors.Is(err, ErrInvalidInput))
 				assert.Contains(t, err.Error(), tc.errMsg)
@@ -613,6 +1166,7 @@ func TestUnregister(t *testing.T) {
 
 	t.Run("successful package unregistration", func(t *testing.T) {
 		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryKinds).Return(kindsJSON, nil)
 		db.On("Exec", dbQuery, mock.Anything).Return(nil)
 		m := NewManager(db)
 
@@ -623,6 +1177,7 @@ func TestUnregister(t *testing.T) {
 
 	t.Run("database error", func(t *testing.T) {
 		db := &tests.DBMock{}
+		db.On("QueryRow", dbQueryKinds).Return(kindsJSON, nil)
 		db.On("Exec", dbQuery, mock.Anything).Return(tests.ErrFakeDatabaseFailure)
 		m := NewManager(db)
 
@@ -631,3 +1186,15 @@ func TestUnregister(t *testing.T) {
 		db.AssertExpectations(t)
 	})
 }
+
+// kindsJSON contains the package kinds returned by the database in the tests,
+// including one not defined as a constant in the hub package.
+var kindsJSON = []byte(`
+[
+	{"kind": 0, "name": "Helm charts", "slug": "chart"},
+	{"kind": 1, "name": "Falco rules", "slug": "falco"},
+	{"kind": 2, "name": "OPA policies", "slug": "opa"},
+	{"kind": 3, "name": "Operators", "slug": "operator"},
+	{"kind": 4, "name": "Kubectl plugins", "slug": "krew"}
+]
+`)
@@ -0,0 +1,105 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/artifacthub/hub/internal/hub"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// MetadataFile represents the name of the file containing the metadata of
+	// a package in the Artifact Hub format.
+	MetadataFile = "artifacthub-pkg.yml"
+)
+
+// ErrInvalidMetadata indicates that the package metadata provided is not
+// valid.
+var ErrInvalidMetadata = errors.New("invalid metadata")
+
+// Metadata represents the metadata of a package in the Artifact Hub format,
+// which can be used to publish packages of any kind. See the documentation in
+// docs/artifacthub-pkg.yml for more details.
+type Metadata struct {
+	Version     string                 `json:"version"`
+	Name        string                 `json:"name"`
+	DisplayName string                 `json:"displayName"`
+	Description string                 `json:"description"`
+	Keywords    []string               `json:"keywords"`
+	LogoURL     string                 `json:"logoURL"`
+	HomeURL     string                 `json:"homeURL"`
+	AppVersion  string                 `json:"appVersion"`
+	Deprecated  bool                   `json:"deprecated"`
+	Readme      string                 `json:"readme"`
+	Links       []*hub.Link            `json:"links"`
+	Maintainers []*hub.Maintainer      `json:"maintainers"`
+	Data        map[string]interface{} `json:"data"`
+}
+
+// ParseMetadata parses and validates the package metadata provided, which is
+// expected to be in the Artifact Hub format (yaml or json).
+func ParseMetadata(data []byte) (*Metadata, error) {
+	var md *Metadata
+	if err := yaml.Unmarshal(data, &md); err != nil || md == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMetadata, "invalid yaml content")
+	}
+	if md.Name == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMetadata, "name not provided")
+	}
+	if md.Version == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMetadata, "version not provided")
+	}
+	if _, err := semver.StrictNewVersion(md.Version); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidMetadata, "invalid version (semantic version expected)")
+	}
+	for _, link := range md.Links {
+		if link.Name == "" || !isValidURL(link.URL) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMetadata, "invalid link")
+		}
+	}
+	for _, maintainer := range md.Maintainers {
+		if maintainer.Email == "" {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMetadata, "maintainer email not provided")
+		}
+	}
+	return md, nil
+}
+
+// PreparePackageFromMetadata prepares a package of the kind provided using
+// the metadata given.
+func PreparePackageFromMetadata(kind hub.PackageKind, md *Metadata) *hub.Package {
+	p := &hub.Package{
+		Kind:        kind,
+		Name:        md.Name,
+		LogoURL:     md.LogoURL,
+		DisplayName: md.DisplayName,
+		Description: md.Description,
+		Keywords:    md.Keywords,
+		HomeURL:     md.HomeURL,
+		Readme:      md.Readme,
+		Links:       md.Links,
+		Data:        md.Data,
+		Version:     md.Version,
+		AppVersion:  md.AppVersion,
+		Deprecated:  md.Deprecated,
+	}
+	for _, maintainer := range md.Maintainers {
+		p.Maintainers = append(p.Maintainers, &hub.Maintainer{
+			Name:  maintainer.Name,
+			Email: maintainer.Email,
+		})
+	}
+	return p
+}
+
+// isValidURL checks if the url provided is a valid absolute http(s) url.
+func isValidURL(u string) bool {
+	tmp, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	return (tmp.Scheme == "http" || tmp.Scheme == "https") && tmp.Host != ""
+}
@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/artifacthub/hub/internal/hub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetadata(t *testing.T) {
+	t.Run("invalid metadata", func(t *testing.T) {
+		testCases := []struct {
+			data   string
+			errMsg string
+		}{
+			{
+				"- invalid",
+				"invalid yaml content",
+			},
+			{
+				"version: 1.0.0",
+				"name not provided",
+			},
+			{
+				"name: pkg1",
+				"version not provided",
+			},
+			{
+				"name: pkg1\nversion: 1.0",
+				"invalid version (semantic version expected)",
+			},
+			{
+				"name: pkg1\nversion: 1.0.0\nlinks:\n  - name: link1\n    url: invalid",
+				"invalid link",
+			},
+			{
+				"name: pkg1\nversion: 1.0.0\nmaintainers:\n  - name: maintainer1",
+				"maintainer email not provided",
+			},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.errMsg, func(t *testing.T) {
+				_, err := ParseMetadata([]byte(tc.data))
+				assert.True(t, errors.Is(err, ErrInvalidMetadata))
+				assert.Contains(t, err.Error(), tc.errMsg)
+			})
+		}
+	})
+
+	t.Run("valid metadata", func(t *testing.T) {
+		md, err := ParseMetadata([]byte(`
+version: 1.0.0
+name: pkg1
+displayName: Package 1
+links:
+  - name: link1
+    url: https://link1.url
+maintainers:
+  - name: maintainer1
+    email: maintainer1@email.com
+data:
+  key: value
+`))
+		require.NoError(t, err)
+		assert.Equal(t, &Metadata{
+			Version:     "1.0.0",
+			Name:        "pkg1",
+			DisplayName: "Package 1",
+			Links:       []*hub.Link{{Name: "link1", URL: "https://link1.url"}},
+			Maintainers: []*hub.Maintainer{{Name: "maintainer1", Email: "maintainer1@email.com"}},
+			Data:        map[string]interface{}{"key": "value"},
+		}, md)
+	})
+}
+
+func TestPreparePackageFromMetadata(t *testing.T) {
+	md := &Metadata{
+		Version:     "1.0.0",
+		Name:        "pkg1",
+		DisplayName: "Package 1",
+		Description: "description",
+		Keywords:    []string{"kw1"},
+		AppVersion:  "2.0.0",
+		Deprecated:  true,
+		Maintainers: []*hub.Maintainer{{Name: "maintainer1", Email: "maintainer1@email.com"}},
+	}
+	p := PreparePackageFromMetadata(hub.PackageKind(4), md)
+	assert.Equal(t, &hub.Package{
+		Kind:        hub.PackageKind(4),
+		Name:        "pkg1",
+		DisplayName: "Package 1",
+		Description: "description",
+		Keywords:    []string{"kw1"},
+		Version:     "1.0.0",
+		AppVersion:  "2.0.0",
+		Deprecated:  true,
+		Maintainers: []*hub.Maintainer{{Name: "maintainer1", Email: "maintainer1@email.com"}},
+	}, p)
+}
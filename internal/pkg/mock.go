@@ -12,6 +12,27 @@ type ManagerMock struct {
 	mock.Mock
 }
 
+// GetChangelogJSON implements the PackageManager interface.
+func (m *ManagerMock) GetChangelogJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	args := m.Called(ctx, input)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+// GetDependenciesJSON implements the PackageManager interface.
+func (m *ManagerMock) GetDependenciesJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	args := m.Called(ctx, input)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+// GetDependentsJSON implements the PackageManager interface.
+func (m *ManagerMock) GetDependentsJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	args := m.Called(ctx, input)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
 // GetJSON implements the PackageManager interface.
 func (m *ManagerMock) GetJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
 	args := m.Called(ctx, input)
@@ -19,6 +40,20 @@ func (m *ManagerMock) GetJSON(ctx context.Context, input *hub.GetPackageInput) (
 	return data, args.Error(1)
 }
 
+// GetKinds implements the PackageManager interface.
+func (m *ManagerMock) GetKinds(ctx context.Context) ([]*hub.PackageKindInfo, error) {
+	args := m.Called(ctx)
+	kinds, _ := args.Get(0).([]*hub.PackageKindInfo)
+	return kinds, args.Error(1)
+}
+
+// GetOrgPackagesVersions implements the PackageManager interface.
+func (m *ManagerMock) GetOrgPackagesVersions(ctx context.Context, orgID string) ([]*hub.Package, error) {
+	args := m.Called(ctx, orgID)
+	pp, _ := args.Get(0).([]*hub.Package)
+	return pp, args.Error(1)
+}
+
 // GetStarredByUserJSON implements the PackageManager interface.
 func (m *ManagerMock) GetStarredByUserJSON(ctx context.Context) ([]byte, error) {
 	args := m.Called(ctx)
@@ -47,6 +82,20 @@ func (m *ManagerMock) GetUpdatesJSON(ctx context.Context) ([]byte, error) {
 	return data, args.Error(1)
 }
 
+// GetValues implements the PackageManager interface.
+func (m *ManagerMock) GetValues(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	args := m.Called(ctx, input)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
+// GetValuesSchemaJSON implements the PackageManager interface.
+func (m *ManagerMock) GetValuesSchemaJSON(ctx context.Context, input *hub.GetPackageInput) ([]byte, error) {
+	args := m.Called(ctx, input)
+	data, _ := args.Get(0).([]byte)
+	return data, args.Error(1)
+}
+
 // Register implements the PackageManager interface.
 func (m *ManagerMock) Register(ctx context.Context, pkg *hub.Package) error {
 	args := m.Called(ctx, pkg)
@@ -0,0 +1,232 @@
+package tests
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// testRegistryToken represents the token issued by the registry when it
+	// requires authentication.
+	testRegistryToken = "test-registry-token"
+)
+
+// OCIRegistry is an in-process OCI registry that implements the subset of the
+// distribution API needed to list, inspect and pull Helm charts. It's meant
+// to be used in tests only.
+type OCIRegistry struct {
+	*httptest.Server
+
+	// RequireToken instructs the registry to require a bearer token, issued
+	// by its own token endpoint, to serve the repositories content.
+	RequireToken bool
+
+	// Username and Password, when set, are the credentials the token endpoint
+	// expects to receive using basic auth to issue tokens.
+	Username string
+	Password string
+
+	// TagsPageSize, when set, is the maximum number of tags the registry
+	// returns in each page when listing the tags of a repository.
+	TagsPageSize int
+
+	// OmitDigestHeader instructs the registry not to report the manifests
+	// digests in the Docker-Content-Digest header.
+	OmitDigestHeader bool
+
+	mu        sync.Mutex
+	tags      map[string]map[string]string // K: repository, K: tag, V: manifest digest
+	manifests map[string][]byte            // K: manifest digest
+	blobs     map[string][]byte            // K: blob digest
+
+	manifestDownloads int
+}
+
+// NewOCIRegistry creates and starts a new in-process OCI registry. It must be
+// closed once it's no longer needed.
+func NewOCIRegistry() *OCIRegistry {
+	r := &OCIRegistry{
+		tags:      make(map[string]map[string]string),
+		manifests: make(map[string][]byte),
+		blobs:     make(map[string][]byte),
+	}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serveHTTP))
+	return r
+}
+
+// Host returns the host (including the port) where the registry is listening.
+func (r *OCIRegistry) Host() string {
+	return strings.TrimPrefix(r.URL, "http://")
+}
+
+// ManifestDownloads returns the number of manifests downloaded from the
+// registry using GET requests.
+func (r *OCIRegistry) ManifestDownloads() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.manifestDownloads
+}
+
+// PushChart stores the provided chart archive in the repository and tag
+// given, using the same layout the Helm client uses when pushing charts.
+func (r *OCIRegistry) PushChart(repository, tag string, archive []byte) string {
+	config := []byte("{}")
+	manifest, _ := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"config": map[string]interface{}{
+			"mediaType": "application/vnd.cncf.helm.config.v1+json",
+			"digest":    digest(config),
+			"size":      len(config),
+		},
+		"layers": []map[string]interface{}{
+			{
+				"mediaType": "application/vnd.cncf.helm.chart.content.v1.tar+gzip",
+				"digest":    digest(archive),
+				"size":      len(archive),
+			},
+		},
+	})
+	return r.PushManifest(repository, tag, manifest, config, archive)
+}
+
+// TamperBlob replaces the content of the blob with the digest provided, so
+// that it no longer matches it.
+func (r *OCIRegistry) TamperBlob(blobDigest string, blob []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.blobs[blobDigest] = blob
+}
+
+// PushManifest stores the provided manifest and blobs in the repository and
+// tag given, returning the manifest digest.
+func (r *OCIRegistry) PushManifest(repository, tag string, manifest []byte, blobs ...[]byte) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	manifestDigest := digest(manifest)
+	r.manifests[manifestDigest] = manifest
+	for _, blob := range blobs {
+		r.blobs[digest(blob)] = blob
+	}
+	if _, ok := r.tags[repository]; !ok {
+		r.tags[repository] = make(map[string]string)
+	}
+	r.tags[repository][tag] = manifestDigest
+	return manifestDigest
+}
+
+// serveHTTP handles the requests received by the registry.
+func (r *OCIRegistry) serveHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		if r.Username != "" {
+			username, password, ok := req.BasicAuth()
+			if !ok || username != r.Username || password != r.Password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": testRegistryToken})
+		return
+	}
+	if !strings.HasPrefix(req.URL.Path, "/v2/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if r.RequireToken && req.Header.Get("Authorization") != "Bearer "+testRegistryToken {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(
+			`Bearer realm="%s/token",service="tests",scope="repository:*:pull"`, r.URL,
+		))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p := strings.TrimPrefix(req.URL.Path, "/v2/")
+	switch {
+	case strings.HasSuffix(p, "/tags/list"):
+		repository := strings.TrimSuffix(p, "/tags/list")
+		tags, ok := r.tags[repository]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		names := make([]string, 0, len(tags))
+		for tag := range tags {
+			names = append(names, tag)
+		}
+		sort.Strings(names)
+		names = r.tagsPage(w, req, names)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"name": repository,
+			"tags": names,
+		})
+	case strings.Contains(p, "/manifests/"):
+		parts := strings.SplitN(p, "/manifests/", 2)
+		ref := parts[1]
+		if !strings.HasPrefix(ref, "sha256:") {
+			ref = r.tags[parts[0]][ref]
+		}
+		manifest, ok := r.manifests[ref]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		if !r.OmitDigestHeader {
+			w.Header().Set("Docker-Content-Digest", ref)
+		}
+		if req.Method == http.MethodGet {
+			r.manifestDownloads++
+		}
+		_, _ = w.Write(manifest)
+	case strings.Contains(p, "/blobs/"):
+		blob, ok := r.blobs[strings.SplitN(p, "/blobs/", 2)[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(blob)
+	case p == "":
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// tagsPage returns the page of the sorted tags provided requested using the
+// n and last query parameters, setting the Link header to the next page when
+// there are more tags available.
+func (r *OCIRegistry) tagsPage(w http.ResponseWriter, req *http.Request, tags []string) []string {
+	if last := req.URL.Query().Get("last"); last != "" {
+		i := sort.SearchStrings(tags, last)
+		if i < len(tags) && tags[i] == last {
+			i++
+		}
+		tags = tags[i:]
+	}
+	pageSize := r.TagsPageSize
+	if n, err := strconv.Atoi(req.URL.Query().Get("n")); err == nil && n > 0 && (pageSize == 0 || n < pageSize) {
+		pageSize = n
+	}
+	if pageSize == 0 || len(tags) <= pageSize {
+		return tags
+	}
+	tags = tags[:pageSize]
+	w.Header().Set("Link", fmt.Sprintf(
+		`<%s?n=%d&last=%s>; rel="next"`, req.URL.Path, pageSize, tags[len(tags)-1],
+	))
+	return tags
+}
+
+// digest returns the digest of the provided content in the format used by
+// OCI registries.
+func digest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}